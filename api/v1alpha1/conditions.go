@@ -36,6 +36,11 @@ const (
 	// reconciler for that resource. This condition indicates the state of the
 	// reconciler.
 	ResourceGraphDefinitionConditionTypeReconcilerReady ConditionType = "ReconcilerReady"
+	// ResourceGraphDefinitionConditionTypeResourcesAccessible indicates whether the
+	// default identities used to reconcile instances hold all the permissions the
+	// graph requires. It is informational and doesn't prevent instances from being
+	// reconciled.
+	ResourceGraphDefinitionConditionTypeResourcesAccessible ConditionType = "ResourcesAccessible"
 )
 
 const (
@@ -49,6 +54,20 @@ const (
 
 	// InstanceConditionTypeError used in something is wrong but i'm going to try again
 	InstanceConditionTypeError ConditionType = "Error"
+
+	// InstanceConditionTypeResourcesAccessible indicates whether the identity used
+	// to reconcile the instance holds all the permissions the graph requires.
+	InstanceConditionTypeResourcesAccessible ConditionType = "ResourcesAccessible"
+
+	// InstanceConditionTypePaused indicates that the reconciliation of the
+	// instance is paused, either by the instance or by its
+	// ResourceGraphDefinition.
+	InstanceConditionTypePaused ConditionType = "Paused"
+
+	// InstanceConditionTypeStalled indicates that the reconciliation of the
+	// instance failed too many times in a row. The instance is retried with a
+	// long backoff until it succeeds.
+	InstanceConditionTypeStalled ConditionType = "Stalled"
 )
 
 // Condition is the common struct used by all CRDs managed by ACK service
@@ -16,6 +16,7 @@ package v1alpha1
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -43,6 +44,208 @@ type ResourceGraphDefinitionSpec struct {
 	//
 	// +kubebuilder:validation:Optional
 	DefaultServiceAccounts map[string]string `json:"defaultServiceAccounts,omitempty"`
+	// ServiceAccountPolicy controls whether instances can request their own
+	// service account for controller impersonation. When omitted, instances
+	// always use the DefaultServiceAccounts.
+	//
+	// +kubebuilder:validation:Optional
+	ServiceAccountPolicy *ServiceAccountPolicy `json:"serviceAccountPolicy,omitempty"`
+	// HealthChecks define how the readiness of custom kinds is assessed when
+	// their resources don't have readyWhen expressions. They take precedence
+	// over the built-in health checks.
+	//
+	// +kubebuilder:validation:Optional
+	HealthChecks []HealthCheck `json:"healthChecks,omitempty"`
+	// ReadyTimeout is the default ReadyTimeout of the resources that don't
+	// define their own.
+	//
+	// +kubebuilder:validation:Optional
+	ReadyTimeout *metav1.Duration `json:"readyTimeout,omitempty"`
+	// DeleteTimeout is the default DeleteTimeout of the resources that don't
+	// define their own.
+	//
+	// +kubebuilder:validation:Optional
+	DeleteTimeout *metav1.Duration `json:"deleteTimeout,omitempty"`
+	// Paused pauses the reconciliation of all the instances. Their status is
+	// still reported, but their resources are not created, updated or
+	// deleted.
+	//
+	// +kubebuilder:validation:Optional
+	Paused bool `json:"paused,omitempty"`
+	// Rollout stages the rollout of changes to the existing instances. When
+	// omitted, all the instances are reconciled with the new graph as soon as
+	// it is built.
+	//
+	// +kubebuilder:validation:Optional
+	Rollout *RolloutPolicy `json:"rollout,omitempty"`
+	// RevisionHistoryLimit is the number of ResourceGraphDefinitionRevisions
+	// kept. Older revisions are deleted, unless instances are pinned to them
+	// or still use them. Defaults to 10.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// Queue configures how the instances are queued for reconciliation,
+	// relative to the instances of the other ResourceGraphDefinitions. When
+	// omitted, the defaults of the controller apply.
+	//
+	// +kubebuilder:validation:Optional
+	Queue *QueuePolicy `json:"queue,omitempty"`
+	// Scope restricts the namespaces the instances are watched and
+	// reconciled in, within the namespaces of the controller. When omitted,
+	// the instances are reconciled in all the namespaces of the controller.
+	//
+	// +kubebuilder:validation:Optional
+	Scope *InstanceScope `json:"scope,omitempty"`
+}
+
+// InstanceScope defines the namespaces the instances of a
+// ResourceGraphDefinition are reconciled in. The instances of the other
+// namespaces are ignored.
+type InstanceScope struct {
+	// Namespaces are the namespaces the instances are reconciled in. Each
+	// namespace is watched separately, so that the controller only needs
+	// permissions in these namespaces.
+	//
+	// +kubebuilder:validation:Optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces the instances are reconciled
+	// in by label.
+	//
+	// +kubebuilder:validation:Optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// QueuePolicy defines how the instances of a ResourceGraphDefinition are
+// queued for reconciliation. Each ResourceGraphDefinition has its own queue,
+// and the controller workers take instances from the queues in weighted
+// round-robin.
+type QueuePolicy struct {
+	// Weight is the share of the workers the instances get while the
+	// instances of other ResourceGraphDefinitions are queued. Defaults to 1.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight,omitempty"`
+	// MaxConcurrentReconciles is the maximum number of instances reconciled
+	// at a time.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentReconciles int32 `json:"maxConcurrentReconciles,omitempty"`
+	// RateLimit is the maximum number of instances requeued after errors per
+	// second.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	RateLimit int32 `json:"rateLimit,omitempty"`
+	// BurstLimit is the maximum number of instances requeued after errors in
+	// a burst.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	BurstLimit int32 `json:"burstLimit,omitempty"`
+}
+
+// RolloutFailurePolicy defines what happens to a rollout when instances moved
+// to the new revision fail.
+//
+// +kubebuilder:validation:Enum=Pause;Continue
+type RolloutFailurePolicy string
+
+const (
+	// RolloutFailurePolicyPause halts the rollout as long as instances moved
+	// to the new revision are Degraded or failing. This is the default.
+	RolloutFailurePolicyPause RolloutFailurePolicy = "Pause"
+	// RolloutFailurePolicyContinue keeps rolling out, failing instances only
+	// count as unavailable.
+	RolloutFailurePolicyContinue RolloutFailurePolicy = "Continue"
+)
+
+// RolloutPolicy defines how changes to the ResourceGraphDefinition are rolled
+// out to the existing instances. Instances are moved to the new revision in
+// batches, wave by wave, and keep being reconciled with the graph of their
+// previous revision until then. New instances always use the latest revision.
+type RolloutPolicy struct {
+	// MaxUnavailable is the maximum number, or percentage, of instances moved
+	// to the new revision that are not ACTIVE yet. Defaults to 1.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// Waves select the instances rolled out together, in order. A wave starts
+	// once all the instances of the previous waves are moved to the new
+	// revision. Instances not selected by any wave are rolled out last.
+	//
+	// +kubebuilder:validation:Optional
+	Waves []RolloutWave `json:"waves,omitempty"`
+	// OnFailure defines what happens when instances moved to the new revision
+	// become Degraded or fail. Defaults to Pause.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Pause
+	OnFailure RolloutFailurePolicy `json:"onFailure,omitempty"`
+}
+
+// RolloutWave is a group of instances rolled out together.
+type RolloutWave struct {
+	// Name identifies the wave in the rollout status.
+	//
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Selector selects the instances of the wave by label.
+	//
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// HealthCheck is a readiness check applied to every resource of a kind that
+// doesn't define readyWhen expressions.
+type HealthCheck struct {
+	// Group is the API group of the kind, empty for the core group.
+	//
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+	// Kind is the kind the check applies to.
+	//
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+	// ReadyWhen is a list of expressions that must all evaluate to true for
+	// the object to be ready. The object is referred to as "self", e.g
+	// "${self.status.phase == 'Ready'}".
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	ReadyWhen []string `json:"readyWhen"`
+}
+
+// ServiceAccountPolicy defines which service accounts instances are allowed to
+// request with the "kro.run/service-account" annotation.
+//
+// The service account is always resolved in the namespace of the instance, and
+// the user named in the "kro.run/service-account-requester" annotation must be
+// allowed to impersonate it.
+type ServiceAccountPolicy struct {
+	// AllowedServiceAccounts is the list of service account names instances
+	// can request. The special value "*" allows any service account.
+	//
+	// +kubebuilder:validation:Optional
+	AllowedServiceAccounts []string `json:"allowedServiceAccounts,omitempty"`
+}
+
+// Allows returns true if the policy allows instances to request the given
+// service account.
+func (p *ServiceAccountPolicy) Allows(serviceAccount string) bool {
+	if p == nil || serviceAccount == "" {
+		return false
+	}
+	for _, allowed := range p.AllowedServiceAccounts {
+		if allowed == DefaultServiceAccountKey || allowed == serviceAccount {
+			return true
+		}
+	}
+	return false
 }
 
 // Schema represents the attributes that define an instance of
@@ -87,6 +290,58 @@ type Validation struct {
 	Message    string `json:"message,omitempty"`
 }
 
+// AdoptionPolicy defines how kro handles an existing object that isn't owned
+// by the instance reconciling it.
+//
+// +kubebuilder:validation:Enum=Adopt;Fail
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyAdopt takes ownership of existing objects that are not
+	// owned by any kro instance. Objects owned by another instance are never
+	// adopted.
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+	// AdoptionPolicyFail refuses to manage existing objects that are not owned
+	// by the instance. This is the default.
+	AdoptionPolicyFail AdoptionPolicy = "Fail"
+)
+
+// HealthCheckPolicy defines how kro assesses the readiness of resources that
+// don't have readyWhen expressions.
+//
+// +kubebuilder:validation:Enum=Default;None
+type HealthCheckPolicy string
+
+const (
+	// HealthCheckPolicyDefault uses the ResourceGraphDefinition health check
+	// for the resource kind if any, or the built-in one: rollout completion
+	// for Deployments, StatefulSets and DaemonSets, success for Jobs, etc. and
+	// the Ready condition and observed generation for other kinds. This is the
+	// default.
+	HealthCheckPolicyDefault HealthCheckPolicy = "Default"
+	// HealthCheckPolicyNone considers resources ready as soon as they exist.
+	HealthCheckPolicyNone HealthCheckPolicy = "None"
+)
+
+// UpdatePolicy defines how kro applies changes to an existing object.
+//
+// +kubebuilder:validation:Enum=CreateOnly;Update;Recreate
+type UpdatePolicy string
+
+const (
+	// UpdatePolicyCreateOnly creates the object if it doesn't exist, and never
+	// updates it afterwards, e.g for one-shot Jobs or bootstrap Secrets.
+	UpdatePolicyCreateOnly UpdatePolicy = "CreateOnly"
+	// UpdatePolicyUpdate updates the object in place when it differs from the
+	// template. This is the default.
+	UpdatePolicyUpdate UpdatePolicy = "Update"
+	// UpdatePolicyRecreate updates the object in place, and deletes and
+	// re-creates it when the API server rejects the update because it changes
+	// immutable fields (e.g Job templates or Service clusterIPs), along with
+	// the resources depending on it.
+	UpdatePolicyRecreate UpdatePolicy = "Recreate"
+)
+
 type Resource struct {
 	// +kubebuilder:validation:Required
 	ID string `json:"id,omitempty"`
@@ -96,6 +351,50 @@ type Resource struct {
 	ReadyWhen []string `json:"readyWhen,omitempty"`
 	// +kubebuilder:validation:Optional
 	IncludeWhen []string `json:"includeWhen,omitempty"`
+	// AdoptionPolicy defines what happens when the object already exists and
+	// isn't owned by the instance. Defaults to Fail.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Fail
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// IgnoreDifferences is a list of field paths whose differences between the
+	// template and the observed object are ignored, e.g fields mutated by
+	// admission webhooks or other controllers. Paths use dots for fields and
+	// brackets for list items, "[*]" matches any list item and "['a.b']" quotes
+	// keys containing dots, e.g "spec.replicas" or
+	// "spec.template.spec.containers[*].resources".
+	//
+	// +kubebuilder:validation:Optional
+	IgnoreDifferences []string `json:"ignoreDifferences,omitempty"`
+	// UpdatePolicy defines how changes to the template are applied to the
+	// existing object. Defaults to Update.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Update
+	UpdatePolicy UpdatePolicy `json:"updatePolicy,omitempty"`
+	// DependsOn is a list of resource IDs that must be reconciled and ready
+	// before this resource, in addition to the dependencies inferred from its
+	// expressions, e.g to create a Namespace before the objects it contains.
+	//
+	// +kubebuilder:validation:Optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// HealthCheck defines how readiness is assessed when ReadyWhen is empty.
+	// Defaults to Default.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Default
+	HealthCheck HealthCheckPolicy `json:"healthCheck,omitempty"`
+	// ReadyTimeout is how long the resource can wait for readiness before the
+	// instance is reported as Degraded, e.g "10m". Waits forever when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	ReadyTimeout *metav1.Duration `json:"readyTimeout,omitempty"`
+	// DeleteTimeout is how long the deletion of the resource can take before
+	// the instance is reported as Degraded, e.g "5m". Waits forever when
+	// omitted.
+	//
+	// +kubebuilder:validation:Optional
+	DeleteTimeout *metav1.Duration `json:"deleteTimeout,omitempty"`
 }
 
 // ResourceGraphDefinitionState defines the state of the resource graph definition.
@@ -118,6 +417,57 @@ type ResourceGraphDefinitionStatus struct {
 	Conditions []Condition `json:"conditions,omitempty"`
 	// Resources represents the resources, and their information (dependencies for now)
 	Resources []ResourceInformation `json:"resources,omitempty"`
+	// Rollout is the progress of the rollout of the latest revision, when the
+	// ResourceGraphDefinition has a rollout policy.
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// ActiveGeneration is the generation of the ResourceGraphDefinition whose
+	// graph the instances are reconciled with.
+	ActiveGeneration int64 `json:"activeGeneration,omitempty"`
+	// FailedGeneration is the generation of the ResourceGraphDefinition that
+	// failed to reconcile, if any. The instances keep being reconciled with the
+	// graph of the active generation.
+	FailedGeneration int64 `json:"failedGeneration,omitempty"`
+}
+
+// RolloutState defines the state of a rollout.
+type RolloutState string
+
+const (
+	// RolloutStateProgressing means instances are being moved to the new
+	// revision.
+	RolloutStateProgressing RolloutState = "Progressing"
+	// RolloutStateHalted means the rollout stopped because instances moved to
+	// the new revision are Degraded or failing.
+	RolloutStateHalted RolloutState = "Halted"
+	// RolloutStateComplete means all the instances use the new revision.
+	RolloutStateComplete RolloutState = "Complete"
+)
+
+// RolloutStatus is the progress of the rollout of a revision.
+type RolloutStatus struct {
+	// Revision is the revision being rolled out, the generation of the
+	// ResourceGraphDefinition that last changed the graph.
+	Revision int64 `json:"revision,omitempty"`
+	// State is the state of the rollout.
+	State RolloutState `json:"state,omitempty"`
+	// Instances is the number of instances.
+	Instances int32 `json:"instances"`
+	// UpdatedInstances is the number of instances moved to the revision.
+	UpdatedInstances int32 `json:"updatedInstances"`
+	// UnavailableInstances is the number of instances moved to the revision
+	// that are not ACTIVE yet.
+	UnavailableInstances int32 `json:"unavailableInstances"`
+	// DegradedInstances is the number of instances moved to the revision
+	// that are Degraded or failing.
+	DegradedInstances int32 `json:"degradedInstances"`
+	// PinnedInstances is the number of instances pinned to a revision, they
+	// are not rolled out.
+	PinnedInstances int32 `json:"pinnedInstances,omitempty"`
+	// CurrentWave is the name of the wave being rolled out, empty for the
+	// instances not selected by any wave.
+	CurrentWave string `json:"currentWave,omitempty"`
+	// Message explains the state of the rollout.
+	Message string `json:"message,omitempty"`
 }
 
 // ResourceInformation defines the information about a resource
@@ -134,6 +484,9 @@ type ResourceInformation struct {
 type Dependency struct {
 	// ID represents the id of the dependency resource
 	ID string `json:"id,omitempty"`
+	// Explicit is true when the dependency is declared in the resource
+	// dependsOn list.
+	Explicit bool `json:"explicit,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -163,6 +516,67 @@ type ResourceGraphDefinitionList struct {
 	Items           []ResourceGraphDefinition `json:"items"`
 }
 
+// ResourceGraphDefinitionRevisionSpec is the snapshot of a generation of a
+// ResourceGraphDefinition.
+type ResourceGraphDefinitionRevisionSpec struct {
+	// ResourceGraphDefinitionName is the name of the ResourceGraphDefinition.
+	//
+	// +kubebuilder:validation:Required
+	ResourceGraphDefinitionName string `json:"resourceGraphDefinitionName"`
+	// Revision is the generation of the ResourceGraphDefinition.
+	//
+	// +kubebuilder:validation:Required
+	Revision int64 `json:"revision"`
+	// GraphHash is the hash of the schema and resources of the revision.
+	// Revisions with the same hash build the same graph.
+	//
+	// +kubebuilder:validation:Required
+	GraphHash string `json:"graphHash"`
+	// TopologicalOrder is the topological order of the revision graph.
+	//
+	// +kubebuilder:validation:Optional
+	TopologicalOrder []string `json:"topologicalOrder,omitempty"`
+	// Schema is the OpenAPI schema generated for the instances.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Schema runtime.RawExtension `json:"schema,omitempty"`
+	// Definition is the ResourceGraphDefinition spec of the revision, the
+	// graph is built from it when instances use the revision.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Definition runtime.RawExtension `json:"definition"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="RESOURCEGRAPHDEFINITION",type=string,priority=0,JSONPath=`.spec.resourceGraphDefinitionName`
+// +kubebuilder:printcolumn:name="REVISION",type=integer,priority=0,JSONPath=`.spec.revision`
+// +kubebuilder:printcolumn:name="HASH",type=string,priority=1,JSONPath=`.spec.graphHash`
+// +kubebuilder:printcolumn:name="AGE",type="date",priority=0,JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:shortName=rgdrev,scope=Cluster
+
+// ResourceGraphDefinitionRevision is an immutable revision of a
+// ResourceGraphDefinition. kro creates one for every generation of a
+// ResourceGraphDefinition, and deletes them with it.
+type ResourceGraphDefinitionRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+	Spec ResourceGraphDefinitionRevisionSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ResourceGraphDefinitionRevisionList contains a list of ResourceGraphDefinitionRevision
+type ResourceGraphDefinitionRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceGraphDefinitionRevision `json:"items"`
+}
+
 func init() {
 	SchemeBuilder.Register(&ResourceGraphDefinition{}, &ResourceGraphDefinitionList{})
+	SchemeBuilder.Register(&ResourceGraphDefinitionRevision{}, &ResourceGraphDefinitionRevisionList{})
 }
@@ -17,13 +17,18 @@ import (
 	"context"
 	"flag"
 	"os"
+	"strings"
 	"time"
 
 	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/metadata"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -33,6 +38,8 @@ import (
 	resourcegraphdefinitionctrl "github.com/kro-run/kro/pkg/controller/resourcegraphdefinition"
 	"github.com/kro-run/kro/pkg/dynamiccontroller"
 	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/sharding"
+	"github.com/kro-run/kro/pkg/tracing"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -64,6 +71,7 @@ func main() {
 		allowCRDDeletion                            bool
 		resourceGraphDefinitionConcurrentReconciles int
 		dynamicControllerConcurrentReconciles       int
+		dynamicControllerConcurrentReconcilesPerGVR int
 		// dynamic controller rate limiter parameters
 		minRetryDelay time.Duration
 		maxRetryDelay time.Duration
@@ -72,11 +80,38 @@ func main() {
 		// reconciler parameters
 		resyncPeriod    int
 		queueMaxRetries int
-		shutdownTimeout int
+		// stalled items parameters
+		stalledRetryDelay    time.Duration
+		maxStalledRetryDelay time.Duration
+		progressDeadline     time.Duration
+		shutdownTimeout      int
+		// informer parameters
+		metadataOnlyInformers   bool
+		maxCachedAnnotationSize int
+		enableResourceCache     bool
 		// var dynamicControllerDefaultResyncPeriod int
 		logLevel int
 		qps      float64
 		burst    int
+		// impersonation parameters
+		impersonationCacheSize        int
+		serviceAccountRequesterPolicy string
+		// tracing parameters
+		tracingExporter    string
+		tracingEndpoint    string
+		tracingInsecure    bool
+		tracingSampleRatio float64
+		// sharding parameters
+		enableSharding     bool
+		shardID            string
+		shardNamespace     string
+		shardKey           string
+		shardBuckets       int
+		shardLeaseDuration time.Duration
+		// scope parameters
+		watchNamespaces                 string
+		watchNamespaceSelector          string
+		resourceGraphDefinitionSelector string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8078", "The address the metric endpoint binds to.")
@@ -93,6 +128,11 @@ func main() {
 		"dynamic-controller-concurrent-reconciles", 1,
 		"The number of dynamic controller reconciles to run in parallel",
 	)
+	flag.IntVar(&dynamicControllerConcurrentReconcilesPerGVR,
+		"dynamic-controller-concurrent-reconciles-per-gvr", 0,
+		"The maximum number of instances of a ResourceGraphDefinition reconciled in parallel, "+
+			"unless set in its spec. 0 means no limit other than the number of dynamic controller reconciles.",
+	)
 
 	// rate limiter parameters
 	flag.DurationVar(&minRetryDelay, "dynamic-controller-rate-limiter-min-delay", 200*time.Millisecond,
@@ -100,15 +140,16 @@ func main() {
 	flag.DurationVar(&maxRetryDelay, "dynamic-controller-rate-limiter-max-delay", 1000*time.Second,
 		"Maximum delay for the dynamic controller rate limiter, in seconds.")
 	flag.IntVar(&rateLimit, "dynamic-controller-rate-limiter-rate-limit", 10,
-		"Rate limit to control how frequently events are allowed to happen for the dynamic controller.")
+		"Rate limit to control how frequently events are allowed to happen for the instances of a "+
+			"ResourceGraphDefinition, unless set in its spec.")
 	flag.IntVar(&burstLimit, "dynamic-controller-rate-limiter-burst-limit", 100,
-		"Burst size of events for the dynamic controller rate limiter.")
+		"Burst size of events for the instances of a ResourceGraphDefinition, unless set in its spec.")
 
 	// reconciler parameters
 	flag.IntVar(&resyncPeriod, "dynamic-controller-default-resync-period", 10,
 		"interval at which the controller will re list resources even with no changes, in hours")
 	flag.IntVar(&queueMaxRetries, "dynamic-controller-default-queue-max-retries", 20,
-		"maximum number of retries for an item in the queue will be retried before being dropped")
+		"maximum number of retries for an item in the queue will be retried before being stalled")
 	flag.IntVar(&shutdownTimeout, "dynamic-controller-default-shutdown-timeout", 60,
 		"maximum duration to wait for the controller to gracefully shutdown, in seconds")
 	// log level flags
@@ -117,6 +158,64 @@ func main() {
 	flag.Float64Var(&qps, "client-qps", 100, "The number of queries per second to allow")
 	flag.IntVar(&burst, "client-burst", 150,
 		"The number of requests that can be stored for processing before the server starts enforcing the QPS limit")
+	// impersonation flags
+	flag.IntVar(&impersonationCacheSize, "impersonation-cache-size", kroclient.DefaultImpersonationCacheSize,
+		"The maximum number of impersonated service account clients to keep in memory")
+	flag.StringVar(&serviceAccountRequesterPolicy, "service-account-requester-policy", "",
+		"The name of the ValidatingAdmissionPolicy protecting the requester annotation of instances requesting "+
+			"a service account. Instances can only request service accounts when it is set and the policy installed.")
+	// tracing flags
+	flag.StringVar(&tracingExporter, "tracing-exporter", string(tracing.ExporterNone),
+		"The OpenTelemetry span exporter to use. One of: none, otlp-grpc, otlp-http.")
+	flag.StringVar(&tracingEndpoint, "tracing-endpoint", "",
+		"The OTLP collector endpoint (host:port). Defaults to the OTEL_EXPORTER_OTLP_* environment variables.")
+	flag.BoolVar(&tracingInsecure, "tracing-insecure", false,
+		"Disable TLS when exporting spans to the OTLP collector.")
+	flag.Float64Var(&tracingSampleRatio, "tracing-sample-ratio", 1,
+		"The ratio of reconciliations that are traced, between 0 and 1.")
+
+	// sharding flags
+	flag.BoolVar(&enableSharding, "enable-sharding", false,
+		"Shard the instances across the controller replicas. Every replica reconciles the instances of its shard.")
+	flag.StringVar(&shardID, "shard-id", os.Getenv("POD_NAME"),
+		"The identity of the replica among the shards. Defaults to the POD_NAME environment variable, or the hostname.")
+	flag.StringVar(&shardNamespace, "shard-lease-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace of the Leases of the shards. Defaults to the POD_NAMESPACE environment variable.")
+	flag.StringVar(&shardKey, "shard-key", string(sharding.KeyName),
+		"What instances are hashed by to assign them to shards. One of: name, uid.")
+	flag.IntVar(&shardBuckets, "shard-buckets", sharding.DefaultBuckets,
+		"The number of buckets instances are hashed into, must be the same on all the replicas.")
+	flag.DurationVar(&shardLeaseDuration, "shard-lease-duration", sharding.DefaultLeaseDuration,
+		"The duration after which the instances of a replica that stopped renewing its Lease move to the other replicas. "+
+			"Replicas also wait for it before taking over the instances of replicas that are still live.")
+
+	// stalled items flags
+	flag.DurationVar(&stalledRetryDelay, "dynamic-controller-stalled-retry-delay", dynamiccontroller.DefaultStalledRetryDelay,
+		"The delay before instances that failed past the max retries are reconciled again. It doubles each time they fail again.")
+	flag.DurationVar(&maxStalledRetryDelay, "dynamic-controller-max-stalled-retry-delay", dynamiccontroller.DefaultMaxStalledRetryDelay,
+		"The maximum delay before instances that failed past the max retries are reconciled again.")
+	flag.DurationVar(&progressDeadline, "dynamic-controller-progress-deadline", dynamiccontroller.DefaultProgressDeadline,
+		"The duration after which the liveness probe fails if instances are queued but the dynamic controller "+
+			"workers made no progress. 0 disables the check.")
+
+	// informer flags
+	flag.BoolVar(&metadataOnlyInformers, "dynamic-controller-metadata-only-informers", false,
+		"Cache the metadata of the instances only, reducing the memory of the informers.")
+	flag.IntVar(&maxCachedAnnotationSize, "dynamic-controller-max-cached-annotation-size",
+		dynamiccontroller.DefaultMaxCachedAnnotationSize,
+		"The size, in bytes, above which annotation values are stripped from the cached instances.")
+	flag.BoolVar(&enableResourceCache, "enable-resource-cache", true,
+		"Read the resources of the instances from shared informer caches instead of the API server, "+
+			"falling back to live reads while the caches sync.")
+
+	// scope flags
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"A comma separated list of the namespaces instances are reconciled in. Each namespace is watched separately, "+
+			"so that kro only needs permissions in these namespaces. Defaults to all the namespaces.")
+	flag.StringVar(&watchNamespaceSelector, "watch-namespace-selector", "",
+		"A label selector of the namespaces instances are reconciled in. Defaults to all the namespaces.")
+	flag.StringVar(&resourceGraphDefinitionSelector, "resource-graph-definition-selector", "",
+		"A label selector of the ResourceGraphDefinitions served by this installation of kro. Defaults to all of them.")
 
 	flag.Parse()
 
@@ -129,6 +228,17 @@ func main() {
 
 	ctrl.SetLogger(rootLogger)
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    tracing.Exporter(tracingExporter),
+		Endpoint:    tracingEndpoint,
+		Insecure:    tracingInsecure,
+		SampleRatio: tracingSampleRatio,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	set, err := kroclient.NewSet(kroclient.Config{
 		QPS:   float32(qps),
 		Burst: burst,
@@ -139,8 +249,23 @@ func main() {
 	}
 	restConfig := set.RESTConfig()
 
+	// Separate installations of kro can serve different
+	// ResourceGraphDefinitions, e.g for different tenants.
+	var cacheOptions cache.Options
+	if resourceGraphDefinitionSelector != "" {
+		selector, err := labels.Parse(resourceGraphDefinitionSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid resource graph definition selector")
+			os.Exit(1)
+		}
+		cacheOptions.ByObject = map[client.Object]cache.ByObject{
+			&xv1alpha1.ResourceGraphDefinition{}: {Label: selector},
+		}
+	}
+
 	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOptions,
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
@@ -165,17 +290,70 @@ func main() {
 		os.Exit(1)
 	}
 
-	dc := dynamiccontroller.NewDynamicController(rootLogger, dynamiccontroller.Config{
+	var sharder *sharding.Sharder
+	if enableSharding {
+		if shardID == "" {
+			shardID, _ = os.Hostname()
+		}
+		sharder, err = sharding.NewSharder(rootLogger, sharding.Config{
+			ID:            shardID,
+			Namespace:     shardNamespace,
+			Key:           sharding.Key(shardKey),
+			Buckets:       shardBuckets,
+			LeaseDuration: shardLeaseDuration,
+			RenewInterval: shardLeaseDuration / 3,
+		}, set.Kubernetes())
+		if err != nil {
+			setupLog.Error(err, "unable to create sharder")
+			os.Exit(1)
+		}
+		if err := mgr.Add(sharder); err != nil {
+			setupLog.Error(err, "unable to add sharder to the manager")
+			os.Exit(1)
+		}
+	}
+
+	dcConfig := dynamiccontroller.Config{
 		Workers: dynamicControllerConcurrentReconciles,
 		// TODO(a-hilaly): expose these as flags
-		ShutdownTimeout: time.Duration(shutdownTimeout) * time.Second,
-		ResyncPeriod:    time.Duration(resyncPeriod) * time.Hour,
-		QueueMaxRetries: queueMaxRetries,
-		MinRetryDelay:   minRetryDelay,
-		MaxRetryDelay:   maxRetryDelay,
-		RateLimit:       rateLimit,
-		BurstLimit:      burstLimit,
-	}, set.Dynamic())
+		ShutdownTimeout:      time.Duration(shutdownTimeout) * time.Second,
+		ResyncPeriod:         time.Duration(resyncPeriod) * time.Hour,
+		QueueMaxRetries:      queueMaxRetries,
+		StalledRetryDelay:    stalledRetryDelay,
+		MaxStalledRetryDelay: maxStalledRetryDelay,
+		ProgressDeadline:     progressDeadline,
+		MinRetryDelay:        minRetryDelay,
+		MaxRetryDelay:        maxRetryDelay,
+		RateLimit:            rateLimit,
+		BurstLimit:           burstLimit,
+
+		MaxConcurrentReconcilesPerGVR: dynamicControllerConcurrentReconcilesPerGVR,
+		MaxCachedAnnotationSize:       maxCachedAnnotationSize,
+	}
+	if metadataOnlyInformers {
+		dcConfig.MetadataClient, err = metadata.NewForConfig(restConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to create metadata client")
+			os.Exit(1)
+		}
+	}
+	for _, namespace := range strings.Split(watchNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			dcConfig.Namespaces = append(dcConfig.Namespaces, namespace)
+		}
+	}
+	if watchNamespaceSelector != "" {
+		dcConfig.NamespaceSelector, err = labels.Parse(watchNamespaceSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid watch namespace selector")
+			os.Exit(1)
+		}
+	}
+	// Avoid setting a nil *Sharder, which is a non nil interface.
+	if sharder != nil {
+		dcConfig.Sharder = sharder
+	}
+	dc := dynamiccontroller.NewDynamicController(rootLogger, dcConfig, set.Dynamic())
 
 	resourceGraphDefinitionGraphBuilder, err := graph.NewBuilder(
 		restConfig,
@@ -185,11 +363,25 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The resources of the instances are cached in the namespaces kro
+	// watches, and read live elsewhere.
+	var resourceCache *kroclient.ResourceCache
+	if enableResourceCache {
+		resourceCache = kroclient.NewResourceCache(rootLogger, set.Dynamic(), dcConfig.Namespaces)
+		if err := mgr.Add(resourceCache); err != nil {
+			setupLog.Error(err, "unable to add resource cache to the manager")
+			os.Exit(1)
+		}
+	}
+
 	rgd := resourcegraphdefinitionctrl.NewResourceGraphDefinitionReconciler(
 		set,
 		allowCRDDeletion,
 		dc,
 		resourceGraphDefinitionGraphBuilder,
+		kroclient.NewImpersonationCache(set, impersonationCacheSize),
+		kroclient.NewServiceAccountAuthorizer(set.Kubernetes(), serviceAccountRequesterPolicy),
+		resourceCache,
 		resourceGraphDefinitionConcurrentReconciles,
 	)
 	if err := rgd.SetupWithManager(mgr); err != nil {
@@ -197,12 +389,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	go func() {
-		err := dc.Run(context.Background())
-		if err != nil {
-			setupLog.Error(err, "dynamic controller failed to run")
-		}
-	}()
+	// The dynamic controller only runs on the leader, unless the instances are
+	// sharded, and stops with the manager.
+	if err := mgr.Add(dc); err != nil {
+		setupLog.Error(err, "unable to add dynamic controller to the manager")
+		os.Exit(1)
+	}
 
 	//+kubebuilder:scaffold:builder
 
@@ -211,7 +403,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	// Wedged dynamic controller workers restart the replica.
+	if err = mgr.AddHealthzCheck("dynamic-controller", dc.LivenessCheck); err != nil {
+		setupLog.Error(err, "unable to set up dynamic controller health check")
+		os.Exit(1)
+	}
+
+	// Replicas are ready once the ResourceGraphDefinitions are served and
+	// the informers of their instances synced, so that rolling upgrades
+	// don't stop the previous replicas before.
+	if err = mgr.AddReadyzCheck("readyz", rgd.ReadyCheck); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
@@ -227,4 +428,9 @@ func main() {
 
 	<-ctx.Done()
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(shutdownTimeout)*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "failed to flush traces")
+	}
 }
@@ -31,4 +31,5 @@ func main() {
 
 func init() {
 	// Add subcommands and configure global flags here
+	rootCmd.AddCommand(newPlanCommand())
 }
@@ -0,0 +1,267 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	kroclient "github.com/kro-run/kro/pkg/client"
+	instancectrl "github.com/kro-run/kro/pkg/controller/instance"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+type planOptions struct {
+	filename   string
+	kubeconfig string
+	output     string
+	delete     bool
+}
+
+func newPlanCommand() *cobra.Command {
+	opts := &planOptions{}
+	cmd := &cobra.Command{
+		Use:   "plan -f instance.yaml",
+		Short: "Preview the changes kro would make to the resources of an instance",
+		Long: `Preview the changes kro would make to the resources of an instance.
+
+The instance is read from a file and reconciled in dry-run mode against the
+cluster: its ResourceGraphDefinition is looked up by kind, and the resources
+are created and updated with server-side dry-run. Nothing is persisted.
+
+When the instance already exists, its spec is replaced by the one of the file.
+Use --delete to preview the deletion of an existing instance instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runPlan(cmd.Context(), cmd.OutOrStdout(), opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.filename, "filename", "f", "", "The file holding the instance to plan")
+	cmd.Flags().StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, defaults to $KUBECONFIG or ~/.kube/config")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output format, one of text or json")
+	cmd.Flags().BoolVar(&opts.delete, "delete", false, "Preview the deletion of the instance")
+	_ = cmd.MarkFlagRequired("filename")
+	return cmd
+}
+
+func runPlan(ctx context.Context, out io.Writer, opts *planOptions) error {
+	if opts.output != "text" && opts.output != "json" {
+		return fmt.Errorf("unsupported output format %q", opts.output)
+	}
+
+	instance, err := readInstance(opts.filename)
+	if err != nil {
+		return err
+	}
+
+	restConfig, err := loadRESTConfig(opts.kubeconfig)
+	if err != nil {
+		return err
+	}
+	set, err := kroclient.NewSet(kroclient.Config{RestConfig: restConfig})
+	if err != nil {
+		return fmt.Errorf("failed to create client set: %w", err)
+	}
+
+	rgd, err := findResourceGraphDefinition(ctx, set, instance)
+	if err != nil {
+		return err
+	}
+	builder, err := graph.NewBuilder(set.RESTConfig())
+	if err != nil {
+		return fmt.Errorf("failed to create graph builder: %w", err)
+	}
+	g, err := builder.NewResourceGraphDefinition(ctx, rgd)
+	if err != nil {
+		return fmt.Errorf("failed to build ResourceGraphDefinition %s: %w", rgd.Name, err)
+	}
+
+	gvr := g.Instance.GetGroupVersionResource()
+	instance, err = resolveInstance(ctx, set.Dynamic().Resource(gvr).Namespace(instance.GetNamespace()), instance, opts.delete)
+	if err != nil {
+		return err
+	}
+
+	labeler, err := metadata.NewKROMetaLabeler().Merge(metadata.NewResourceGraphDefinitionLabeler(rgd))
+	if err != nil {
+		return fmt.Errorf("failed to create labeler: %w", err)
+	}
+	controller := instancectrl.NewController(
+		logr.Discard(),
+		instancectrl.ReconcileConfig{DefaultRequeueDuration: 3 * time.Second},
+		gvr,
+		g,
+		set,
+		nil,
+		nil,
+		rgd.Spec.DefaultServiceAccounts,
+		rgd.Spec.ServiceAccountPolicy,
+		// Plans can't trust the requester of a service account either.
+		nil,
+		labeler,
+		nil,
+	)
+	plan, err := controller.Plan(ctx, instance)
+	if err != nil {
+		return fmt.Errorf("failed to plan instance %s/%s: %w", instance.GetNamespace(), instance.GetName(), err)
+	}
+
+	if opts.output == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	}
+	return printPlan(out, plan)
+}
+
+// readInstance reads the instance from a YAML or JSON file.
+func readInstance(filename string) (*unstructured.Unstructured, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	instance := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &instance.Object); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	if instance.GetKind() == "" || instance.GetName() == "" {
+		return nil, fmt.Errorf("%s must hold an instance with a kind and a name", filename)
+	}
+	if instance.GetNamespace() == "" {
+		instance.SetNamespace(metav1.NamespaceDefault)
+	}
+	return instance, nil
+}
+
+func loadRESTConfig(kubeconfig string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+// findResourceGraphDefinition returns the ResourceGraphDefinition defining
+// the kind of the instance.
+func findResourceGraphDefinition(ctx context.Context, set *kroclient.Set, instance *unstructured.Unstructured) (*v1alpha1.ResourceGraphDefinition, error) {
+	list, err := set.Dynamic().Resource(v1alpha1.GroupVersion.WithResource("resourcegraphdefinitions")).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ResourceGraphDefinitions: %w", err)
+	}
+
+	gvk := instance.GroupVersionKind()
+	for _, item := range list.Items {
+		rgd := &v1alpha1.ResourceGraphDefinition{}
+		if err := k8sruntime.DefaultUnstructuredConverter.FromUnstructured(item.Object, rgd); err != nil {
+			return nil, fmt.Errorf("failed to convert ResourceGraphDefinition %s: %w", item.GetName(), err)
+		}
+		if rgd.Spec.Schema == nil {
+			continue
+		}
+		group := rgd.Spec.Schema.Group
+		if group == "" {
+			group = v1alpha1.KRODomainName
+		}
+		if group == gvk.Group && rgd.Spec.Schema.APIVersion == gvk.Version && rgd.Spec.Schema.Kind == gvk.Kind {
+			return rgd, nil
+		}
+	}
+	return nil, fmt.Errorf("no ResourceGraphDefinition defines %s", gvk)
+}
+
+// resolveInstance returns the instance as the API server would store it, with
+// defaulted fields. Existing instances get the spec of the file, or are marked
+// for deletion.
+func resolveInstance(
+	ctx context.Context,
+	client dynamic.ResourceInterface,
+	instance *unstructured.Unstructured,
+	planDeletion bool,
+) (*unstructured.Unstructured, error) {
+	dryRun := []string{metav1.DryRunAll}
+
+	existing, err := client.Get(ctx, instance.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if planDeletion {
+			return nil, fmt.Errorf("instance %s/%s doesn't exist", instance.GetNamespace(), instance.GetName())
+		}
+		created, err := client.Create(ctx, instance, metav1.CreateOptions{DryRun: dryRun})
+		if err != nil {
+			return nil, fmt.Errorf("invalid instance: %w", err)
+		}
+		return created, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	if planDeletion {
+		now := metav1.Now()
+		existing.SetDeletionTimestamp(&now)
+		return existing, nil
+	}
+	existing.Object["spec"] = instance.Object["spec"]
+	updated, err := client.Update(ctx, existing, metav1.UpdateOptions{DryRun: dryRun})
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance: %w", err)
+	}
+	return updated, nil
+}
+
+// printPlan prints the plan as a table, followed by the differences of each
+// update.
+func printPlan(out io.Writer, plan *instancectrl.Plan) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tACTION\tKIND\tNAMESPACE\tNAME\tREASON")
+	for _, change := range plan.Resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			change.ID, change.Action, change.Kind, change.Namespace, change.Name, change.Reason)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, change := range plan.Resources {
+		if len(change.Differences) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s (%s %s):\n", change.ID, change.Action, change.Kind)
+		for _, difference := range change.Differences {
+			observed, _ := json.Marshal(difference.Observed)
+			desired, _ := json.Marshal(difference.Desired)
+			fmt.Fprintf(out, "  ~ %s: %s -> %s\n", difference.Path, observed, desired)
+		}
+	}
+	return nil
+}
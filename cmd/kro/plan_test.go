@@ -0,0 +1,181 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	instancectrl "github.com/kro-run/kro/pkg/controller/instance"
+	"github.com/kro-run/kro/pkg/controller/instance/delta"
+)
+
+var appsGVR = schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "apps"}
+
+// dryRunRecorder records the DryRun option of the writes, which the fake
+// dynamic client ignores.
+type dryRunRecorder struct {
+	dynamic.ResourceInterface
+	dryRuns [][]string
+}
+
+func (r *dryRunRecorder) Create(
+	ctx context.Context,
+	obj *unstructured.Unstructured,
+	options metav1.CreateOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	r.dryRuns = append(r.dryRuns, options.DryRun)
+	return r.ResourceInterface.Create(ctx, obj, options, subresources...)
+}
+
+func (r *dryRunRecorder) Update(
+	ctx context.Context,
+	obj *unstructured.Unstructured,
+	options metav1.UpdateOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	r.dryRuns = append(r.dryRuns, options.DryRun)
+	return r.ResourceInterface.Update(ctx, obj, options, subresources...)
+}
+
+func newApp(spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kro.run/v1alpha1",
+		"kind":       "App",
+		"metadata":   map[string]interface{}{"name": "my-app", "namespace": "default"},
+		"spec":       spec,
+	}}
+}
+
+func TestResolveInstance(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     *unstructured.Unstructured
+		planDeletion bool
+		wantErr      string
+		wantDryRuns  int
+	}{
+		{
+			name:        "new instance",
+			wantDryRuns: 1,
+		},
+		{
+			name:        "existing instance",
+			existing:    newApp(map[string]interface{}{"replicas": int64(1)}),
+			wantDryRuns: 1,
+		},
+		{
+			name:         "deleted instance",
+			existing:     newApp(map[string]interface{}{"replicas": int64(1)}),
+			planDeletion: true,
+		},
+		{
+			name:         "deleted instance that doesn't exist",
+			planDeletion: true,
+			wantErr:      "instance default/my-app doesn't exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []k8sruntime.Object
+			if tt.existing != nil {
+				objects = append(objects, tt.existing)
+			}
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(),
+				map[schema.GroupVersionResource]string{appsGVR: "AppList"}, objects...)
+			recorder := &dryRunRecorder{ResourceInterface: client.Resource(appsGVR).Namespace("default")}
+
+			instance, err := resolveInstance(context.Background(), recorder, newApp(map[string]interface{}{"replicas": int64(3)}), tt.planDeletion)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			// Instances are only ever written with server-side dry-run.
+			assert.Len(t, recorder.dryRuns, tt.wantDryRuns)
+			for _, dryRun := range recorder.dryRuns {
+				assert.Equal(t, []string{metav1.DryRunAll}, dryRun)
+			}
+			if tt.planDeletion {
+				assert.NotNil(t, instance.GetDeletionTimestamp())
+				assert.Equal(t, tt.existing.Object["spec"], instance.Object["spec"])
+			} else {
+				assert.Nil(t, instance.GetDeletionTimestamp())
+				assert.Equal(t, map[string]interface{}{"replicas": int64(3)}, instance.Object["spec"])
+			}
+		})
+	}
+}
+
+func TestReadInstance(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("apiVersion: kro.run/v1alpha1\nkind: App\nmetadata:\n  name: my-app\n"), 0o600))
+
+	instance, err := readInstance(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "my-app", instance.GetName())
+	assert.Equal(t, metav1.NamespaceDefault, instance.GetNamespace())
+
+	require.NoError(t, os.WriteFile(filename, []byte("apiVersion: kro.run/v1alpha1\nkind: App\n"), 0o600))
+	_, err = readInstance(filename)
+	assert.ErrorContains(t, err, "must hold an instance with a kind and a name")
+}
+
+func TestPrintPlan(t *testing.T) {
+	plan := &instancectrl.Plan{Resources: []instancectrl.PlannedChange{
+		{
+			ID:        "deployment",
+			Kind:      "Deployment",
+			Namespace: "default",
+			Name:      "my-app",
+			Action:    instancectrl.PlanActionUpdate,
+			Differences: []delta.Difference{
+				{Path: "spec.replicas", Observed: int64(3), Desired: int64(5)},
+			},
+		},
+		{ID: "service", Kind: "Service", Namespace: "default", Name: "my-app", Action: instancectrl.PlanActionNone},
+		{ID: "ingress", Action: instancectrl.PlanActionUnknown, Reason: "depends on values that are only known once the changes are applied"},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, printPlan(&out, plan))
+	lines := strings.Split(out.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	assert.Equal(t, `RESOURCE    ACTION   KIND        NAMESPACE  NAME    REASON
+deployment  Update   Deployment  default    my-app
+service     None     Service     default    my-app
+ingress     Unknown                                 depends on values that are only known once the changes are applied
+
+deployment (Update Deployment):
+  ~ spec.replicas: 3 -> 5
+`, strings.Join(lines, "\n"))
+}
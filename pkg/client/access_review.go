@@ -0,0 +1,136 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+const (
+	// accessReviewTTL is the duration for which access review results are
+	// cached. It bounds both the number of reviews sent to the API server and
+	// the time it takes for an RBAC change to be noticed.
+	accessReviewTTL = time.Minute
+)
+
+// Permission describes an action on a resource type, in a namespace.
+type Permission struct {
+	// GVR is the resource type the permission applies to.
+	GVR schema.GroupVersionResource
+	// Namespace is the namespace the permission applies to. An empty namespace
+	// means cluster scoped resources, or all namespaces.
+	Namespace string
+	// Verb is the API verb, e.g get, create, update or delete.
+	Verb string
+}
+
+// String returns a human readable representation of the permission, e.g
+// "create deployments.apps in namespace default".
+func (p Permission) String() string {
+	resource := p.GVR.GroupResource().String()
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", p.Verb, resource, p.Namespace)
+}
+
+// accessReviewCache caches the results of SelfSubjectAccessReviews.
+type accessReviewCache struct {
+	mu      sync.Mutex
+	entries map[Permission]accessReviewEntry
+}
+
+type accessReviewEntry struct {
+	allowed bool
+	expires time.Time
+}
+
+func newAccessReviewCache() *accessReviewCache {
+	return &accessReviewCache{entries: make(map[Permission]accessReviewEntry)}
+}
+
+func (c *accessReviewCache) get(p Permission, now time.Time) (allowed, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[p]
+	if !ok || now.After(entry.expires) {
+		delete(c.entries, p)
+		return false, false
+	}
+	return entry.allowed, true
+}
+
+func (c *accessReviewCache) set(p Permission, allowed bool, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[p] = accessReviewEntry{allowed: allowed, expires: now.Add(accessReviewTTL)}
+}
+
+// ReviewAccess checks, using SelfSubjectAccessReviews, whether the identity
+// of the client Set holds the given permissions. It returns the permissions
+// that are denied, in the order they were given. Results are cached for a
+// short period of time, so that reviewing the same permissions on every
+// reconciliation stays cheap.
+func (c *Set) ReviewAccess(ctx context.Context, permissions []Permission) ([]Permission, error) {
+	return reviewAccess(ctx, c.kubernetes.AuthorizationV1().SelfSubjectAccessReviews(), c.accessReviews, permissions)
+}
+
+func reviewAccess(
+	ctx context.Context,
+	client authorizationv1client.SelfSubjectAccessReviewInterface,
+	cache *accessReviewCache,
+	permissions []Permission,
+) ([]Permission, error) {
+	var denied []Permission
+	seen := make(map[Permission]bool, len(permissions))
+	for _, p := range permissions {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		now := time.Now()
+		allowed, ok := cache.get(p, now)
+		if !ok {
+			review, err := client.Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: p.Namespace,
+						Verb:      p.Verb,
+						Group:     p.GVR.Group,
+						Version:   p.GVR.Version,
+						Resource:  p.GVR.Resource,
+					},
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to review access to %s: %w", p, err)
+			}
+			allowed = review.Status.Allowed
+			cache.set(p, allowed, now)
+		}
+		if !allowed {
+			denied = append(denied, p)
+		}
+	}
+	return denied, nil
+}
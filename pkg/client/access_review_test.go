@@ -0,0 +1,71 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestReviewAccess(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	services := schema.GroupVersionResource{Version: "v1", Resource: "services"}
+
+	clientset := fake.NewSimpleClientset()
+	reviews := 0
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		// Only deny deleting services.
+		review.Status.Allowed = !(attrs.Resource == "services" && attrs.Verb == "delete")
+		return true, review, nil
+	})
+
+	permissions := []Permission{
+		{GVR: deployments, Namespace: "default", Verb: "create"},
+		{GVR: services, Namespace: "default", Verb: "create"},
+		{GVR: services, Namespace: "default", Verb: "delete"},
+		// Duplicates are only reviewed once.
+		{GVR: services, Namespace: "default", Verb: "delete"},
+	}
+
+	cache := newAccessReviewCache()
+	denied, err := reviewAccess(context.Background(), clientset.AuthorizationV1().SelfSubjectAccessReviews(), cache, permissions)
+	require.NoError(t, err)
+	assert.Equal(t, []Permission{{GVR: services, Namespace: "default", Verb: "delete"}}, denied)
+	assert.Equal(t, 3, reviews)
+
+	// Results are served from the cache.
+	denied, err = reviewAccess(context.Background(), clientset.AuthorizationV1().SelfSubjectAccessReviews(), cache, permissions)
+	require.NoError(t, err)
+	assert.Len(t, denied, 1)
+	assert.Equal(t, 3, reviews)
+}
+
+func TestPermissionString(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	assert.Equal(t, "create deployments.apps in namespace default",
+		Permission{GVR: deployments, Namespace: "default", Verb: "create"}.String())
+	assert.Equal(t, "get namespaces",
+		Permission{GVR: schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, Verb: "get"}.String())
+}
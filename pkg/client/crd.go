@@ -20,10 +20,12 @@ import (
 
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logr "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -38,8 +40,9 @@ var _ CRDClient = &CRDWrapper{}
 
 // CRDClient represents operations for managing CustomResourceDefinitions
 type CRDClient interface {
-	// EnsureCreated ensures a CRD exists and is ready
-	Ensure(ctx context.Context, crd v1.CustomResourceDefinition) error
+	// Ensure ensures a CRD exists, is up-to-date and is ready. It returns the
+	// ready CRD, and whether it was created, updated or left unchanged.
+	Ensure(ctx context.Context, crd v1.CustomResourceDefinition) (*v1.CustomResourceDefinition, controllerutil.OperationResult, error)
 
 	// Delete removes a CRD if it exists
 	Delete(ctx context.Context, name string) error
@@ -91,26 +94,39 @@ func newCRDWrapper(cfg CRDWrapperConfig) *CRDWrapper {
 //
 // The caller is responsible for ensuring the CRD, isn't introducing
 // breaking changes.
-func (w *CRDWrapper) Ensure(ctx context.Context, crd v1.CustomResourceDefinition) error {
+func (w *CRDWrapper) Ensure(
+	ctx context.Context,
+	crd v1.CustomResourceDefinition,
+) (*v1.CustomResourceDefinition, controllerutil.OperationResult, error) {
 	log := logr.FromContext(ctx)
-	_, err := w.Get(ctx, crd.Name)
+	result := controllerutil.OperationResultNone
+	existing, err := w.Get(ctx, crd.Name)
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to check for existing CRD: %w", err)
+			return nil, result, fmt.Errorf("failed to check for existing CRD: %w", err)
 		}
 
 		log.Info("Creating CRD", "name", crd.Name)
 		if err := w.create(ctx, crd); err != nil {
-			return fmt.Errorf("failed to create CRD: %w", err)
+			return nil, result, fmt.Errorf("failed to create CRD: %w", err)
 		}
+		result = controllerutil.OperationResultCreated
 	} else {
 		log.Info("Updating existing CRD", "name", crd.Name)
-		if err := w.patch(ctx, crd); err != nil {
-			return fmt.Errorf("failed to patch CRD: %w", err)
+		patched, err := w.patch(ctx, crd)
+		if err != nil {
+			return nil, result, fmt.Errorf("failed to patch CRD: %w", err)
+		}
+		if !equality.Semantic.DeepEqual(existing.Spec, patched.Spec) {
+			result = controllerutil.OperationResultUpdated
 		}
 	}
 
-	return w.waitForReady(ctx, crd.Name)
+	ready, err := w.waitForReady(ctx, crd.Name)
+	if err != nil {
+		return nil, result, err
+	}
+	return ready, result, nil
 }
 
 // Get retrieves a CRD by name
@@ -123,20 +139,19 @@ func (w *CRDWrapper) create(ctx context.Context, crd v1.CustomResourceDefinition
 	return err
 }
 
-func (w *CRDWrapper) patch(ctx context.Context, newCRD v1.CustomResourceDefinition) error {
+func (w *CRDWrapper) patch(ctx context.Context, newCRD v1.CustomResourceDefinition) (*v1.CustomResourceDefinition, error) {
 	patchBytes, err := json.Marshal(newCRD)
 	if err != nil {
-		return fmt.Errorf("failed to marshal CRD for patch: %w", err)
+		return nil, fmt.Errorf("failed to marshal CRD for patch: %w", err)
 	}
 
-	_, err = w.client.Patch(
+	return w.client.Patch(
 		ctx,
 		newCRD.Name,
 		types.MergePatchType,
 		patchBytes,
 		metav1.PatchOptions{},
 	)
-	return err
 }
 
 // Delete removes a CRD if it exists
@@ -151,12 +166,13 @@ func (w *CRDWrapper) Delete(ctx context.Context, name string) error {
 	return nil
 }
 
-// waitForReady waits for a CRD to become ready
-func (w *CRDWrapper) waitForReady(ctx context.Context, name string) error {
+// waitForReady waits for a CRD to become ready, and returns it.
+func (w *CRDWrapper) waitForReady(ctx context.Context, name string) (*v1.CustomResourceDefinition, error) {
 	log := logr.FromContext(ctx)
 	log.Info("Waiting for CRD to become ready", "name", name)
 
-	return wait.PollUntilContextTimeout(ctx, w.pollInterval, w.timeout, true,
+	var ready *v1.CustomResourceDefinition
+	err := wait.PollUntilContextTimeout(ctx, w.pollInterval, w.timeout, true,
 		func(ctx context.Context) (bool, error) {
 			crd, err := w.Get(ctx, name)
 			if err != nil {
@@ -168,10 +184,12 @@ func (w *CRDWrapper) waitForReady(ctx context.Context, name string) error {
 
 			for _, cond := range crd.Status.Conditions {
 				if cond.Type == v1.Established && cond.Status == v1.ConditionTrue {
+					ready = crd
 					return true, nil
 				}
 			}
 
 			return false, nil
 		})
+	return ready, err
 }
@@ -0,0 +1,104 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func newTestCRD(kind string) v1.CustomResourceDefinition {
+	return v1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "tests.kro.run"},
+		Spec: v1.CustomResourceDefinitionSpec{
+			Group: "kro.run",
+			Names: v1.CustomResourceDefinitionNames{Kind: kind, Plural: "tests"},
+			Scope: v1.NamespaceScoped,
+		},
+	}
+}
+
+func TestCRDWrapperEnsure(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	gets := 0
+	// The API server establishes the CRDs it stores, whatever the status sent
+	// along a create or patch.
+	clientset.PrependReactor("get", "customresourcedefinitions", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		gets++
+		obj, err := clientset.Tracker().Get(action.GetResource(), "", action.(k8stesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		crd := obj.(*v1.CustomResourceDefinition)
+		crd.Status.Conditions = []v1.CustomResourceDefinitionCondition{
+			{Type: v1.Established, Status: v1.ConditionTrue},
+		}
+		return true, crd, nil
+	})
+
+	w := &CRDWrapper{
+		client:       clientset.ApiextensionsV1().CustomResourceDefinitions(),
+		pollInterval: time.Millisecond,
+		timeout:      time.Second,
+	}
+
+	tests := []struct {
+		name   string
+		crd    v1.CustomResourceDefinition
+		result controllerutil.OperationResult
+	}{
+		{name: "missing CRD is created", crd: newTestCRD("Test"), result: controllerutil.OperationResultCreated},
+		{name: "unchanged CRD is left as is", crd: newTestCRD("Test"), result: controllerutil.OperationResultNone},
+		{name: "changed CRD is updated", crd: newTestCRD("Other"), result: controllerutil.OperationResultUpdated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gets = 0
+			crd, result, err := w.Ensure(context.Background(), tt.crd)
+			require.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			// The returned CRD is the ready one, so callers don't need to get
+			// it again.
+			require.NotNil(t, crd)
+			assert.Equal(t, tt.crd.Spec.Names.Kind, crd.Spec.Names.Kind)
+			assert.Equal(t, v1.Established, crd.Status.Conditions[0].Type)
+			// One get to find the existing CRD, and one to check it is ready.
+			assert.Equal(t, 2, gets)
+		})
+	}
+}
+
+func TestCRDWrapperEnsureNotReady(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	w := &CRDWrapper{
+		client:       clientset.ApiextensionsV1().CustomResourceDefinitions(),
+		pollInterval: time.Millisecond,
+		timeout:      10 * time.Millisecond,
+	}
+
+	crd, result, err := w.Ensure(context.Background(), newTestCRD("Test"))
+	assert.Error(t, err)
+	assert.Nil(t, crd)
+	assert.Equal(t, controllerutil.OperationResultCreated, result)
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	// DefaultImpersonationCacheSize is the default maximum number of
+	// impersonated client sets kept in an ImpersonationCache.
+	DefaultImpersonationCacheSize = 128
+)
+
+// ImpersonationCache is a bounded, least recently used cache of impersonated
+// client sets keyed by user name. Building an impersonated Set creates new
+// REST clients (and with them new HTTP transports and connections), so the
+// cache is shared by all the instance controllers to reuse them across
+// reconciliations.
+//
+// ImpersonationCache is safe for concurrent use.
+type ImpersonationCache struct {
+	base    *Set
+	maxSize int
+
+	mu sync.Mutex
+	// lru holds the cache entries, the most recently used entry is at the front.
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type impersonationCacheEntry struct {
+	user string
+	set  *Set
+}
+
+// NewImpersonationCache returns a new ImpersonationCache that builds its
+// clients from the given base Set. A maxSize lower or equal to zero defaults
+// to DefaultImpersonationCacheSize.
+func NewImpersonationCache(base *Set, maxSize int) *ImpersonationCache {
+	if maxSize <= 0 {
+		maxSize = DefaultImpersonationCacheSize
+	}
+	return &ImpersonationCache{
+		base:    base,
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the client Set impersonating the given user, creating and
+// caching it if needed.
+func (c *ImpersonationCache) Get(user string) (*Set, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[user]; ok {
+		c.lru.MoveToFront(elem)
+		impersonationCacheHits.Inc()
+		return elem.Value.(*impersonationCacheEntry).set, nil
+	}
+	impersonationCacheMisses.Inc()
+
+	// NOTE: the lock is held while building the client. Building a Set does
+	// not reach the API server, and holding the lock guarantees that we never
+	// build the same client twice when several workers miss at once.
+	set, err := c.base.WithImpersonation(user)
+	if err != nil {
+		return nil, err
+	}
+
+	c.entries[user] = c.lru.PushFront(&impersonationCacheEntry{user: user, set: set})
+	for c.lru.Len() > c.maxSize {
+		c.removeElement(c.lru.Back())
+		impersonationCacheEvictions.WithLabelValues("size").Inc()
+	}
+	impersonationCacheSize.Set(float64(c.lru.Len()))
+	return set, nil
+}
+
+// Invalidate removes all the cached clients whose user matches the given
+// predicate, and returns the number of removed entries.
+func (c *ImpersonationCache) Invalidate(match func(user string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for user, elem := range c.entries {
+		if match(user) {
+			c.removeElement(elem)
+			removed++
+		}
+	}
+	impersonationCacheEvictions.WithLabelValues("invalidation").Add(float64(removed))
+	impersonationCacheSize.Set(float64(c.lru.Len()))
+	return removed
+}
+
+// Len returns the number of cached clients.
+func (c *ImpersonationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// removeElement removes an entry from the cache. The caller must hold the lock.
+func (c *ImpersonationCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*impersonationCacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.user)
+}
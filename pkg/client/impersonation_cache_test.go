@@ -0,0 +1,90 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func newTestSet(t *testing.T) *Set {
+	t.Helper()
+	set, err := NewSet(Config{RestConfig: &rest.Config{Host: "https://localhost:6443"}})
+	require.NoError(t, err)
+	return set
+}
+
+func TestImpersonationCacheGet(t *testing.T) {
+	cache := NewImpersonationCache(newTestSet(t), 2)
+
+	hits := testutil.ToFloat64(impersonationCacheHits)
+	misses := testutil.ToFloat64(impersonationCacheMisses)
+
+	first, err := cache.Get("system:serviceaccount:default:a")
+	require.NoError(t, err)
+	assert.Equal(t, "system:serviceaccount:default:a", first.RESTConfig().Impersonate.UserName)
+
+	second, err := cache.Get("system:serviceaccount:default:a")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(impersonationCacheHits))
+	assert.Equal(t, misses+1, testutil.ToFloat64(impersonationCacheMisses))
+}
+
+func TestImpersonationCacheEviction(t *testing.T) {
+	cache := NewImpersonationCache(newTestSet(t), 2)
+	evictions := testutil.ToFloat64(impersonationCacheEvictions.WithLabelValues("size"))
+
+	a, err := cache.Get("a")
+	require.NoError(t, err)
+	_, err = cache.Get("b")
+	require.NoError(t, err)
+	// Use "a" so that "b" becomes the least recently used entry.
+	_, err = cache.Get("a")
+	require.NoError(t, err)
+	_, err = cache.Get("c")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, evictions+1, testutil.ToFloat64(impersonationCacheEvictions.WithLabelValues("size")))
+
+	cachedA, err := cache.Get("a")
+	require.NoError(t, err)
+	assert.Same(t, a, cachedA)
+}
+
+func TestImpersonationCacheInvalidate(t *testing.T) {
+	cache := NewImpersonationCache(newTestSet(t), 0)
+
+	for _, user := range []string{
+		"system:serviceaccount:ns1:deployer",
+		"system:serviceaccount:ns2:deployer",
+		"system:serviceaccount:ns1:other",
+	} {
+		_, err := cache.Get(user)
+		require.NoError(t, err)
+	}
+
+	removed := cache.Invalidate(func(user string) bool {
+		return strings.HasSuffix(user, ":deployer")
+	})
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, 1, cache.Len())
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		impersonationCacheHits,
+		impersonationCacheMisses,
+		impersonationCacheEvictions,
+		impersonationCacheSize,
+		resourceCacheReads,
+		resourceCacheInformers,
+	)
+}
+
+var (
+	impersonationCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "client_impersonation_cache_hits_total",
+			Help: "Total number of impersonated client lookups served from the cache",
+		},
+	)
+	impersonationCacheMisses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "client_impersonation_cache_misses_total",
+			Help: "Total number of impersonated client lookups that required building a new client",
+		},
+	)
+	impersonationCacheEvictions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "client_impersonation_cache_evictions_total",
+			Help: "Total number of impersonated clients removed from the cache by reason",
+		},
+		[]string{"reason"},
+	)
+	impersonationCacheSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "client_impersonation_cache_size",
+			Help: "Current number of impersonated clients in the cache",
+		},
+	)
+	resourceCacheReads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "client_resource_cache_reads_total",
+			Help: "Total number of resource reads from the cache per GVR and result",
+		},
+		[]string{"gvr", "result"},
+	)
+	resourceCacheInformers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "client_resource_cache_informers",
+			Help: "Current number of informers run by the resource cache",
+		},
+	)
+)
@@ -0,0 +1,177 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// ResourceCache caches the resources created by kro for the instances, i.e
+// the objects labeled with metadata.InstanceIDLabel, so that reconciles read
+// their observed state from memory instead of the API server. It is shared by
+// all the instance controllers.
+//
+// The informers of a GVR are started the first time it is read, until they
+// synced the cache is cold and Get doesn't answer. Get doesn't answer either
+// for the objects that aren't cached, e.g objects about to be adopted, or
+// created since the last event, so callers must fall back to a live read.
+// Informers run until the cache stops, the GVRs of the resources usually
+// outlive the ResourceGraphDefinitions, and are shared by several of them.
+//
+// ResourceCache is safe for concurrent use.
+type ResourceCache struct {
+	log    logr.Logger
+	client dynamic.Interface
+	// namespaces are the namespaces the namespaced resources are cached in,
+	// nil caches them in all the namespaces.
+	namespaces []string
+
+	// ctx is the context the informers run with, set by Start.
+	ctx     context.Context
+	started chan struct{}
+
+	mu sync.Mutex
+	// informers holds the informer of each GVR and namespace, or of
+	// metav1.NamespaceAll when the GVR is cached in all the namespaces.
+	informers map[schema.GroupVersionResource]map[string]cache.SharedIndexInformer
+}
+
+// NewResourceCache returns a new ResourceCache reading the resources with the
+// given client. Namespaced resources are only cached in the given namespaces,
+// or in all the namespaces if nil.
+func NewResourceCache(log logr.Logger, client dynamic.Interface, namespaces []string) *ResourceCache {
+	return &ResourceCache{
+		log:        log.WithName("resource-cache"),
+		client:     client,
+		namespaces: namespaces,
+		started:    make(chan struct{}),
+		informers:  make(map[schema.GroupVersionResource]map[string]cache.SharedIndexInformer),
+	}
+}
+
+// Start runs the informers until the context is canceled. It implements
+// manager.Runnable.
+func (c *ResourceCache) Start(ctx context.Context) error {
+	c.ctx = ctx
+	close(c.started)
+	<-ctx.Done()
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The cache is
+// available on all the replicas, informers are only started by the replicas
+// reconciling instances.
+func (c *ResourceCache) NeedLeaderElection() bool {
+	return false
+}
+
+// Get returns a copy of the cached object, and true, or false if the cache
+// can't answer: the cache isn't running, the informers of the GVR haven't
+// synced yet, the namespace isn't cached, or the object isn't cached. Pass an
+// empty namespace for cluster scoped resources.
+func (c *ResourceCache) Get(gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool) {
+	select {
+	case <-c.started:
+	default:
+		return nil, false
+	}
+
+	informerNamespace := metav1.NamespaceAll
+	if namespace != "" && c.namespaces != nil {
+		if !slices.Contains(c.namespaces, namespace) {
+			resourceCacheReads.WithLabelValues(gvr.String(), "uncached").Inc()
+			return nil, false
+		}
+		informerNamespace = namespace
+	}
+
+	informer := c.informer(gvr, informerNamespace)
+	if !informer.HasSynced() {
+		resourceCacheReads.WithLabelValues(gvr.String(), "cold").Inc()
+		return nil, false
+	}
+
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	obj, exists, err := informer.GetIndexer().GetByKey(key)
+	if err != nil || !exists {
+		resourceCacheReads.WithLabelValues(gvr.String(), "miss").Inc()
+		return nil, false
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		resourceCacheReads.WithLabelValues(gvr.String(), "miss").Inc()
+		return nil, false
+	}
+	resourceCacheReads.WithLabelValues(gvr.String(), "hit").Inc()
+	return u.DeepCopy(), true
+}
+
+// informer returns the informer of a GVR in a namespace, starting it if
+// needed.
+func (c *ResourceCache) informer(gvr schema.GroupVersionResource, namespace string) cache.SharedIndexInformer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	informers, ok := c.informers[gvr]
+	if !ok {
+		informers = make(map[string]cache.SharedIndexInformer)
+		c.informers[gvr] = informers
+	}
+	if informer, ok := informers[namespace]; ok {
+		return informer
+	}
+
+	c.log.V(1).Info("Starting resource informer", "gvr", gvr, "namespace", namespace)
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.client, 0, namespace,
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = metadata.InstanceIDLabel
+		},
+	)
+	informer := factory.ForResource(gvr).Informer()
+	// The cached objects are compared with the desired state, only the
+	// managed fields, which are never desired, are stripped.
+	_ = informer.SetTransform(stripManagedFields)
+	factory.Start(c.ctx.Done())
+	informers[namespace] = informer
+	resourceCacheInformers.Inc()
+	return informer
+}
+
+// stripManagedFields removes the managed fields of the objects before they
+// are cached.
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	// Tombstones of deleted objects are passed through.
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return obj, nil
+	}
+	accessor.SetManagedFields(nil)
+	return obj, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+func newConfigMap(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kro", Operation: metav1.ManagedFieldsOperationUpdate}})
+	return obj
+}
+
+func TestResourceCacheGet(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	owned := map[string]string{metadata.InstanceIDLabel: "6f0f64a5"}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ConfigMapList"},
+		newConfigMap("team-a", "owned", owned),
+		newConfigMap("team-a", "unowned", nil),
+		newConfigMap("team-b", "owned", owned),
+	)
+	cache := NewResourceCache(logr.Discard(), client, []string{"team-a"})
+
+	// The cache doesn't answer until it runs.
+	_, ok := cache.Get(gvr, "team-a", "owned")
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Start(ctx)
+	}()
+
+	hits := testutil.ToFloat64(resourceCacheReads.WithLabelValues(gvr.String(), "hit"))
+	var obj *unstructured.Unstructured
+	require.Eventually(t, func() bool {
+		obj, ok = cache.Get(gvr, "team-a", "owned")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "owned", obj.GetName())
+	assert.Empty(t, obj.GetManagedFields(), "the managed fields are stripped")
+	assert.Equal(t, hits+1, testutil.ToFloat64(resourceCacheReads.WithLabelValues(gvr.String(), "hit")))
+
+	// Callers get copies of the cached objects.
+	obj.SetLabels(nil)
+	obj, ok = cache.Get(gvr, "team-a", "owned")
+	require.True(t, ok)
+	assert.Equal(t, owned, obj.GetLabels())
+
+	// Objects not created by kro, and namespaces that aren't watched, are
+	// read live.
+	_, ok = cache.Get(gvr, "team-a", "unowned")
+	assert.False(t, ok)
+	_, ok = cache.Get(gvr, "team-b", "owned")
+	assert.False(t, ok)
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrServiceAccountRequestsDisabled is returned when instances request a
+// service account, but no admission policy protects the requester annotation.
+var ErrServiceAccountRequestsDisabled = errors.New("service account requests are disabled")
+
+// ServiceAccountAuthorizer authorizes the service accounts requested by
+// instances. The user requesting a service account is read from an instance
+// annotation, which any instance author can write. So requests are only
+// authorized when a ValidatingAdmissionPolicy, bound with the Deny action,
+// matches the instances and ensures that the annotation names the user that
+// set it.
+//
+// Decisions are cached for a short period of time, like the results of
+// ReviewAccess. ServiceAccountAuthorizer is safe for concurrent use.
+type ServiceAccountAuthorizer struct {
+	kubernetes kubernetes.Interface
+	// policyName is the name of the ValidatingAdmissionPolicy protecting the
+	// requester annotation. Requests are refused when it is empty.
+	policyName string
+
+	mu sync.Mutex
+	// decisions caches the policy checks, keyed by API group, and the
+	// impersonation reviews, keyed by user, namespace and service account.
+	decisions map[string]authorizationDecision
+}
+
+type authorizationDecision struct {
+	// denied is the reason of the denial, nil when allowed.
+	denied  error
+	expires time.Time
+}
+
+// NewServiceAccountAuthorizer returns a ServiceAccountAuthorizer relying on
+// the ValidatingAdmissionPolicy with the given name. An empty policyName
+// refuses all the service account requests.
+func NewServiceAccountAuthorizer(kubernetes kubernetes.Interface, policyName string) *ServiceAccountAuthorizer {
+	return &ServiceAccountAuthorizer{
+		kubernetes: kubernetes,
+		policyName: policyName,
+		decisions:  make(map[string]authorizationDecision),
+	}
+}
+
+// Authorize returns nil if the instances of the given API group are protected
+// by the admission policy, and the requester can impersonate the service
+// account in the given namespace.
+func (a *ServiceAccountAuthorizer) Authorize(ctx context.Context, group, requester, namespace, serviceAccount string) error {
+	if a == nil || a.policyName == "" {
+		return fmt.Errorf("%w: no admission policy protects the service account requester", ErrServiceAccountRequestsDisabled)
+	}
+	if requester == "" {
+		return fmt.Errorf("the requester of service account %s/%s is unknown", namespace, serviceAccount)
+	}
+
+	if err := a.decide(ctx, "policy/"+group, func(ctx context.Context) (authorizationDecision, error) {
+		return a.checkPolicy(ctx, group)
+	}); err != nil {
+		return err
+	}
+	key := fmt.Sprintf("review/%s/%s/%s", requester, namespace, serviceAccount)
+	return a.decide(ctx, key, func(ctx context.Context) (authorizationDecision, error) {
+		return a.reviewImpersonation(ctx, requester, namespace, serviceAccount)
+	})
+}
+
+// decide returns the reason of the cached denial, or makes and caches the
+// decision. Nothing is cached when the decision can't be made, e.g the API
+// server is unreachable.
+func (a *ServiceAccountAuthorizer) decide(
+	ctx context.Context,
+	key string,
+	decision func(context.Context) (authorizationDecision, error),
+) error {
+	now := time.Now()
+	a.mu.Lock()
+	cached, ok := a.decisions[key]
+	a.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.denied
+	}
+
+	decided, err := decision(ctx)
+	if err != nil {
+		return err
+	}
+	decided.expires = now.Add(accessReviewTTL)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for k, d := range a.decisions {
+		if now.After(d.expires) {
+			delete(a.decisions, k)
+		}
+	}
+	a.decisions[key] = decided
+	return decided.denied
+}
+
+// checkPolicy checks that the admission policy matches the instances of the
+// API group, and is bound with the Deny action.
+func (a *ServiceAccountAuthorizer) checkPolicy(ctx context.Context, group string) (authorizationDecision, error) {
+	admission := a.kubernetes.AdmissionregistrationV1()
+	policy, err := admission.ValidatingAdmissionPolicies().Get(ctx, a.policyName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return authorizationDecision{
+			denied: fmt.Errorf("%w: admission policy %s is not installed", ErrServiceAccountRequestsDisabled, a.policyName),
+		}, nil
+	}
+	if err != nil {
+		return authorizationDecision{}, fmt.Errorf("failed to get admission policy %s: %w", a.policyName, err)
+	}
+	if policy.Spec.FailurePolicy != nil && *policy.Spec.FailurePolicy != admissionregistrationv1.Fail {
+		return authorizationDecision{
+			denied: fmt.Errorf("%w: admission policy %s must fail closed", ErrServiceAccountRequestsDisabled, a.policyName),
+		}, nil
+	}
+	if !policyMatchesGroup(policy, group) {
+		return authorizationDecision{
+			denied: fmt.Errorf("%w: admission policy %s doesn't match the instances of API group %q",
+				ErrServiceAccountRequestsDisabled, a.policyName, group),
+		}, nil
+	}
+
+	bindings, err := admission.ValidatingAdmissionPolicyBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return authorizationDecision{}, fmt.Errorf("failed to list admission policy bindings: %w", err)
+	}
+	for _, binding := range bindings.Items {
+		if binding.Spec.PolicyName == a.policyName && binding.Spec.ParamRef == nil && binding.Spec.MatchResources == nil &&
+			slices.Contains(binding.Spec.ValidationActions, admissionregistrationv1.Deny) {
+			return authorizationDecision{}, nil
+		}
+	}
+	return authorizationDecision{
+		denied: fmt.Errorf("%w: admission policy %s isn't bound with the Deny action", ErrServiceAccountRequestsDisabled, a.policyName),
+	}, nil
+}
+
+// policyMatchesGroup returns true if the policy matches the creates and
+// updates of all the resources of the API group.
+func policyMatchesGroup(policy *admissionregistrationv1.ValidatingAdmissionPolicy, group string) bool {
+	constraints := policy.Spec.MatchConstraints
+	if constraints == nil {
+		return false
+	}
+	for _, rule := range constraints.ResourceRules {
+		if len(rule.ResourceNames) > 0 {
+			continue
+		}
+		if (slices.Contains(rule.APIGroups, group) || slices.Contains(rule.APIGroups, "*")) &&
+			slices.Contains(rule.APIVersions, "*") &&
+			slices.Contains(rule.Resources, "*") &&
+			(slices.Contains(rule.Operations, admissionregistrationv1.OperationAll) ||
+				slices.Contains(rule.Operations, admissionregistrationv1.Create) &&
+					slices.Contains(rule.Operations, admissionregistrationv1.Update)) {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewImpersonation checks, using a SubjectAccessReview, whether the user
+// can impersonate the service account.
+func (a *ServiceAccountAuthorizer) reviewImpersonation(ctx context.Context, user, namespace, serviceAccount string) (authorizationDecision, error) {
+	review, err := a.kubernetes.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User: user,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "impersonate",
+				Resource:  "serviceaccounts",
+				Name:      serviceAccount,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return authorizationDecision{}, fmt.Errorf("failed to review service account access: %w", err)
+	}
+	if !review.Status.Allowed {
+		return authorizationDecision{denied: fmt.Errorf("user %q is not allowed to impersonate service account %s/%s: %s",
+			user, namespace, serviceAccount, review.Status.Reason)}, nil
+	}
+	return authorizationDecision{}, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testRequesterPolicy = "kro-service-account-requester"
+
+func newRequesterPolicy(groups ...string) *admissionregistrationv1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: testRequesterPolicy},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+			MatchConstraints: &admissionregistrationv1.MatchResources{
+				ResourceRules: []admissionregistrationv1.NamedRuleWithOperations{{
+					RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   groups,
+							APIVersions: []string{"*"},
+							Resources:   []string{"*"},
+						},
+					},
+				}},
+			},
+		},
+	}
+}
+
+func newRequesterPolicyBinding(actions ...admissionregistrationv1.ValidationAction) *admissionregistrationv1.ValidatingAdmissionPolicyBinding {
+	return &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: testRequesterPolicy},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        testRequesterPolicy,
+			ValidationActions: actions,
+		},
+	}
+}
+
+func TestServiceAccountAuthorizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		policyName string
+		objects    []k8sruntime.Object
+		requester  string
+		wantErr    string
+		disabled   bool
+	}{
+		{
+			name:      "requests are refused without a policy name",
+			requester: "alice",
+			wantErr:   "no admission policy protects the service account requester",
+			disabled:  true,
+		},
+		{
+			name:       "requests are refused when the policy isn't installed",
+			policyName: testRequesterPolicy,
+			requester:  "alice",
+			wantErr:    "is not installed",
+			disabled:   true,
+		},
+		{
+			name:       "requests are refused when the policy isn't bound",
+			policyName: testRequesterPolicy,
+			objects:    []k8sruntime.Object{newRequesterPolicy("kro.run")},
+			requester:  "alice",
+			wantErr:    "isn't bound with the Deny action",
+			disabled:   true,
+		},
+		{
+			name:       "requests are refused when the policy only warns",
+			policyName: testRequesterPolicy,
+			objects: []k8sruntime.Object{
+				newRequesterPolicy("kro.run"),
+				newRequesterPolicyBinding(admissionregistrationv1.Warn),
+			},
+			requester: "alice",
+			wantErr:   "isn't bound with the Deny action",
+			disabled:  true,
+		},
+		{
+			name:       "requests are refused when the policy doesn't match the instances",
+			policyName: testRequesterPolicy,
+			objects: []k8sruntime.Object{
+				newRequesterPolicy("example.com"),
+				newRequesterPolicyBinding(admissionregistrationv1.Deny),
+			},
+			requester: "alice",
+			wantErr:   `doesn't match the instances of API group "kro.run"`,
+			disabled:  true,
+		},
+		{
+			name:       "requests without requester are refused",
+			policyName: testRequesterPolicy,
+			objects: []k8sruntime.Object{
+				newRequesterPolicy("kro.run"),
+				newRequesterPolicyBinding(admissionregistrationv1.Deny),
+			},
+			wantErr: "the requester of service account default/deployer is unknown",
+		},
+		{
+			name:       "requesters that can't impersonate the service account are refused",
+			policyName: testRequesterPolicy,
+			objects: []k8sruntime.Object{
+				newRequesterPolicy("kro.run"),
+				newRequesterPolicyBinding(admissionregistrationv1.Deny),
+			},
+			requester: "mallory",
+			wantErr:   `user "mallory" is not allowed to impersonate service account default/deployer`,
+		},
+		{
+			name:       "requesters that can impersonate the service account are allowed",
+			policyName: testRequesterPolicy,
+			objects: []k8sruntime.Object{
+				newRequesterPolicy("*"),
+				newRequesterPolicyBinding(admissionregistrationv1.Deny, admissionregistrationv1.Audit),
+			},
+			requester: "alice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tt.objects...)
+			reviews := 0
+			clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+				reviews++
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				review.Status.Allowed = review.Spec.User == "alice"
+				return true, review, nil
+			})
+			authorizer := NewServiceAccountAuthorizer(clientset, tt.policyName)
+
+			// The second attempt is served from the cache.
+			for range 2 {
+				err := authorizer.Authorize(context.Background(), "kro.run", tt.requester, "default", "deployer")
+				if tt.wantErr == "" {
+					require.NoError(t, err)
+				} else {
+					assert.ErrorContains(t, err, tt.wantErr)
+					assert.Equal(t, tt.disabled, errors.Is(err, ErrServiceAccountRequestsDisabled))
+				}
+			}
+			assert.LessOrEqual(t, reviews, 1)
+		})
+	}
+}
+
+func TestServiceAccountAuthorizerNil(t *testing.T) {
+	var authorizer *ServiceAccountAuthorizer
+	err := authorizer.Authorize(context.Background(), "kro.run", "alice", "default", "deployer")
+	assert.ErrorIs(t, err, ErrServiceAccountRequestsDisabled)
+}
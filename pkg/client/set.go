@@ -29,6 +29,8 @@ type Set struct {
 	kubernetes      *kubernetes.Clientset
 	dynamic         *dynamic.DynamicClient
 	apiExtensionsV1 *apiextensionsv1.ApiextensionsV1Client
+	// accessReviews caches the access reviews made for the identity of the Set.
+	accessReviews *accessReviewCache
 }
 
 // Config holds configuration for client creation
@@ -67,7 +69,7 @@ func NewSet(cfg Config) (*Set, error) {
 	}
 	config.UserAgent = fmt.Sprintf("kro/%s", version.GetVersionInfo().GitVersion)
 
-	c := &Set{config: config}
+	c := &Set{config: config, accessReviews: newAccessReviewCache()}
 	if err := c.init(); err != nil {
 		return nil, err
 	}
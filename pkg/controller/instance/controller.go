@@ -15,22 +15,29 @@ package instance
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/kro-run/kro/api/v1alpha1"
 	kroclient "github.com/kro-run/kro/pkg/client"
 	"github.com/kro-run/kro/pkg/graph"
 	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/tracing"
 )
 
 // ReconcileConfig holds configuration parameters for the reconciliation process.
@@ -39,10 +46,16 @@ type ReconcileConfig struct {
 	// DefaultRequeueDuration is the default duration to wait before requeueing a
 	// a reconciliation if no specific requeue time is set.
 	DefaultRequeueDuration time.Duration
-	// DeletionGraceTimeDuration is the duration to wait after initializing a resource
-	// deletion before considering it failed
-	// Not implemented.
-	DeletionGraceTimeDuration time.Duration
+	// MaxRequeueDuration caps the exponential backoff of instances degraded by
+	// a readiness or deletion timeout.
+	MaxRequeueDuration time.Duration
+	// Paused pauses the reconciliation of all the instances, see
+	// metadata.ReconcileAnnotation to pause a single instance.
+	Paused bool
+	// PausedRequeueDuration is the interval at which the status of paused
+	// instances is refreshed. It bounds how long instances take to resume
+	// after their ResourceGraphDefinition is resumed.
+	PausedRequeueDuration time.Duration
 	// DeletionPolicy is the deletion policy to use when deleting resources in the graph
 	// TODO(a-hilaly): need to define think the different deletion policies we need to
 	// support.
@@ -76,6 +89,12 @@ type Controller struct {
 	gvr schema.GroupVersionResource
 	// client holds the dynamic client to use for interacting with the Kubernetes API.
 	clientSet *kroclient.Set
+	// impersonationCache holds the impersonated clients, it is shared by all the
+	// instance controllers.
+	impersonationCache *kroclient.ImpersonationCache
+	// resourceCache caches the resources of the instances, it is shared by all
+	// the instance controllers. Resources are read live when it is nil.
+	resourceCache *kroclient.ResourceCache
 	// rgd is a read-only reference to the Graph that the controller is
 	// managing instances for.
 	// TODO: use a read-only interface for the ResourceGraphDefinition
@@ -88,6 +107,23 @@ type Controller struct {
 	reconcileConfig ReconcileConfig
 	// defaultServiceAccounts is a map of service accounts to use for controller impersonation.
 	defaultServiceAccounts map[string]string
+	// serviceAccountPolicy defines the service accounts instances are allowed
+	// to request for controller impersonation.
+	serviceAccountPolicy *v1alpha1.ServiceAccountPolicy
+	// serviceAccountAuthorizer authorizes the requesters of service accounts,
+	// it is shared by all the instance controllers. Requests are refused when
+	// it is nil.
+	serviceAccountAuthorizer *kroclient.ServiceAccountAuthorizer
+	// recorder is used to record Kubernetes events on instances.
+	recorder record.EventRecorder
+	// readiness tracks since when the instance resources have been waiting
+	// for readiness.
+	readiness *readinessTracker
+	// paused tracks the paused instances, for metrics.
+	paused *pausedInstances
+	// revisions are the revisions instances are reconciled with, see
+	// SetRevisions.
+	revisions Revisions
 }
 
 // NewController creates a new Controller instance.
@@ -97,70 +133,178 @@ func NewController(
 	gvr schema.GroupVersionResource,
 	rgd *graph.Graph,
 	clientSet *kroclient.Set,
+	impersonationCache *kroclient.ImpersonationCache,
+	resourceCache *kroclient.ResourceCache,
 	defaultServiceAccounts map[string]string,
+	serviceAccountPolicy *v1alpha1.ServiceAccountPolicy,
+	serviceAccountAuthorizer *kroclient.ServiceAccountAuthorizer,
 	instanceLabeler metadata.Labeler,
+	recorder record.EventRecorder,
 ) *Controller {
+	if impersonationCache == nil {
+		impersonationCache = kroclient.NewImpersonationCache(clientSet, kroclient.DefaultImpersonationCacheSize)
+	}
 	return &Controller{
-		log:                    log,
-		gvr:                    gvr,
-		clientSet:              clientSet,
-		rgd:                    rgd,
-		instanceLabeler:        instanceLabeler,
-		reconcileConfig:        reconcileConfig,
-		defaultServiceAccounts: defaultServiceAccounts,
+		log:                      log,
+		gvr:                      gvr,
+		clientSet:                clientSet,
+		impersonationCache:       impersonationCache,
+		resourceCache:            resourceCache,
+		rgd:                      rgd,
+		instanceLabeler:          instanceLabeler,
+		reconcileConfig:          reconcileConfig,
+		defaultServiceAccounts:   defaultServiceAccounts,
+		serviceAccountPolicy:     serviceAccountPolicy,
+		serviceAccountAuthorizer: serviceAccountAuthorizer,
+		recorder:                 recorder,
+		readiness:                newReadinessTracker(),
+		paused:                   newPausedInstances(gvr),
 	}
 }
 
 // Reconcile is a handler function that reconciles the instance and its sub-resources.
-func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) error {
+func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (err error) {
 	namespace, name := getNamespaceName(req)
 
+	ctx, span := tracing.Start(ctx, "instance.Reconcile",
+		attribute.String("kro.instance.gvr", c.gvr.String()),
+		attribute.String("kro.instance.namespace", namespace),
+		attribute.String("kro.instance.name", name),
+	)
+	defer tracing.End(span, &err)
+
 	log := c.log.WithValues("namespace", namespace, "name", name)
 
-	instance, err := c.clientSet.Dynamic().Resource(c.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	instanceClient := tracing.WrapResourceInterface(c.clientSet.Dynamic().Resource(c.gvr).Namespace(namespace), c.gvr, namespace)
+	instance, err := instanceClient.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("Instance not found, it may have been deleted")
+			c.paused.set(types.NamespacedName{Namespace: namespace, Name: name}, false)
 			return nil
 		}
 		log.Error(err, "Failed to get instance")
 		return nil
 	}
 
+	instanceGraphReconciler, err := c.newInstanceGraphReconciler(ctx, log, instance)
+	if err != nil {
+		return err
+	}
+	return instanceGraphReconciler.reconcile(ctx)
+}
+
+// Stalled marks the instance with the Stalled condition and the last error,
+// after its reconciliation failed too many times in a row. The dynamic
+// controller keeps retrying it with a long backoff, and the condition is
+// cleared once it reconciles successfully. It returns a NotFound error when
+// the instance is gone, so that it isn't retried.
+func (c *Controller) Stalled(ctx context.Context, req ctrl.Request, reconcileErr error) error {
+	namespace, name := getNamespaceName(req)
+
+	instanceClient := c.clientSet.Dynamic().Resource(c.gvr).Namespace(namespace)
+	instance, err := instanceClient.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	stalled := createCondition(
+		v1alpha1.InstanceConditionTypeStalled,
+		corev1.ConditionTrue,
+		"MaxRetriesExceeded",
+		reconcileErr.Error(),
+		instance.GetGeneration(),
+	)
+	existing, _, _ := unstructured.NestedSlice(instance.Object, "status", "conditions")
+	conditions := make([]interface{}, 0, len(existing)+1)
+	for _, condition := range existing {
+		if cond, ok := condition.(map[string]interface{}); ok && cond["type"] == string(v1alpha1.InstanceConditionTypeStalled) {
+			continue
+		}
+		conditions = append(conditions, condition)
+	}
+	conditions = append(conditions, stalled)
+	if err := unstructured.SetNestedSlice(instance.Object, conditions, "status", "conditions"); err != nil {
+		return err
+	}
+
+	if _, err := instanceClient.UpdateStatus(ctx, instance, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update instance status: %w", err)
+	}
+	recordEvent(c.recorder, instance, corev1.EventTypeWarning, EventReasonStalled,
+		"Reconciliation stalled after repeated failures, retrying with a long backoff: %v", reconcileErr)
+	return nil
+}
+
+// newInstanceGraphReconciler creates the reconciler of an instance, with a
+// fresh runtime and state.
+func (c *Controller) newInstanceGraphReconciler(
+	ctx context.Context,
+	log logr.Logger,
+	instance *unstructured.Unstructured,
+) (*instanceGraphReconciler, error) {
 	// This is one of the main reasons why we're splitting the controller into
 	// two parts. The instantiator is responsible for creating a new runtime
 	// instance of the resource graph definition. The instance graph reconciler is responsible
 	// for reconciling the instance and its sub-resources, while keeping the same
 	// runtime object in it's fields.
-	rgRuntime, err := c.rgd.NewGraphRuntime(instance)
+	rgd, revision, waitingReason := c.resolveRevision(ctx, instance)
+
+	_, runtimeSpan := tracing.Start(ctx, "instance.NewGraphRuntime")
+	rgRuntime, err := rgd.NewGraphRuntime(instance)
+	tracing.End(runtimeSpan, &err)
 	if err != nil {
-		return fmt.Errorf("failed to create runtime resource graph definition: %w", err)
+		recordEvent(c.recorder, instance, corev1.EventTypeWarning, EventReasonEvaluationFailed,
+			"Failed to evaluate resource graph expressions: %v", err)
+		return nil, fmt.Errorf("failed to create runtime resource graph definition: %w", err)
 	}
 
 	instanceSubResourcesLabeler, err := metadata.NewInstanceLabeler(instance).Merge(c.instanceLabeler)
 	if err != nil {
-		return fmt.Errorf("failed to create instance sub-resources labeler: %w", err)
+		return nil, fmt.Errorf("failed to create instance sub-resources labeler: %w", err)
+	}
+	if revision != 0 {
+		instanceSubResourcesLabeler, err = instanceSubResourcesLabeler.Merge(metadata.GenericLabeler{
+			metadata.ResourceGraphDefinitionVersionLabel: strconv.FormatInt(revision, 10),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create instance sub-resources labeler: %w", err)
+		}
 	}
 
 	// If possible, use a service account to create the execution client
-	// TODO(a-hilaly): client caching
-	executionClient, err := c.getExecutionClient(namespace)
+	executionClient, err := c.getExecutionClient(ctx, instance)
 	if err != nil {
-		return fmt.Errorf("failed to create execution client: %w", err)
+		recordEvent(c.recorder, instance, corev1.EventTypeWarning, EventReasonImpersonationFailed,
+			"Failed to create execution client: %v", err)
+		return nil, fmt.Errorf("failed to create execution client: %w", err)
 	}
 
-	instanceGraphReconciler := &instanceGraphReconciler{
+	// The cache is filled with kro's identity, reading from it would bypass
+	// the permissions of the impersonated identities.
+	var resourceCache *kroclient.ResourceCache
+	if executionClient == c.clientSet {
+		resourceCache = c.resourceCache
+	}
+
+	return &instanceGraphReconciler{
 		log:                         log,
 		gvr:                         c.gvr,
-		client:                      executionClient,
+		client:                      executionClient.Dynamic(),
+		resourceCache:               resourceCache,
+		accessReviewer:              executionClient,
 		runtime:                     rgRuntime,
 		instanceLabeler:             c.instanceLabeler,
 		instanceSubResourcesLabeler: instanceSubResourcesLabeler,
 		reconcileConfig:             c.reconcileConfig,
+		recorder:                    c.recorder,
+		readiness:                   c.readiness,
+		paused:                      c.paused,
+		revision:                    revision,
+		waitingReason:               waitingReason,
 		// Fresh instance state at each reconciliation loop.
 		state: newInstanceState(),
-	}
-	return instanceGraphReconciler.reconcile(ctx)
+	}, nil
 }
 
 // getNamespaceName extracts the namespace and name from the request.
@@ -182,17 +326,51 @@ const (
 	errorInvalidSA    errorCategory = "invalid_sa"
 	errorClientCreate errorCategory = "client_create"
 	errorPermissions  errorCategory = "permissions"
+	errorNotAllowed   errorCategory = "not_allowed"
 )
 
 // getExecutionClient determines the execution client to use for the instance.
 // If the instance is created in a namespace of which a service account is specified,
 // the execution client will be created using the service account. If no service account
 // is specified for the namespace, the default client will be used.
-func (c *Controller) getExecutionClient(namespace string) (dynamic.Interface, error) {
+//
+// Instances can also request their own service account with the
+// metadata.ServiceAccountAnnotation, which takes precedence over the default
+// service accounts. See getRequestedServiceAccount.
+//
+// Impersonated clients are served from the shared impersonation cache, so they are
+// only built once per service account.
+func (c *Controller) getExecutionClient(ctx context.Context, instance *unstructured.Unstructured) (*kroclient.Set, error) {
+	namespace := instance.GetNamespace()
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	// Check for an instance requested service account
+	if sa, ok := instance.GetAnnotations()[metadata.ServiceAccountAnnotation]; ok {
+		if err := c.authorizeRequestedServiceAccount(ctx, instance, namespace, sa); err != nil {
+			return nil, err
+		}
+		userName, err := getServiceAccountUserName(namespace, sa)
+		if err != nil {
+			c.handleImpersonateError(namespace, sa, err)
+			return nil, fmt.Errorf("invalid requested service account: %w", err)
+		}
+
+		pivotedClient, err := c.impersonationCache.Get(userName)
+		if err != nil {
+			c.handleImpersonateError(namespace, sa, err)
+			return nil, fmt.Errorf("failed to create impersonated client with requested SA: %w", err)
+		}
+
+		impersonationTotal.WithLabelValues(namespace, sa, "success").Inc()
+		return pivotedClient, nil
+	}
+
 	// if no service accounts are specified, use the default client
 	if len(c.defaultServiceAccounts) == 0 {
 		c.log.V(1).Info("no service accounts configured, using default client")
-		return c.clientSet.Dynamic(), nil
+		return c.clientSet, nil
 	}
 
 	timer := prometheus.NewTimer(impersonationDuration.WithLabelValues(namespace, ""))
@@ -206,14 +384,14 @@ func (c *Controller) getExecutionClient(namespace string) (dynamic.Interface, er
 			return nil, fmt.Errorf("invalid service account configuration: %w", err)
 		}
 
-		pivotedClient, err := c.clientSet.WithImpersonation(userName)
+		pivotedClient, err := c.impersonationCache.Get(userName)
 		if err != nil {
 			c.handleImpersonateError(namespace, sa, err)
 			return nil, fmt.Errorf("failed to create impersonated client: %w", err)
 		}
 
 		impersonationTotal.WithLabelValues(namespace, sa, "success").Inc()
-		return pivotedClient.Dynamic(), nil
+		return pivotedClient, nil
 	}
 
 	// Check for default service account (marked by "*")
@@ -224,19 +402,56 @@ func (c *Controller) getExecutionClient(namespace string) (dynamic.Interface, er
 			return nil, fmt.Errorf("invalid default service account configuration: %w", err)
 		}
 
-		pivotedClient, err := c.clientSet.WithImpersonation(userName)
+		pivotedClient, err := c.impersonationCache.Get(userName)
 		if err != nil {
 			c.handleImpersonateError(namespace, defaultSA, err)
 			return nil, fmt.Errorf("failed to create impersonated client with default SA: %w", err)
 		}
 
 		impersonationTotal.WithLabelValues(namespace, defaultSA, "success").Inc()
-		return pivotedClient.Dynamic(), nil
+		return pivotedClient, nil
 	}
 
 	impersonationTotal.WithLabelValues(namespace, "", "default").Inc()
 	// Fallback to the default client
-	return c.clientSet.Dynamic(), nil
+	return c.clientSet, nil
+}
+
+// authorizeRequestedServiceAccount verifies that an instance is allowed to
+// use the service account it requested. The service account must be allowed
+// by the ResourceGraphDefinition service account policy, and the user named in
+// the metadata.ServiceAccountRequesterAnnotation must be allowed to impersonate
+// it.
+//
+// Anyone creating an instance can write the requester annotation, so requests
+// are refused unless the admission policy ensuring that it names the
+// requesting user is installed, see kroclient.ServiceAccountAuthorizer.
+func (c *Controller) authorizeRequestedServiceAccount(
+	ctx context.Context,
+	instance *unstructured.Unstructured,
+	namespace, sa string,
+) error {
+	if !c.serviceAccountPolicy.Allows(sa) {
+		err := fmt.Errorf("service account %q is not allowed by the resource graph definition service account policy", sa)
+		recordImpersonateError(namespace, sa, errorNotAllowed)
+		return err
+	}
+
+	requester := instance.GetAnnotations()[metadata.ServiceAccountRequesterAnnotation]
+	if requester == "" {
+		recordImpersonateError(namespace, sa, errorNotAllowed)
+		return fmt.Errorf("annotation %s is required to request a service account", metadata.ServiceAccountRequesterAnnotation)
+	}
+
+	if err := c.serviceAccountAuthorizer.Authorize(ctx, c.gvr.Group, requester, namespace, sa); err != nil {
+		if errors.Is(err, kroclient.ErrServiceAccountRequestsDisabled) {
+			recordImpersonateError(namespace, sa, errorNotAllowed)
+		} else {
+			c.handleImpersonateError(namespace, sa, err)
+		}
+		return fmt.Errorf("failed to authorize requested service account: %w", err)
+	}
+	return nil
 }
 
 // handleImpersonateError logs the error and records the error in the metrics
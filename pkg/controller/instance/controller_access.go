@@ -0,0 +1,102 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kroclient "github.com/kro-run/kro/pkg/client"
+	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/tracing"
+)
+
+// resourceVerbs are the verbs the instance controller uses on sub-resources.
+var resourceVerbs = []string{"get", "create", "update", "delete"}
+
+// accessReviewer reviews the permissions of the identity used to reconcile
+// an instance. It is implemented by kroclient.Set.
+type accessReviewer interface {
+	ReviewAccess(ctx context.Context, permissions []kroclient.Permission) ([]kroclient.Permission, error)
+}
+
+// reviewAccess checks that the execution identity holds every permission the
+// graph needs before any sub-resource is touched. This avoids failing in the
+// middle of the graph, after some resources were already created, and reports
+// all the missing permissions at once.
+//
+// A failure to run the review itself (e.g the API server is unavailable) is
+// not fatal, the reconciliation proceeds and fails on the first denied call.
+func (igr *instanceGraphReconciler) reviewAccess(ctx context.Context) (err error) {
+	if igr.accessReviewer == nil {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "instance.reviewAccess")
+	defer tracing.End(span, &err)
+
+	denied, reviewErr := igr.accessReviewer.ReviewAccess(ctx, igr.requiredPermissions())
+	igr.state.AccessReviewed = true
+	if reviewErr != nil {
+		igr.log.V(1).Info("Failed to review access, skipping pre-flight check", "error", reviewErr)
+		igr.state.AccessReviewErr = reviewErr
+		return nil
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+
+	missing := make([]string, 0, len(denied))
+	for _, p := range denied {
+		missing = append(missing, p.String())
+	}
+	igr.state.MissingPermissions = missing
+	igr.recordWarning(EventReasonMissingPermissions, "Missing permissions: %s", strings.Join(missing, ", "))
+	return fmt.Errorf("missing permissions to reconcile the instance resources: %s", strings.Join(missing, ", "))
+}
+
+// requiredPermissions returns the permissions needed to reconcile all the
+// sub-resources of the instance. The namespace of a resource that is not
+// resolved yet defaults to the namespace of the instance.
+func (igr *instanceGraphReconciler) requiredPermissions() []kroclient.Permission {
+	instanceNamespace := igr.runtime.GetInstance().GetNamespace()
+	if instanceNamespace == "" {
+		instanceNamespace = metav1.NamespaceDefault
+	}
+
+	var permissions []kroclient.Permission
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		descriptor := igr.runtime.ResourceDescriptor(resourceID)
+
+		namespace := ""
+		if descriptor.IsNamespaced() {
+			namespace = instanceNamespace
+			if resource, state := igr.runtime.GetResource(resourceID); state == runtime.ResourceStateResolved && resource.GetNamespace() != "" {
+				namespace = resource.GetNamespace()
+			}
+		}
+
+		for _, verb := range resourceVerbs {
+			permissions = append(permissions, kroclient.Permission{
+				GVR:       descriptor.GetGroupVersionResource(),
+				Namespace: namespace,
+				Verb:      verb,
+			})
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// ownership describes the relationship between an existing object and the
+// instance being reconciled.
+type ownership int
+
+const (
+	// ownedByInstance means the object was created (or adopted) by the instance.
+	ownedByInstance ownership = iota
+	// ownedByOtherInstance means the object is managed by another kro instance.
+	ownedByOtherInstance
+	// notOwned means the object isn't managed by any kro instance.
+	notOwned
+)
+
+// getOwnership returns the ownership of an existing object, based on the
+// kro.run/owned and kro.run/instance-id labels.
+func (igr *instanceGraphReconciler) getOwnership(observed *unstructured.Unstructured) ownership {
+	labels := observed.GetLabels()
+	instanceID, ok := labels[metadata.InstanceIDLabel]
+	switch {
+	case !ok || !metadata.IsKROOwned(metav1.ObjectMeta{Labels: labels}):
+		return notOwned
+	case instanceID == string(igr.runtime.GetInstance().GetUID()):
+		return ownedByInstance
+	default:
+		return ownedByOtherInstance
+	}
+}
+
+// checkOwnership verifies that the instance is allowed to manage an existing
+// object. Objects owned by another instance are never touched. Objects that
+// aren't owned by any instance are only managed if the resource adoption
+// policy allows it, in which case adopt is true and the object must be updated
+// to carry the instance labels.
+func (igr *instanceGraphReconciler) checkOwnership(resourceID string, observed *unstructured.Unstructured) (adopt bool, err error) {
+	switch igr.getOwnership(observed) {
+	case ownedByInstance:
+		return false, nil
+	case ownedByOtherInstance:
+		labels := observed.GetLabels()
+		return false, fmt.Errorf("%s %s is owned by another instance %s/%s",
+			observed.GetKind(), observed.GetName(),
+			labels[metadata.InstanceNamespaceLabel], labels[metadata.InstanceLabel])
+	}
+
+	policy := igr.runtime.ResourceDescriptor(resourceID).GetAdoptionPolicy()
+	if policy != v1alpha1.AdoptionPolicyAdopt {
+		return false, fmt.Errorf("%s %s already exists and is not owned by kro, set the resource adoptionPolicy to %s to manage it",
+			observed.GetKind(), observed.GetName(), v1alpha1.AdoptionPolicyAdopt)
+	}
+	return true, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+func TestOwnership(t *testing.T) {
+	const instanceUID = "instance-uid"
+
+	tests := []struct {
+		name      string
+		labels    map[string]string
+		adoption  v1alpha1.AdoptionPolicy
+		ownership ownership
+		adopt     bool
+		wantErr   string
+		// deleted is whether the instance deletion deletes the object.
+		deleted bool
+	}{
+		{
+			name: "owned by the instance",
+			labels: map[string]string{
+				metadata.OwnedLabel:      "true",
+				metadata.InstanceIDLabel: instanceUID,
+			},
+			ownership: ownedByInstance,
+			deleted:   true,
+		},
+		{
+			name: "owned by another instance",
+			labels: map[string]string{
+				metadata.OwnedLabel:             "true",
+				metadata.InstanceIDLabel:        "other-uid",
+				metadata.InstanceNamespaceLabel: "other",
+				metadata.InstanceLabel:          "app",
+			},
+			adoption:  v1alpha1.AdoptionPolicyAdopt,
+			ownership: ownedByOtherInstance,
+			wantErr:   "ConfigMap config is owned by another instance other/app",
+		},
+		{
+			name: "disowned by the instance",
+			labels: map[string]string{
+				metadata.OwnedLabel:      "false",
+				metadata.InstanceIDLabel: instanceUID,
+			},
+			ownership: notOwned,
+			wantErr:   "already exists and is not owned by kro",
+		},
+		{
+			name:      "instance id without owned label",
+			labels:    map[string]string{metadata.InstanceIDLabel: instanceUID},
+			ownership: notOwned,
+			wantErr:   "already exists and is not owned by kro",
+		},
+		{
+			name:      "owned label without instance id",
+			labels:    map[string]string{metadata.OwnedLabel: "true"},
+			ownership: notOwned,
+			wantErr:   "already exists and is not owned by kro",
+		},
+		{
+			name:      "not owned",
+			ownership: notOwned,
+			wantErr:   "set the resource adoptionPolicy to Adopt to manage it",
+		},
+		{
+			name:      "not owned and adopted",
+			adoption:  v1alpha1.AdoptionPolicyAdopt,
+			ownership: notOwned,
+			adopt:     true,
+		},
+		{
+			name:      "owned label without instance id and adopted",
+			labels:    map[string]string{metadata.OwnedLabel: "true"},
+			adoption:  v1alpha1.AdoptionPolicyAdopt,
+			ownership: notOwned,
+			adopt:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newFakeRuntime(newTestInstance(instanceUID))
+			rt.addResource("config", &fakeResource{
+				desired:  newTestConfigMap("config", map[string]interface{}{"key": "value"}),
+				adoption: tt.adoption,
+			})
+			observed := newTestConfigMap("config", map[string]interface{}{"key": "value"})
+			observed.SetLabels(tt.labels)
+			igr, client, _ := newTestReconciler(rt, observed.DeepCopy())
+
+			assert.Equal(t, tt.ownership, igr.getOwnership(observed))
+
+			adopt, err := igr.checkOwnership("config", observed)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.adopt, adopt)
+
+			// Deleting the instance never deletes objects it doesn't own,
+			// even adopted ones that don't carry its labels yet.
+			require.NoError(t, igr.initializeDeletionState(context.Background()))
+			err = igr.deleteResourcesInOrder(context.Background())
+			deletes := 0
+			for _, action := range client.Actions() {
+				if _, ok := action.(k8stesting.DeleteAction); ok {
+					deletes++
+				}
+			}
+			if tt.deleted {
+				assert.Error(t, err)
+				assert.Equal(t, InstanceStateDeleting, igr.state.ResourceStates["config"].State)
+				assert.Equal(t, 1, deletes)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "SKIPPED", igr.state.ResourceStates["config"].State)
+				assert.Zero(t, deletes)
+			}
+		})
+	}
+}
+
+func TestReconcileResourceOwnershipConflict(t *testing.T) {
+	rt := newFakeRuntime(newTestInstance("instance-uid"))
+	rt.addResource("config", &fakeResource{
+		desired:  newTestConfigMap("config", map[string]interface{}{"key": "desired"}),
+		adoption: v1alpha1.AdoptionPolicyAdopt,
+	})
+	observed := ownedBy(newTestConfigMap("config", map[string]interface{}{"key": "other"}), "other-uid")
+	igr, client, recorder := newTestReconciler(rt, observed)
+
+	err := igr.reconcileResource(context.Background(), "config")
+	assert.ErrorContains(t, err, "ownership conflict")
+	assert.Equal(t, "ERROR", igr.state.ResourceStates["config"].State)
+	assert.Equal(t, []string{EventReasonResourceConflict}, eventReasons(recorder))
+	// The object of the other instance is left untouched.
+	for _, action := range client.Actions() {
+		assert.Equal(t, "get", action.GetVerb())
+	}
+}
+
+func TestReconcileResourceAdoption(t *testing.T) {
+	rt := newFakeRuntime(newTestInstance("instance-uid"))
+	rt.addResource("config", &fakeResource{
+		desired:  newTestConfigMap("config", map[string]interface{}{"key": "value"}),
+		adoption: v1alpha1.AdoptionPolicyAdopt,
+	})
+	igr, client, recorder := newTestReconciler(rt, newTestConfigMap("config", map[string]interface{}{"key": "value"}))
+
+	// Adopted objects are updated to carry the instance labels, even
+	// without differences.
+	err := igr.reconcileResource(context.Background(), "config")
+	assert.ErrorContains(t, err, "resource update in progress")
+	assert.Equal(t, []string{EventReasonResourceAdopted}, eventReasons(recorder))
+
+	adopted, err := client.Resource(testConfigMapGVR).Namespace("default").Get(context.Background(), "config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ownedByInstance, igr.getOwnership(adopted))
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/requeue"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+const (
+	// PausedReasonAnnotation is the Paused condition reason of instances
+	// paused with the metadata.ReconcileAnnotation.
+	PausedReasonAnnotation = "PausedByAnnotation"
+	// PausedReasonResourceGraphDefinition is the Paused condition reason of
+	// instances paused by their ResourceGraphDefinition.
+	PausedReasonResourceGraphDefinition = "PausedByResourceGraphDefinition"
+	// PausedReasonPlan is the Paused condition reason of instances with the
+	// metadata.ReconcilePlan annotation value, see planInstance.
+	PausedReasonPlan = "PlanRequested"
+)
+
+// pausedInstances tracks the paused instances of a controller, and reports
+// their number in the instance_paused metric.
+type pausedInstances struct {
+	mu        sync.Mutex
+	gvr       schema.GroupVersionResource
+	instances map[types.NamespacedName]struct{}
+}
+
+func newPausedInstances(gvr schema.GroupVersionResource) *pausedInstances {
+	return &pausedInstances{
+		gvr:       gvr,
+		instances: make(map[types.NamespacedName]struct{}),
+	}
+}
+
+// set records whether the instance is paused.
+func (p *pausedInstances) set(instance types.NamespacedName, paused bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if paused {
+		p.instances[instance] = struct{}{}
+	} else {
+		delete(p.instances, instance)
+	}
+	pausedInstancesGauge.WithLabelValues(p.gvr.String()).Set(float64(len(p.instances)))
+}
+
+// pausedReason returns the reason the reconciliation of the instance is
+// paused, or an empty string if it isn't.
+func (igr *instanceGraphReconciler) pausedReason() string {
+	switch igr.runtime.GetInstance().GetAnnotations()[metadata.ReconcileAnnotation] {
+	case metadata.ReconcilePaused:
+		return PausedReasonAnnotation
+	case metadata.ReconcilePlan:
+		return PausedReasonPlan
+	}
+	if igr.reconcileConfig.Paused {
+		return PausedReasonResourceGraphDefinition
+	}
+	return igr.waitingReason
+}
+
+// observeInstance is the read-only reconciliation of paused instances. It
+// reads the existing resources to refresh the instance status, but never
+// creates, updates or deletes anything, including the instance finalizer.
+// Manual changes made to the resources are kept until the instance is
+// resumed.
+func (igr *instanceGraphReconciler) observeInstance(ctx context.Context) error {
+	for _, resourceID := range igr.runtime.TopologicalOrder() {
+		if want, err := igr.runtime.WantToCreateResource(resourceID); err != nil || !want {
+			igr.state.ResourceStates[resourceID] = &ResourceState{State: "SKIPPED"}
+			igr.runtime.IgnoreResource(resourceID)
+			continue
+		}
+
+		resource, state := igr.runtime.GetResource(resourceID)
+		if state != runtime.ResourceStateResolved {
+			igr.state.ResourceStates[resourceID] = &ResourceState{State: "PENDING"}
+			continue
+		}
+
+		observed, err := igr.getObservedResource(ctx, igr.getResourceClient(resourceID), resourceID, resource.GetName())
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				igr.state.ResourceStates[resourceID] = &ResourceState{State: "PENDING"}
+				continue
+			}
+			return fmt.Errorf("failed to get resource %s: %w", resourceID, err)
+		}
+		igr.runtime.SetResource(resourceID, observed)
+		igr.state.ResourceStates[resourceID] = &ResourceState{State: InstanceStatePaused}
+
+		if err := igr.synchronize(ctx); err != nil {
+			return fmt.Errorf("failed to synchronize observing resource %s: %w", resourceID, err)
+		}
+	}
+	return igr.pausedRequeue()
+}
+
+// pausedRequeue requeues paused instances to refresh their status.
+func (igr *instanceGraphReconciler) pausedRequeue() error {
+	requeueAfter := igr.reconcileConfig.PausedRequeueDuration
+	if requeueAfter == 0 {
+		requeueAfter = igr.reconcileConfig.DefaultRequeueDuration
+	}
+	return requeue.NeededAfter(fmt.Errorf("reconciliation is paused"), requeueAfter)
+}
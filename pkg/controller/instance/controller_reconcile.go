@@ -16,19 +16,26 @@ package instance
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
 
+	"github.com/kro-run/kro/api/v1alpha1"
+	kroclient "github.com/kro-run/kro/pkg/client"
 	"github.com/kro-run/kro/pkg/controller/instance/delta"
 	"github.com/kro-run/kro/pkg/metadata"
 	"github.com/kro-run/kro/pkg/requeue"
 	"github.com/kro-run/kro/pkg/runtime"
+	"github.com/kro-run/kro/pkg/tracing"
 )
 
 // instanceGraphReconciler is responsible for reconciling a single instance and
@@ -41,6 +48,10 @@ type instanceGraphReconciler struct {
 	gvr schema.GroupVersionResource
 	// client is a dynamic client for interacting with the Kubernetes API server
 	client dynamic.Interface
+	// resourceCache serves the observed state of the resources, see
+	// getObservedResource. It is nil when resources are read live, including
+	// when the instance is reconciled with an impersonated identity.
+	resourceCache *kroclient.ResourceCache
 	// runtime is the runtime representation of the ResourceGraphDefinition. It holds the
 	// information about the instance and its sub-resources, the CEL expressions
 	// their dependencies, and the resolved values... etc
@@ -55,6 +66,24 @@ type instanceGraphReconciler struct {
 	reconcileConfig ReconcileConfig
 	// state holds the current state of the instance and its sub-resources.
 	state *InstanceState
+	// recorder is used to record Kubernetes events on the instance.
+	recorder record.EventRecorder
+	// accessReviewer reviews the permissions of the execution identity before
+	// reconciling the sub-resources.
+	accessReviewer accessReviewer
+	// readiness tracks since when the resources have been waiting for
+	// readiness, across reconciliations.
+	readiness *readinessTracker
+	// paused tracks the paused instances of the controller.
+	paused *pausedInstances
+	// dryRun is true when the instance is being planned, see plan.
+	dryRun bool
+	// revision is the ResourceGraphDefinition revision the instance is
+	// reconciled with, zero when the controller doesn't track revisions.
+	revision int64
+	// waitingReason is the reason the instance waits for the graph of its
+	// revision, see Controller.resolveRevision.
+	waitingReason string
 }
 
 // reconcile performs the reconciliation of the instance and its sub-resources.
@@ -63,13 +92,29 @@ type instanceGraphReconciler struct {
 func (igr *instanceGraphReconciler) reconcile(ctx context.Context) error {
 	instance := igr.runtime.GetInstance()
 	igr.state = newInstanceState()
+	name := types.NamespacedName{Namespace: instance.GetNamespace(), Name: instance.GetName()}
 
-	// Handle instance deletion if marked for deletion
+	// Handle instance deletion if marked for deletion. Paused and planned
+	// instances are deleted too, their finalizer would block the deletion
+	// otherwise.
 	if !instance.GetDeletionTimestamp().IsZero() {
+		igr.paused.set(name, false)
 		igr.state.State = "DELETING"
 		return igr.handleReconciliation(ctx, igr.handleInstanceDeletion)
 	}
 
+	// Paused instances only get their status refreshed
+	pausedReason := igr.pausedReason()
+	igr.paused.set(name, pausedReason != "")
+	if pausedReason != "" {
+		igr.state.State = InstanceStatePaused
+		igr.state.PausedReason = pausedReason
+		if pausedReason == PausedReasonPlan {
+			return igr.handleReconciliation(ctx, igr.planInstance)
+		}
+		return igr.handleReconciliation(ctx, igr.observeInstance)
+	}
+
 	return igr.handleReconciliation(ctx, igr.reconcileInstance)
 }
 
@@ -98,9 +143,17 @@ func (igr *instanceGraphReconciler) handleReconciliation(ctx context.Context, re
 func (igr *instanceGraphReconciler) reconcileInstance(ctx context.Context) error {
 	instance := igr.runtime.GetInstance()
 
-	// Set managed state and handle instance labels
-	if err := igr.setupInstance(ctx, instance); err != nil {
-		return fmt.Errorf("failed to setup instance: %w", err)
+	// Set managed state and handle instance labels, plans leave the instance
+	// untouched.
+	if !igr.dryRun {
+		if err := igr.setupInstance(ctx, instance); err != nil {
+			return fmt.Errorf("failed to setup instance: %w", err)
+		}
+	}
+
+	// Make sure we can manage every resource before touching any of them
+	if err := igr.reviewAccess(ctx); err != nil {
+		return err
 	}
 
 	// Initialize resource states
@@ -115,7 +168,8 @@ func (igr *instanceGraphReconciler) reconcileInstance(ctx context.Context) error
 		}
 
 		// Synchronize runtime state after each resource
-		if _, err := igr.runtime.Synchronize(); err != nil {
+		if err := igr.synchronize(ctx); err != nil {
+			igr.recordWarning(EventReasonEvaluationFailed, "Failed to evaluate expressions after reconciling resource %s: %v", resourceID, err)
 			return fmt.Errorf("failed to synchronize reconciling resource %s: %w", resourceID, err)
 		}
 	}
@@ -123,6 +177,16 @@ func (igr *instanceGraphReconciler) reconcileInstance(ctx context.Context) error
 	return nil
 }
 
+// synchronize evaluates the runtime expressions that became resolvable, within
+// a dedicated span so that CEL evaluation time shows up in traces.
+func (igr *instanceGraphReconciler) synchronize(ctx context.Context) (err error) {
+	_, span := tracing.Start(ctx, "instance.Synchronize")
+	defer tracing.End(span, &err)
+
+	_, err = igr.runtime.Synchronize()
+	return err
+}
+
 // setupInstance prepares an instance for reconciliation by setting up necessary
 // labels and managed state.
 func (igr *instanceGraphReconciler) setupInstance(ctx context.Context, instance *unstructured.Unstructured) error {
@@ -137,22 +201,33 @@ func (igr *instanceGraphReconciler) setupInstance(ctx context.Context, instance
 }
 
 // reconcileResource handles the reconciliation of a single resource within the instance
-func (igr *instanceGraphReconciler) reconcileResource(ctx context.Context, resourceID string) error {
+func (igr *instanceGraphReconciler) reconcileResource(ctx context.Context, resourceID string) (err error) {
 	log := igr.log.WithValues("resourceID", resourceID)
 	resourceState := &ResourceState{State: "IN_PROGRESS"}
 	igr.state.ResourceStates[resourceID] = resourceState
 
+	ctx, span := tracing.Start(ctx, "instance.reconcileResource", attribute.String("kro.resource.id", resourceID))
+	defer func() {
+		span.SetAttributes(attribute.String("kro.resource.state", resourceState.State))
+		tracing.End(span, &err)
+	}()
+
 	// Check if resource should be created
 	if want, err := igr.runtime.WantToCreateResource(resourceID); err != nil || !want {
 		log.V(1).Info("Skipping resource creation", "reason", err)
 		resourceState.State = "SKIPPED"
 		igr.runtime.IgnoreResource(resourceID)
+		igr.planChange(resourceID, nil, PlanActionSkip, "includeWhen expressions are not satisfied", nil)
 		return nil
 	}
 
 	// Get and validate resource state
 	resource, state := igr.runtime.GetResource(resourceID)
 	if state != runtime.ResourceStateResolved {
+		if igr.dryRun {
+			igr.planChange(resourceID, nil, PlanActionUnknown, "depends on values that are only known once the changes are applied", nil)
+			return nil
+		}
 		return igr.delayedRequeue(fmt.Errorf("resource %s not resolved: state=%v", resourceID, state))
 	}
 
@@ -174,7 +249,7 @@ func (igr *instanceGraphReconciler) handleResourceReconciliation(
 	rc := igr.getResourceClient(resourceID)
 
 	// Check if resource exists
-	observed, err := rc.Get(ctx, resource.GetName(), metav1.GetOptions{})
+	observed, err := igr.getObservedResource(ctx, rc, resourceID, resource.GetName())
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return igr.handleResourceCreation(ctx, rc, resource, resourceID, resourceState)
@@ -184,19 +259,69 @@ func (igr *instanceGraphReconciler) handleResourceReconciliation(
 		return resourceState.Err
 	}
 
+	// Make sure we are allowed to manage the existing object
+	adopt, err := igr.checkOwnership(resourceID, observed)
+	if err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = fmt.Errorf("ownership conflict: %w", err)
+		igr.recordWarning(EventReasonResourceConflict, "Refusing to manage resource %s: %v", resourceID, err)
+		return resourceState.Err
+	}
+
+	// Objects being recreated are waited on until they are gone.
+	if observed.GetDeletionTimestamp() != nil &&
+		igr.runtime.ResourceDescriptor(resourceID).GetUpdatePolicy() == v1alpha1.UpdatePolicyRecreate {
+		resourceState.State = "RECREATING"
+		if igr.dryRun {
+			igr.planChange(resourceID, observed, PlanActionRecreate, "waiting for the resource deletion", nil)
+			return nil
+		}
+		return igr.delayedRequeue(fmt.Errorf("waiting for resource deletion before recreating it"))
+	}
+
 	// Update runtime with observed state
 	igr.runtime.SetResource(resourceID, observed)
 
+	// Apply the changes before waiting for readiness, so that resources that
+	// can't become ready (e.g a Deployment stuck on a bad image) can be fixed.
+	// Adopted objects are labeled right away.
+	if err := igr.updateResource(ctx, rc, resource, observed, resourceID, resourceState, adopt); err != nil {
+		return err
+	}
+
+	// Plans don't wait for readiness, the dependents use the current values
+	if igr.dryRun {
+		return nil
+	}
+
 	// Check resource readiness
-	if ready, reason, err := igr.runtime.IsResourceReady(resourceID); err != nil || !ready {
+	_, readySpan := tracing.Start(ctx, "instance.IsResourceReady", attribute.String("kro.resource.id", resourceID))
+	ready, reason, err := igr.runtime.IsResourceReady(resourceID)
+	readySpan.SetAttributes(attribute.Bool("kro.resource.ready", ready))
+	tracing.End(readySpan, &err)
+	if err != nil {
+		igr.recordWarning(EventReasonEvaluationFailed, "Failed to evaluate readiness of resource %s: %v", resourceID, err)
+	}
+	if err != nil || !ready {
 		log.V(1).Info("Resource not ready", "reason", reason, "error", err)
 		resourceState.State = "WAITING_FOR_READINESS"
 		resourceState.Err = fmt.Errorf("resource not ready: %s: %w", reason, err)
-		return igr.delayedRequeue(resourceState.Err)
+		// The instance is requeued until the resource is ready, only the
+		// transition to waiting is recorded.
+		since, waiting := igr.readiness.waitingSince(igr.runtime.GetInstance().GetUID(), resourceID, time.Now())
+		if err == nil {
+			if !waiting {
+				igr.recordEvent(EventReasonWaitingForReadiness, "Waiting for resource %s to become ready: %s", resourceID, reason)
+			}
+		} else {
+			reason = err.Error()
+		}
+		return igr.waitForReadiness(resourceID, reason, since, resourceState)
 	}
 
+	igr.readiness.reset(igr.runtime.GetInstance().GetUID(), resourceID)
 	resourceState.State = "SYNCED"
-	return igr.updateResource(ctx, rc, resource, observed, resourceID, resourceState)
+	return nil
 }
 
 // getResourceClient returns the appropriate dynamic client and namespace for a resource
@@ -206,9 +331,43 @@ func (igr *instanceGraphReconciler) getResourceClient(resourceID string) dynamic
 	namespace := igr.getResourceNamespace(resourceID)
 
 	if descriptor.IsNamespaced() {
-		return igr.client.Resource(gvr).Namespace(namespace)
+		return tracing.WrapResourceInterface(igr.client.Resource(gvr).Namespace(namespace), gvr, namespace)
+	}
+	return tracing.WrapResourceInterface(igr.client.Resource(gvr), gvr, "")
+}
+
+// getObservedResource returns the observed state of a resource, from the
+// shared resource cache when it can answer, or from the API server when the
+// cache is cold or doesn't hold the object, so that objects missing from the
+// cache are never created twice, nor considered deleted. The cache holds the
+// objects of all the instances, so the objects of other instances are read
+// from the API server too.
+//
+// Cached objects may lag behind the API server, updates made from a stale
+// object are rejected with a conflict and retried, see updateResource.
+func (igr *instanceGraphReconciler) getObservedResource(
+	ctx context.Context,
+	rc dynamic.ResourceInterface,
+	resourceID, name string,
+) (*unstructured.Unstructured, error) {
+	if igr.resourceCache != nil {
+		descriptor := igr.runtime.ResourceDescriptor(resourceID)
+		namespace := ""
+		if descriptor.IsNamespaced() {
+			namespace = igr.getResourceNamespace(resourceID)
+		}
+		observed, ok := igr.resourceCache.Get(descriptor.GetGroupVersionResource(), namespace, name)
+		if ok && observed.GetLabels()[metadata.InstanceIDLabel] == string(igr.runtime.GetInstance().GetUID()) {
+			return observed, nil
+		}
 	}
-	return igr.client.Resource(gvr)
+	return rc.Get(ctx, name, metav1.GetOptions{})
+}
+
+// getInstanceClient returns the dynamic client used to interact with the
+// instance itself.
+func (igr *instanceGraphReconciler) getInstanceClient(namespace string) dynamic.ResourceInterface {
+	return tracing.WrapResourceInterface(igr.client.Resource(igr.gvr).Namespace(namespace), igr.gvr, namespace)
 }
 
 // handleResourceCreation manages the creation of a new resource
@@ -223,29 +382,62 @@ func (igr *instanceGraphReconciler) handleResourceCreation(
 
 	// Apply labels and create resource
 	igr.instanceSubResourcesLabeler.ApplyLabels(resource)
-	if _, err := rc.Create(ctx, resource, metav1.CreateOptions{}); err != nil {
+	created, err := rc.Create(ctx, resource, metav1.CreateOptions{DryRun: igr.dryRunOption()})
+	if err != nil {
 		resourceState.State = "ERROR"
 		resourceState.Err = fmt.Errorf("failed to create resource: %w", err)
+		igr.recordWarning(EventReasonResourceCreateFailed, "Failed to create %s %s: %v", resource.GetKind(), resource.GetName(), err)
 		return resourceState.Err
 	}
+	if igr.dryRun {
+		igr.planChange(resourceID, created, PlanActionCreate, "", nil)
+		igr.runtime.SetResource(resourceID, created)
+		return nil
+	}
 
+	igr.recordEvent(EventReasonResourceCreated, "Created %s %s (resource %s)", resource.GetKind(), resource.GetName(), resourceID)
+	igr.readiness.reset(igr.runtime.GetInstance().GetUID(), resourceID)
 	resourceState.State = "CREATED"
 	return igr.delayedRequeue(fmt.Errorf("awaiting resource creation completion"))
 }
 
 // updateResource handles updates to an existing resource, comparing the desired
-// and observed states and applying the necessary changes.
+// and observed states and applying the necessary changes. When adopting an
+// object, the update is always applied so that the object carries the instance
+// labels.
+//
+// The resource update policy decides how the changes are applied: CreateOnly
+// resources are never updated (adopted objects only get the instance labels),
+// and Recreate resources are deleted and re-created when the update is
+// rejected because of immutable fields.
 func (igr *instanceGraphReconciler) updateResource(
 	ctx context.Context,
 	rc dynamic.ResourceInterface,
 	desired, observed *unstructured.Unstructured,
 	resourceID string,
 	resourceState *ResourceState,
+	adopt bool,
 ) error {
 	igr.log.V(1).Info("Processing resource update", "resourceID", resourceID)
 
+	descriptor := igr.runtime.ResourceDescriptor(resourceID)
+	updatePolicy := descriptor.GetUpdatePolicy()
+	if updatePolicy == v1alpha1.UpdatePolicyCreateOnly {
+		if !adopt {
+			resourceState.State = "SYNCED"
+			igr.log.V(1).Info("Skipping update of create only resource", "resourceID", resourceID)
+			igr.planChange(resourceID, observed, PlanActionNone, "", nil)
+			return nil
+		}
+		desired = observed.DeepCopy()
+	}
+
 	// Compare desired and observed states
-	differences, err := delta.Compare(desired, observed)
+	compareOptions := []delta.Option{
+		delta.WithIgnoredPaths(descriptor.GetIgnoreDifferences()...),
+		delta.WithSchema(descriptor.GetSchema()),
+	}
+	differences, err := delta.Compare(desired, observed, compareOptions...)
 	if err != nil {
 		resourceState.State = "ERROR"
 		resourceState.Err = fmt.Errorf("failed to compare desired and observed states: %w", err)
@@ -253,9 +445,10 @@ func (igr *instanceGraphReconciler) updateResource(
 	}
 
 	// If no differences are found, the resource is in sync.
-	if len(differences) == 0 {
+	if len(differences) == 0 && !adopt {
 		resourceState.State = "SYNCED"
 		igr.log.V(1).Info("No deltas found for resource", "resourceID", resourceID)
+		igr.planChange(resourceID, observed, PlanActionNone, "", nil)
 		return nil
 	}
 
@@ -267,20 +460,66 @@ func (igr *instanceGraphReconciler) updateResource(
 		"resourceID", resourceID,
 		"delta", differences,
 	)
+	paths := make([]string, 0, len(differences))
+	for _, difference := range differences {
+		paths = append(paths, difference.Path)
+		if !igr.dryRun {
+			resourceUpdatePathsTotal.WithLabelValues(igr.gvr.String(), resourceID, delta.NormalizePath(difference.Path)).Inc()
+		}
+	}
+	igr.log.Info("Updating resource", "resourceID", resourceID, "paths", paths, "dryRun", igr.dryRun)
 	igr.instanceSubResourcesLabeler.ApplyLabels(desired)
 
+	// Keep the observed values of the ignored fields, otherwise the update
+	// would revert them.
+	if err := delta.PreserveIgnored(desired, observed, compareOptions...); err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = fmt.Errorf("failed to preserve ignored fields: %w", err)
+		return resourceState.Err
+	}
+
 	// Apply changes to the resource
 	// TODO: Handle annotations
 	desired.SetResourceVersion(observed.GetResourceVersion())
 	desired.SetFinalizers(observed.GetFinalizers())
-	_, err = rc.Update(ctx, desired, metav1.UpdateOptions{})
+	updated, err := rc.Update(ctx, desired, metav1.UpdateOptions{DryRun: igr.dryRunOption()})
 	if err != nil {
+		if updatePolicy == v1alpha1.UpdatePolicyRecreate && isImmutableFieldError(err) {
+			if igr.dryRun {
+				igr.planChange(resourceID, observed, PlanActionRecreate, err.Error(), differences)
+				return nil
+			}
+			return igr.recreateResource(ctx, rc, observed, resourceID, resourceState, err)
+		}
+		// The observed object was stale, e.g read from the resource cache
+		// before it caught up with our last update.
+		if apierrors.IsConflict(err) {
+			resourceState.State = "UPDATING"
+			return igr.delayedRequeue(fmt.Errorf("resource changed since it was observed: %w", err))
+		}
 		resourceState.State = "ERROR"
 		resourceState.Err = fmt.Errorf("failed to update resource: %w", err)
+		igr.recordWarning(EventReasonResourceUpdateFailed, "Failed to update %s %s: %v", desired.GetKind(), desired.GetName(), err)
 		return resourceState.Err
 	}
+	if igr.dryRun {
+		action := PlanActionUpdate
+		if adopt {
+			action = PlanActionAdopt
+		}
+		igr.planChange(resourceID, updated, action, "", differences)
+		igr.runtime.SetResource(resourceID, updated)
+		return nil
+	}
+	if adopt {
+		igr.recordEvent(EventReasonResourceAdopted, "Adopted existing %s %s (resource %s)", desired.GetKind(), desired.GetName(), resourceID)
+	} else {
+		igr.recordEvent(EventReasonResourceUpdated, "Updated %s %s (resource %s)", desired.GetKind(), desired.GetName(), resourceID)
+	}
 
-	// Set state to UPDATING and requeue to check the update
+	// Set state to UPDATING and requeue to check the update, the resource gets
+	// a full ready timeout to roll out the changes.
+	igr.readiness.reset(igr.runtime.GetInstance().GetUID(), resourceID)
 	resourceState.State = "UPDATING"
 	return igr.delayedRequeue(fmt.Errorf("resource update in progress"))
 }
@@ -291,7 +530,7 @@ func (igr *instanceGraphReconciler) handleInstanceDeletion(ctx context.Context)
 	igr.log.V(1).Info("Beginning instance deletion process")
 
 	// Initialize deletion state for all resources
-	if err := igr.initializeDeletionState(); err != nil {
+	if err := igr.initializeDeletionState(ctx); err != nil {
 		return fmt.Errorf("failed to initialize deletion state: %w", err)
 	}
 
@@ -300,13 +539,18 @@ func (igr *instanceGraphReconciler) handleInstanceDeletion(ctx context.Context)
 		return err
 	}
 
+	// Plans keep the instance finalizer
+	if igr.dryRun {
+		return nil
+	}
+
 	// Check if all resources are deleted and cleanup instance
 	return igr.finalizeDeletion(ctx)
 }
 
 // initializeDeletionState prepares resources for deletion by checking their
 // current state and marking them appropriately.
-func (igr *instanceGraphReconciler) initializeDeletionState() error {
+func (igr *instanceGraphReconciler) initializeDeletionState(ctx context.Context) error {
 	for _, resourceID := range igr.runtime.TopologicalOrder() {
 		if _, err := igr.runtime.Synchronize(); err != nil {
 			return fmt.Errorf("failed to synchronize during deletion state initialization: %w", err)
@@ -322,7 +566,7 @@ func (igr *instanceGraphReconciler) initializeDeletionState() error {
 
 		// Check if resource exists
 		rc := igr.getResourceClient(resourceID)
-		observed, err := rc.Get(context.TODO(), resource.GetName(), metav1.GetOptions{})
+		observed, err := igr.getObservedResource(ctx, rc, resourceID, resource.GetName())
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				igr.state.ResourceStates[resourceID] = &ResourceState{
@@ -333,6 +577,15 @@ func (igr *instanceGraphReconciler) initializeDeletionState() error {
 			return fmt.Errorf("failed to check resource %s existence: %w", resourceID, err)
 		}
 
+		// Never delete objects the instance doesn't own
+		if igr.getOwnership(observed) != ownedByInstance {
+			igr.log.V(1).Info("Skipping deletion of resource not owned by the instance", "resourceID", resourceID)
+			igr.state.ResourceStates[resourceID] = &ResourceState{
+				State: "SKIPPED",
+			}
+			continue
+		}
+
 		igr.runtime.SetResource(resourceID, observed)
 		igr.state.ResourceStates[resourceID] = &ResourceState{
 			State: "PENDING_DELETION",
@@ -362,14 +615,31 @@ func (igr *instanceGraphReconciler) deleteResourcesInOrder(ctx context.Context)
 }
 
 // deleteResource handles the deletion of a single resource and updates its state.
-func (igr *instanceGraphReconciler) deleteResource(ctx context.Context, resourceID string) error {
+func (igr *instanceGraphReconciler) deleteResource(ctx context.Context, resourceID string) (err error) {
 	igr.log.V(1).Info("Deleting resource", "resourceID", resourceID)
 
+	ctx, span := tracing.Start(ctx, "instance.deleteResource", attribute.String("kro.resource.id", resourceID))
+	defer tracing.End(span, &err)
+
 	resource, _ := igr.runtime.GetResource(resourceID)
 	rc := igr.getResourceClient(resourceID)
 
+	if igr.dryRun {
+		reason := ""
+		if resource.GetDeletionTimestamp() != nil {
+			reason = "deletion in progress"
+		}
+		igr.planChange(resourceID, resource, PlanActionDelete, reason, nil)
+		return nil
+	}
+
+	// Resources already being deleted are waited on, up to their delete timeout
+	if resource.GetDeletionTimestamp() != nil {
+		return igr.waitForDeletion(resourceID, resource)
+	}
+
 	// Attempt to delete the resource
-	err := rc.Delete(ctx, resource.GetName(), metav1.DeleteOptions{})
+	err = rc.Delete(ctx, resource.GetName(), metav1.DeleteOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			igr.state.ResourceStates[resourceID].State = "DELETED"
@@ -377,8 +647,10 @@ func (igr *instanceGraphReconciler) deleteResource(ctx context.Context, resource
 		}
 		igr.state.ResourceStates[resourceID].State = InstanceStateError
 		igr.state.ResourceStates[resourceID].Err = fmt.Errorf("failed to delete resource: %w", err)
+		igr.recordWarning(EventReasonResourceDeleteFailed, "Failed to delete %s %s: %v", resource.GetKind(), resource.GetName(), err)
 		return igr.state.ResourceStates[resourceID].Err
 	}
+	igr.recordEvent(EventReasonResourceDeleted, "Deleted %s %s (resource %s)", resource.GetKind(), resource.GetName(), resourceID)
 
 	igr.state.ResourceStates[resourceID].State = InstanceStateDeleting
 	return igr.delayedRequeue(fmt.Errorf("resource deletion in progress"))
@@ -387,9 +659,9 @@ func (igr *instanceGraphReconciler) deleteResource(ctx context.Context, resource
 // finalizeDeletion checks if all resources are deleted and removes the instance finalizer
 // if appropriate.
 func (igr *instanceGraphReconciler) finalizeDeletion(ctx context.Context) error {
-	// Check if all resources are deleted
+	// Check if all resources are deleted or abandoned
 	for _, resourceState := range igr.state.ResourceStates {
-		if resourceState.State != "DELETED" && resourceState.State != "SKIPPED" {
+		if resourceState.State != "DELETED" && resourceState.State != "SKIPPED" && resourceState.State != ResourceStateAbandoned {
 			return igr.delayedRequeue(fmt.Errorf("waiting for resource deletion completion"))
 		}
 	}
@@ -401,13 +673,16 @@ func (igr *instanceGraphReconciler) finalizeDeletion(ctx context.Context) error
 		return fmt.Errorf("failed to remove instance finalizer: %w", err)
 	}
 
+	igr.readiness.forget(instance.GetUID())
 	igr.runtime.SetInstance(patched)
 	return nil
 }
 
 // setManaged ensures the instance has the necessary finalizer and labels.
 func (igr *instanceGraphReconciler) setManaged(ctx context.Context, obj *unstructured.Unstructured, uid types.UID) (*unstructured.Unstructured, error) {
-	if exist, _ := metadata.HasInstanceFinalizerUnstructured(obj); exist {
+	revision := strconv.FormatInt(igr.revision, 10)
+	exist, _ := metadata.HasInstanceFinalizerUnstructured(obj)
+	if exist && (igr.revision == 0 || obj.GetAnnotations()[metadata.ResourceGraphDefinitionRevisionAnnotation] == revision) {
 		return obj, nil
 	}
 
@@ -419,9 +694,16 @@ func (igr *instanceGraphReconciler) setManaged(ctx context.Context, obj *unstruc
 	}
 
 	igr.instanceLabeler.ApplyLabels(copy)
+	if igr.revision != 0 {
+		annotations := copy.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[metadata.ResourceGraphDefinitionRevisionAnnotation] = revision
+		copy.SetAnnotations(annotations)
+	}
 
-	updated, err := igr.client.Resource(igr.gvr).
-		Namespace(obj.GetNamespace()).
+	updated, err := igr.getInstanceClient(obj.GetNamespace()).
 		Update(ctx, copy, metav1.UpdateOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update managed state: %w", err)
@@ -443,8 +725,7 @@ func (igr *instanceGraphReconciler) setUnmanaged(ctx context.Context, obj *unstr
 		return nil, fmt.Errorf("failed to remove finalizer: %w", err)
 	}
 
-	updated, err := igr.client.Resource(igr.gvr).
-		Namespace(obj.GetNamespace()).
+	updated, err := igr.getInstanceClient(obj.GetNamespace()).
 		Update(ctx, copy, metav1.UpdateOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update unmanaged state: %w", err)
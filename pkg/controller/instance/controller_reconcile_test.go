@@ -0,0 +1,141 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+
+	kroclient "github.com/kro-run/kro/pkg/client"
+)
+
+// eventReasons drains the events recorded so far and returns their reasons.
+func eventReasons(recorder *record.FakeRecorder) []string {
+	var reasons []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			reasons = append(reasons, strings.Fields(event)[1])
+		default:
+			return reasons
+		}
+	}
+}
+
+func TestReconcileResourceWaitingForReadinessEvent(t *testing.T) {
+	instance := newTestInstance("uid")
+	rt := newFakeRuntime(instance)
+	resource := &fakeResource{desired: newTestConfigMap("config", map[string]interface{}{"key": "value"})}
+	rt.addResource("config", resource)
+	observed := ownedBy(newTestConfigMap("config", map[string]interface{}{"key": "value"}), instance.GetUID())
+	igr, _, recorder := newTestReconciler(rt, observed)
+
+	reconcile := func() {
+		err := igr.reconcileResource(context.Background(), "config")
+		if resource.ready {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+		}
+	}
+
+	// Only the transition to waiting is recorded, not every requeue.
+	reconcile()
+	reconcile()
+	assert.Equal(t, []string{EventReasonWaitingForReadiness}, eventReasons(recorder))
+	assert.Equal(t, "WAITING_FOR_READINESS", igr.state.ResourceStates["config"].State)
+
+	resource.ready = true
+	reconcile()
+	assert.Empty(t, eventReasons(recorder))
+	assert.Equal(t, "SYNCED", igr.state.ResourceStates["config"].State)
+
+	// Waiting again is a new transition.
+	resource.ready = false
+	reconcile()
+	assert.Equal(t, []string{EventReasonWaitingForReadiness}, eventReasons(recorder))
+}
+
+func TestReconcileResourceUpdatesUnreadyResources(t *testing.T) {
+	instance := newTestInstance("uid")
+	rt := newFakeRuntime(instance)
+	// The resource can't become ready until the fixed spec is applied.
+	rt.addResource("config", &fakeResource{desired: newTestConfigMap("config", map[string]interface{}{"image": "fixed"})})
+	observed := ownedBy(newTestConfigMap("config", map[string]interface{}{"image": "broken"}), instance.GetUID())
+	igr, client, recorder := newTestReconciler(rt, observed)
+
+	err := igr.reconcileResource(context.Background(), "config")
+	require.Error(t, err)
+	assert.Equal(t, "UPDATING", igr.state.ResourceStates["config"].State)
+	assert.Equal(t, []string{EventReasonResourceUpdated}, eventReasons(recorder))
+
+	updated, err := client.Resource(testConfigMapGVR).Namespace("default").Get(context.Background(), "config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"image": "fixed"}, updated.Object["data"])
+}
+
+func TestGetObservedResourceChecksInstanceID(t *testing.T) {
+	instance := newTestInstance("uid")
+	rt := newFakeRuntime(instance)
+	rt.addResource("owned", &fakeResource{desired: newTestConfigMap("owned", nil)})
+	rt.addResource("other", &fakeResource{desired: newTestConfigMap("other", nil)})
+
+	source := func(source string) map[string]interface{} {
+		return map[string]interface{}{"source": source}
+	}
+	igr, client, _ := newTestReconciler(rt,
+		ownedBy(newTestConfigMap("owned", source("api")), "uid"),
+		ownedBy(newTestConfigMap("other", source("api")), "other-uid"),
+	)
+
+	// The cache is filled with kro's identity, and holds the objects of all
+	// the instances.
+	cacheClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(), map[schema.GroupVersionResource]string{
+		testConfigMapGVR: "ConfigMapList",
+	},
+		ownedBy(newTestConfigMap("owned", source("cache")), "uid"),
+		ownedBy(newTestConfigMap("other", source("cache")), "other-uid"),
+	)
+	igr.resourceCache = kroclient.NewResourceCache(logr.Discard(), cacheClient, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = igr.resourceCache.Start(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		_, ok := igr.resourceCache.Get(testConfigMapGVR, "default", "other")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	rc := client.Resource(testConfigMapGVR).Namespace("default")
+	owned, err := igr.getObservedResource(ctx, rc, "owned", "owned")
+	require.NoError(t, err)
+	assert.Equal(t, source("cache"), owned.Object["data"])
+
+	// The objects of other instances are read from the API server.
+	other, err := igr.getObservedResource(ctx, rc, "other", "other")
+	require.NoError(t, err)
+	assert.Equal(t, source("api"), other.Object["data"])
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+const (
+	// PausedReasonRollout is the Paused condition reason of instances waiting
+	// for a staged rollout to move them to the latest revision, when the graph
+	// of their revision can't be built.
+	PausedReasonRollout = "WaitingForRollout"
+	// PausedReasonRevisionUnavailable is the Paused condition reason of
+	// instances pinned to a revision whose graph can't be built, e.g because
+	// the revision doesn't exist.
+	PausedReasonRevisionUnavailable = "RevisionUnavailable"
+)
+
+// RevisionGraphs returns the graphs of the revisions of a
+// ResourceGraphDefinition.
+type RevisionGraphs interface {
+	Graph(ctx context.Context, revision int64) (*graph.Graph, error)
+}
+
+// Revisions are the revisions of the ResourceGraphDefinition a controller
+// reconciles instances with. A revision is a generation of the
+// ResourceGraphDefinition that changed its graph.
+type Revisions struct {
+	// Current is the revision of the controller graph.
+	Current int64
+	// Previous is the revision served before the current one. Instances
+	// managed before the rollout policy was set keep using it until a staged
+	// rollout moves them.
+	Previous int64
+	// Graphs returns the graphs of the other revisions, used by pinned
+	// instances and during staged rollouts.
+	Graphs RevisionGraphs
+	// Staged is true when the ResourceGraphDefinition has a rollout policy.
+	// Instances are then reconciled with the revision of their
+	// metadata.ResourceGraphDefinitionRevisionAnnotation, and only new
+	// instances start with the current revision.
+	Staged bool
+}
+
+// SetRevisions sets the revisions the controller reconciles instances with.
+// It must be called before the controller starts serving.
+func (c *Controller) SetRevisions(revisions Revisions) {
+	c.revisions = revisions
+}
+
+// resolveRevision returns the graph and the revision the instance is
+// reconciled with. Instances pinned with the metadata.PinnedRevisionAnnotation
+// use their pinned revision, and instances of staged rollouts the revision
+// they were moved to. When the graph of the revision can't be built, the
+// instance is observed with the current graph, and the reason it waits is
+// returned.
+func (c *Controller) resolveRevision(ctx context.Context, instance *unstructured.Unstructured) (*graph.Graph, int64, string) {
+	current := c.revisions.Current
+	if current == 0 || c.revisions.Graphs == nil {
+		return c.rgd, current, ""
+	}
+	deleting := !instance.GetDeletionTimestamp().IsZero()
+
+	revision, waitingReason := current, ""
+	if value, ok := instance.GetAnnotations()[metadata.PinnedRevisionAnnotation]; ok {
+		pinned, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.log.Info("Ignoring invalid pinned revision", "namespace", instance.GetNamespace(),
+				"name", instance.GetName(), "revision", value)
+		} else {
+			revision, waitingReason = pinned, PausedReasonRevisionUnavailable
+		}
+	}
+	if waitingReason == "" && c.revisions.Staged && !deleting {
+		revision, waitingReason = min(c.stagedRevision(instance), current), PausedReasonRollout
+	}
+
+	if revision == current {
+		return c.rgd, current, ""
+	}
+	g, err := c.revisions.Graphs.Graph(ctx, revision)
+	if err != nil {
+		if deleting {
+			return c.rgd, current, ""
+		}
+		c.log.V(1).Info("Graph of revision is unavailable", "namespace", instance.GetNamespace(),
+			"name", instance.GetName(), "revision", revision, "error", err.Error())
+		return c.rgd, revision, waitingReason
+	}
+	return g, revision, ""
+}
+
+// stagedRevision returns the revision of an instance during staged rollouts,
+// from its metadata.ResourceGraphDefinitionRevisionAnnotation.
+func (c *Controller) stagedRevision(instance *unstructured.Unstructured) int64 {
+	value, ok := instance.GetAnnotations()[metadata.ResourceGraphDefinitionRevisionAnnotation]
+	if !ok {
+		// New instances start with the current revision.
+		if managed, _ := metadata.HasInstanceFinalizerUnstructured(instance); !managed {
+			return c.revisions.Current
+		}
+		return c.revisions.Previous
+	}
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		c.log.Info("Ignoring invalid revision annotation", "namespace", instance.GetNamespace(),
+			"name", instance.GetName(), "revision", value)
+		return c.revisions.Current
+	}
+	return revision
+}
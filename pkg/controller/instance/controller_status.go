@@ -16,10 +16,12 @@ package instance
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/kro-run/kro/api/v1alpha1"
 	"github.com/kro-run/kro/pkg/requeue"
@@ -44,6 +46,20 @@ func (igr *instanceGraphReconciler) prepareStatus() map[string]interface{} {
 	status["state"] = igr.state.State
 	status["conditions"] = igr.prepareConditions(igr.state.ReconcileErr, generation)
 
+	if igr.revision != 0 {
+		status["revision"] = igr.revision
+	}
+
+	delete(status, "plan")
+	if igr.state.Plan != nil {
+		plan, err := planStatus(igr.state.Plan)
+		if err != nil {
+			igr.log.Error(err, "Failed to prepare instance plan status")
+		} else {
+			status["plan"] = plan
+		}
+	}
+
 	return status
 }
 
@@ -91,16 +107,96 @@ func (igr *instanceGraphReconciler) prepareConditions(
 		))
 	}
 
+	if condition := igr.prepareAccessCondition(generation); condition != nil {
+		conditions = append(conditions, condition)
+	}
+
+	if igr.state.PausedReason != "" {
+		conditions = append(conditions, createCondition(
+			v1alpha1.InstanceConditionTypePaused,
+			corev1.ConditionTrue,
+			igr.state.PausedReason,
+			"Reconciliation is paused, resources are not created, updated or deleted",
+			generation,
+		))
+	}
+
+	// The Stalled condition, set by Controller.Stalled, is kept while the
+	// instance keeps failing. Requeues, e.g of paused instances or of
+	// resources waiting for readiness, are progress and clear it, as they do
+	// in updateInstanceState.
+	switch reconcileErr.(type) {
+	case nil, *requeue.NoRequeue, *requeue.RequeueNeeded, *requeue.RequeueNeededAfter:
+	default:
+		if condition := igr.stalledCondition(); condition != nil {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	if degradation := igr.state.Degradation; degradation != nil {
+		conditions = append(conditions, createCondition(
+			v1alpha1.InstanceConditionTypeDegraded,
+			corev1.ConditionTrue,
+			degradation.Reason,
+			degradation.Message,
+			generation,
+		))
+	}
+
 	return conditions
 }
 
+// stalledCondition returns the Stalled condition of the instance, or nil if
+// it isn't stalled.
+func (igr *instanceGraphReconciler) stalledCondition() map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(igr.runtime.GetInstance().Object, "status", "conditions")
+	for _, condition := range conditions {
+		if cond, ok := condition.(map[string]interface{}); ok && cond["type"] == string(v1alpha1.InstanceConditionTypeStalled) {
+			return cond
+		}
+	}
+	return nil
+}
+
+// prepareAccessCondition creates the ResourcesAccessible condition from the
+// pre-flight access review, or returns nil if the review did not run.
+func (igr *instanceGraphReconciler) prepareAccessCondition(generation int64) map[string]interface{} {
+	switch {
+	case !igr.state.AccessReviewed:
+		return nil
+	case igr.state.AccessReviewErr != nil:
+		return createCondition(
+			v1alpha1.InstanceConditionTypeResourcesAccessible,
+			corev1.ConditionUnknown,
+			"AccessReviewFailed",
+			igr.state.AccessReviewErr.Error(),
+			generation,
+		)
+	case len(igr.state.MissingPermissions) > 0:
+		return createCondition(
+			v1alpha1.InstanceConditionTypeResourcesAccessible,
+			corev1.ConditionFalse,
+			"MissingPermissions",
+			"Missing permissions: "+strings.Join(igr.state.MissingPermissions, ", "),
+			generation,
+		)
+	default:
+		return createCondition(
+			v1alpha1.InstanceConditionTypeResourcesAccessible,
+			corev1.ConditionTrue,
+			"PermissionsGranted",
+			"All the permissions required by the resources are granted",
+			generation,
+		)
+	}
+}
+
 // patchInstanceStatus updates the status subresource of the instance.
 func (igr *instanceGraphReconciler) patchInstanceStatus(ctx context.Context, status map[string]interface{}) error {
 	instance := igr.runtime.GetInstance().DeepCopy()
 	instance.Object["status"] = status
 
-	_, err := igr.client.Resource(igr.gvr).
-		Namespace(instance.GetNamespace()).
+	_, err := igr.getInstanceClient(instance.GetNamespace()).
 		UpdateStatus(ctx, instance, metav1.UpdateOptions{})
 
 	if err != nil {
@@ -111,6 +207,11 @@ func (igr *instanceGraphReconciler) patchInstanceStatus(ctx context.Context, sta
 
 // updateInstanceState updates the instance state based on reconciliation results
 func (igr *instanceGraphReconciler) updateInstanceState() {
+	if igr.state.Degradation != nil && igr.state.State != InstanceStateDeleting {
+		igr.state.State = InstanceStateDegraded
+		return
+	}
+
 	switch igr.state.ReconcileErr.(type) {
 	case *requeue.NoRequeue, *requeue.RequeueNeeded, *requeue.RequeueNeededAfter:
 		// Keep current state for requeue errors
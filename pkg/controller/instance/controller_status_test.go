@@ -0,0 +1,54 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/requeue"
+)
+
+func TestPrepareConditionsKeepsStalledOnFailures(t *testing.T) {
+	instance := newTestInstance("uid")
+	stalled := createCondition(v1alpha1.InstanceConditionTypeStalled, corev1.ConditionTrue, "MaxRetriesExceeded", "boom", 1)
+	require.NoError(t, unstructured.SetNestedSlice(instance.Object, []interface{}{stalled}, "status", "conditions"))
+	igr, _, _ := newTestReconciler(newFakeRuntime(instance))
+
+	hasStalled := func(reconcileErr error) bool {
+		for _, condition := range igr.prepareConditions(reconcileErr, 1) {
+			if condition.(map[string]interface{})["type"] == string(v1alpha1.InstanceConditionTypeStalled) {
+				return true
+			}
+		}
+		return false
+	}
+
+	err := errors.New("boom")
+	assert.True(t, hasStalled(err))
+
+	// Paused instances, or instances waiting for revisions or readiness, make
+	// progress.
+	assert.False(t, hasStalled(nil))
+	assert.False(t, hasStalled(requeue.None(err)))
+	assert.False(t, hasStalled(requeue.Needed(err)))
+	assert.False(t, hasStalled(requeue.NeededAfter(err, time.Second)))
+}
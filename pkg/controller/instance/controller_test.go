@@ -0,0 +1,131 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	kroclient "github.com/kro-run/kro/pkg/client"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+func TestAuthorizeRequestedServiceAccount(t *testing.T) {
+	const policyName = "kro-service-account-requester"
+	policy := &admissionregistrationv1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: policyName},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+			MatchConstraints: &admissionregistrationv1.MatchResources{
+				ResourceRules: []admissionregistrationv1.NamedRuleWithOperations{{
+					RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.OperationAll},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   []string{testInstanceGVR.Group},
+							APIVersions: []string{"*"},
+							Resources:   []string{"*"},
+						},
+					},
+				}},
+			},
+		},
+	}
+	binding := &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: policyName},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        policyName,
+			ValidationActions: []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		policyName string
+		sa         string
+		requester  string
+		wantErr    string
+	}{
+		{
+			name:       "requester allowed to impersonate",
+			policyName: policyName,
+			sa:         "deployer",
+			requester:  "alice",
+		},
+		{
+			name:       "requester denied to impersonate",
+			policyName: policyName,
+			sa:         "deployer",
+			requester:  "mallory",
+			wantErr:    `user "mallory" is not allowed to impersonate service account default/deployer`,
+		},
+		{
+			name:       "missing requester",
+			policyName: policyName,
+			sa:         "deployer",
+			wantErr:    "annotation kro.run/service-account-requester is required",
+		},
+		{
+			name:       "service account not allowed by the resource graph definition",
+			policyName: policyName,
+			sa:         "admin",
+			requester:  "alice",
+			wantErr:    `service account "admin" is not allowed`,
+		},
+		{
+			name:      "requester not protected by an admission policy",
+			sa:        "deployer",
+			requester: "alice",
+			wantErr:   "service account requests are disabled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(policy, binding)
+			clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				review.Status.Allowed = review.Spec.User == "alice"
+				return true, review, nil
+			})
+			c := &Controller{
+				log:                      logr.Discard(),
+				gvr:                      testInstanceGVR,
+				serviceAccountPolicy:     &v1alpha1.ServiceAccountPolicy{AllowedServiceAccounts: []string{"deployer"}},
+				serviceAccountAuthorizer: kroclient.NewServiceAccountAuthorizer(clientset, tt.policyName),
+			}
+
+			instance := newTestInstance("uid")
+			annotations := map[string]string{metadata.ServiceAccountAnnotation: tt.sa}
+			if tt.requester != "" {
+				annotations[metadata.ServiceAccountRequesterAnnotation] = tt.requester
+			}
+			instance.SetAnnotations(annotations)
+
+			err := c.authorizeRequestedServiceAccount(context.Background(), instance, "default", tt.sa)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/requeue"
+)
+
+// readinessTracker remembers since when the resources of each instance have
+// been waiting for readiness. The instance state is rebuilt at every
+// reconciliation, so the tracker is shared by all the reconciliations of a
+// controller. Clocks start over when kro restarts.
+type readinessTracker struct {
+	mu    sync.Mutex
+	since map[readinessKey]time.Time
+}
+
+type readinessKey struct {
+	instance   types.UID
+	resourceID string
+}
+
+func newReadinessTracker() *readinessTracker {
+	return &readinessTracker{since: make(map[readinessKey]time.Time)}
+}
+
+// waitingSince returns since when the resource has been waiting for
+// readiness, starting its clock at now if it isn't running. It also returns
+// whether the clock was already running, i.e the resource was already waiting.
+func (t *readinessTracker) waitingSince(instance types.UID, resourceID string, now time.Time) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := readinessKey{instance: instance, resourceID: resourceID}
+	since, ok := t.since[key]
+	if !ok {
+		since = now
+		t.since[key] = since
+	}
+	return since, ok
+}
+
+// reset stops the clock of the resource, e.g when it became ready or was
+// created or updated.
+func (t *readinessTracker) reset(instance types.UID, resourceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.since, readinessKey{instance: instance, resourceID: resourceID})
+}
+
+// forget stops the clocks of all the resources of the instance.
+func (t *readinessTracker) forget(instance types.UID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.since {
+		if key.instance == instance {
+			delete(t.since, key)
+		}
+	}
+}
+
+// waitForReadiness requeues the instance while the resource isn't ready. Once
+// the resource ready timeout is exceeded, the instance is reported as Degraded
+// with the reason the resource isn't ready, e.g the failing readyWhen
+// expression, and requeued with an exponential backoff.
+func (igr *instanceGraphReconciler) waitForReadiness(resourceID, reason string, since time.Time, resourceState *ResourceState) error {
+	timeout := igr.runtime.ResourceDescriptor(resourceID).GetReadyTimeout()
+	if timeout == 0 {
+		return igr.delayedRequeue(resourceState.Err)
+	}
+
+	elapsed := time.Since(since)
+	if elapsed < timeout {
+		return igr.delayedRequeue(resourceState.Err)
+	}
+
+	igr.state.Degradation = &Degradation{
+		Reason:  EventReasonReadyTimeout,
+		Message: fmt.Sprintf("resource %s is not ready after %s: %s", resourceID, timeout, reason),
+	}
+	igr.recordWarning(EventReasonReadyTimeout, "Resource %s is not ready after %s: %s", resourceID, timeout, reason)
+	return igr.backoffRequeue(resourceState.Err, elapsed-timeout)
+}
+
+// waitForDeletion requeues the instance while the deletion of the resource is
+// in progress. Once the resource delete timeout, if any, is exceeded, the
+// instance is reported as Degraded and requeued with an exponential backoff,
+// unless the instance has the metadata.AbandonOnDeleteTimeoutAnnotation, in
+// which case the resource is abandoned.
+func (igr *instanceGraphReconciler) waitForDeletion(resourceID string, observed *unstructured.Unstructured) error {
+	resourceState := igr.state.ResourceStates[resourceID]
+	resourceState.State = InstanceStateDeleting
+
+	timeout := igr.runtime.ResourceDescriptor(resourceID).GetDeleteTimeout()
+	elapsed := time.Since(observed.GetDeletionTimestamp().Time)
+	if timeout == 0 || elapsed < timeout {
+		return igr.delayedRequeue(fmt.Errorf("resource deletion in progress"))
+	}
+
+	if igr.runtime.GetInstance().GetAnnotations()[metadata.AbandonOnDeleteTimeoutAnnotation] == "true" {
+		resourceState.State = ResourceStateAbandoned
+		igr.recordWarning(EventReasonResourceAbandoned, "Abandoned %s %s (resource %s), its deletion didn't complete after %s",
+			observed.GetKind(), observed.GetName(), resourceID, timeout)
+		return nil
+	}
+
+	message := fmt.Sprintf("resource %s is not deleted after %s", resourceID, timeout)
+	if finalizers := observed.GetFinalizers(); len(finalizers) > 0 {
+		message = fmt.Sprintf("%s: waiting for finalizers %v", message, finalizers)
+	}
+	igr.state.Degradation = &Degradation{
+		Reason:  EventReasonDeleteTimeout,
+		Message: message,
+	}
+	igr.recordWarning(EventReasonDeleteTimeout, "Resource %s is not deleted after %s, set the %s annotation to \"true\" to abandon it",
+		resourceID, timeout, metadata.AbandonOnDeleteTimeoutAnnotation)
+	return igr.backoffRequeue(fmt.Errorf("%s", message), elapsed-timeout)
+}
+
+// backoffRequeue requeues a degraded instance after the time elapsed since
+// the timeout was exceeded, so that the interval between two attempts doubles
+// each time. The delay is at least DefaultRequeueDuration, and at most
+// MaxRequeueDuration.
+func (igr *instanceGraphReconciler) backoffRequeue(err error, overdue time.Duration) error {
+	delay := max(overdue, igr.reconcileConfig.DefaultRequeueDuration)
+	if limit := igr.reconcileConfig.MaxRequeueDuration; limit > 0 {
+		delay = min(delay, limit)
+	}
+	return requeue.NeededAfter(err, delay)
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/requeue"
+)
+
+// requeueDelay returns the delay of a requeue.NeededAfter error.
+func requeueDelay(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var after *requeue.RequeueNeededAfter
+	require.ErrorAs(t, err, &after)
+	return after.Duration()
+}
+
+func TestBackoffRequeue(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     time.Duration
+		overdue time.Duration
+		want    time.Duration
+	}{
+		{
+			name:    "just overdue",
+			max:     5 * time.Minute,
+			overdue: time.Second,
+			want:    3 * time.Second,
+		},
+		{
+			name:    "overdue",
+			max:     5 * time.Minute,
+			overdue: time.Minute,
+			want:    time.Minute,
+		},
+		{
+			name:    "long overdue",
+			max:     5 * time.Minute,
+			overdue: time.Hour,
+			want:    5 * time.Minute,
+		},
+		{
+			name:    "no maximum",
+			overdue: time.Hour,
+			want:    time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			igr, _, _ := newTestReconciler(newFakeRuntime(newTestInstance("uid")))
+			igr.reconcileConfig.MaxRequeueDuration = tt.max
+			err := igr.backoffRequeue(errors.New("timeout"), tt.overdue)
+			assert.Equal(t, tt.want, requeueDelay(t, err))
+			assert.ErrorContains(t, err, "timeout")
+		})
+	}
+}
+
+func TestWaitForReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		waiting  time.Duration
+		degraded bool
+	}{
+		{
+			name:    "without timeout",
+			waiting: time.Hour,
+		},
+		{
+			name:    "within timeout",
+			timeout: time.Minute,
+			waiting: 30 * time.Second,
+		},
+		{
+			name:     "timeout exceeded",
+			timeout:  time.Minute,
+			waiting:  time.Hour,
+			degraded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newFakeRuntime(newTestInstance("uid"))
+			rt.addResource("config", &fakeResource{
+				desired:      newTestConfigMap("config", nil),
+				readyTimeout: tt.timeout,
+			})
+			igr, _, recorder := newTestReconciler(rt)
+			resourceState := &ResourceState{State: "WAITING_FOR_READINESS", Err: errors.New("resource not ready")}
+
+			err := igr.waitForReadiness("config", "replicas unavailable", time.Now().Add(-tt.waiting), resourceState)
+			if !tt.degraded {
+				assert.Equal(t, 3*time.Second, requeueDelay(t, err))
+				assert.Nil(t, igr.state.Degradation)
+				assert.Empty(t, eventReasons(recorder))
+				return
+			}
+
+			// Requeued after the time elapsed since the timeout was exceeded
+			assert.InDelta(t, tt.waiting-tt.timeout, requeueDelay(t, err), float64(time.Second))
+			require.NotNil(t, igr.state.Degradation)
+			assert.Equal(t, EventReasonReadyTimeout, igr.state.Degradation.Reason)
+			assert.Equal(t, "resource config is not ready after 1m0s: replicas unavailable", igr.state.Degradation.Message)
+			assert.Equal(t, []string{EventReasonReadyTimeout}, eventReasons(recorder))
+		})
+	}
+}
+
+func TestWaitForDeletion(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		deleting time.Duration
+		abandon  bool
+		// want is the resource state, and wantReason the degradation reason.
+		want       string
+		wantReason string
+	}{
+		{
+			name:     "without timeout",
+			deleting: time.Hour,
+			want:     InstanceStateDeleting,
+		},
+		{
+			name:     "within timeout",
+			timeout:  time.Minute,
+			deleting: 30 * time.Second,
+			want:     InstanceStateDeleting,
+		},
+		{
+			name:       "timeout exceeded",
+			timeout:    time.Minute,
+			deleting:   time.Hour,
+			want:       InstanceStateDeleting,
+			wantReason: EventReasonDeleteTimeout,
+		},
+		{
+			name:     "abandoned within timeout",
+			timeout:  time.Minute,
+			deleting: 30 * time.Second,
+			abandon:  true,
+			want:     InstanceStateDeleting,
+		},
+		{
+			name:     "abandoned without timeout",
+			deleting: time.Hour,
+			abandon:  true,
+			want:     InstanceStateDeleting,
+		},
+		{
+			name:     "abandoned after timeout",
+			timeout:  time.Minute,
+			deleting: time.Hour,
+			abandon:  true,
+			want:     ResourceStateAbandoned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestInstance("uid")
+			if tt.abandon {
+				instance.SetAnnotations(map[string]string{metadata.AbandonOnDeleteTimeoutAnnotation: "true"})
+			}
+			rt := newFakeRuntime(instance)
+			rt.addResource("config", &fakeResource{
+				desired:       newTestConfigMap("config", nil),
+				deleteTimeout: tt.timeout,
+			})
+			igr, _, recorder := newTestReconciler(rt)
+			igr.state.ResourceStates["config"] = &ResourceState{State: "PENDING_DELETION"}
+
+			observed := newTestConfigMap("config", nil)
+			observed.SetDeletionTimestamp(&metav1.Time{Time: time.Now().Add(-tt.deleting)})
+			observed.SetFinalizers([]string{"example.com/cleanup"})
+
+			err := igr.waitForDeletion("config", observed)
+			assert.Equal(t, tt.want, igr.state.ResourceStates["config"].State)
+			switch {
+			case tt.want == ResourceStateAbandoned:
+				assert.NoError(t, err)
+				assert.Nil(t, igr.state.Degradation)
+				assert.Equal(t, []string{EventReasonResourceAbandoned}, eventReasons(recorder))
+			case tt.wantReason != "":
+				assert.InDelta(t, tt.deleting-tt.timeout, requeueDelay(t, err), float64(time.Second))
+				require.NotNil(t, igr.state.Degradation)
+				assert.Equal(t, tt.wantReason, igr.state.Degradation.Reason)
+				assert.Equal(t, "resource config is not deleted after 1m0s: waiting for finalizers [example.com/cleanup]",
+					igr.state.Degradation.Message)
+				assert.Equal(t, []string{EventReasonDeleteTimeout}, eventReasons(recorder))
+			default:
+				assert.Equal(t, 3*time.Second, requeueDelay(t, err))
+				assert.Nil(t, igr.state.Degradation)
+				assert.Empty(t, eventReasons(recorder))
+			}
+		})
+	}
+}
+
+func TestHandleInstanceDeletionAbandonsResources(t *testing.T) {
+	instance := newTestInstance("uid")
+	instance.SetAnnotations(map[string]string{metadata.AbandonOnDeleteTimeoutAnnotation: "true"})
+	instance.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	require.NoError(t, metadata.SetInstanceFinalizerUnstructured(instance))
+
+	rt := newFakeRuntime(instance)
+	rt.addResource("stuck", &fakeResource{
+		desired:       newTestConfigMap("stuck", nil),
+		deleteTimeout: time.Minute,
+	})
+	rt.addResource("deleted", &fakeResource{desired: newTestConfigMap("deleted", nil)})
+
+	stuck := ownedBy(newTestConfigMap("stuck", nil), instance.GetUID())
+	stuck.SetDeletionTimestamp(&metav1.Time{Time: time.Now().Add(-time.Hour)})
+	stuck.SetFinalizers([]string{"example.com/cleanup"})
+	igr, _, recorder := newTestReconciler(rt, instance.DeepCopy(), stuck)
+
+	// The stuck resource is abandoned, and the instance finalizer removed.
+	require.NoError(t, igr.handleInstanceDeletion(context.Background()))
+	assert.Equal(t, ResourceStateAbandoned, igr.state.ResourceStates["stuck"].State)
+	assert.Equal(t, "DELETED", igr.state.ResourceStates["deleted"].State)
+	assert.Equal(t, []string{EventReasonResourceAbandoned}, eventReasons(recorder))
+	hasFinalizer, err := metadata.HasInstanceFinalizerUnstructured(rt.GetInstance())
+	require.NoError(t, err)
+	assert.False(t, hasFinalizer)
+}
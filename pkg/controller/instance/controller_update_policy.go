@@ -0,0 +1,196 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+// statefulSetForbiddenUpdateMsg is the message of the StatefulSet validation
+// rejecting updates to the fields of its spec that can't be changed.
+const statefulSetForbiddenUpdateMsg = "updates to statefulset spec for fields other than"
+
+// isImmutableFieldError returns true if the API server rejected an update
+// because it changes immutable fields, e.g "field is immutable" for Job
+// templates, or forbidden updates to StatefulSet specs. Other invalid
+// updates, e.g forbidden values, are not fixed by recreating the object.
+func isImmutableFieldError(err error) bool {
+	if !apierrors.IsInvalid(err) {
+		return false
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return false
+	}
+	for _, cause := range status.Status().Details.Causes {
+		switch {
+		case cause.Type == metav1.CauseTypeFieldValueInvalid &&
+			strings.Contains(cause.Message, apimachineryvalidation.FieldImmutableErrorMsg):
+			return true
+		case cause.Type == metav1.CauseType(field.ErrorTypeForbidden) &&
+			strings.Contains(cause.Message, statefulSetForbiddenUpdateMsg):
+			return true
+		}
+	}
+	return false
+}
+
+// recreateResource deletes an object whose update was rejected because of
+// immutable fields, so that it is re-created by a later reconciliation. The
+// resources depending on it may hold values of the current object (e.g its
+// UID or generated fields), so they are recreated with it: they are deleted
+// first, and the object is only deleted once they are gone. They are then
+// re-created in topological order, once the new object is ready.
+//
+// Objects are deleted in the foreground so that their own dependents (e.g the
+// pods of a Job) are gone before they are re-created.
+func (igr *instanceGraphReconciler) recreateResource(
+	ctx context.Context,
+	rc dynamic.ResourceInterface,
+	observed *unstructured.Unstructured,
+	resourceID string,
+	resourceState *ResourceState,
+	updateErr error,
+) error {
+	igr.log.Info("Recreating resource with immutable field changes", "resourceID", resourceID, "error", updateErr)
+
+	remaining, err := igr.deleteDependents(ctx, resourceID)
+	if err != nil {
+		resourceState.State = "ERROR"
+		resourceState.Err = fmt.Errorf("failed to delete the dependents of the resource for recreation: %w", err)
+		return resourceState.Err
+	}
+	if remaining > 0 {
+		resourceState.State = "RECREATING"
+		return igr.delayedRequeue(fmt.Errorf("waiting for %d dependents to be deleted before recreating the resource", remaining))
+	}
+
+	uid := observed.GetUID()
+	propagation := metav1.DeletePropagationForeground
+	err = rc.Delete(ctx, observed.GetName(), metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+		Preconditions:     &metav1.Preconditions{UID: &uid},
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		resourceState.State = "ERROR"
+		resourceState.Err = fmt.Errorf("failed to delete resource for recreation: %w", err)
+		igr.recordWarning(EventReasonResourceDeleteFailed, "Failed to delete %s %s for recreation: %v", observed.GetKind(), observed.GetName(), err)
+		return resourceState.Err
+	}
+
+	igr.recordEvent(EventReasonResourceRecreated, "Recreating %s %s (resource %s) after an immutable field change: %v",
+		observed.GetKind(), observed.GetName(), resourceID, updateErr)
+	resourceState.State = "RECREATING"
+	return igr.delayedRequeue(fmt.Errorf("resource recreation in progress"))
+}
+
+// dependents returns the resources depending, directly or not, on a resource,
+// in topological order.
+func (igr *instanceGraphReconciler) dependents(resourceID string) []string {
+	order := igr.runtime.TopologicalOrder()
+	start := slices.Index(order, resourceID)
+	if start < 0 {
+		return nil
+	}
+
+	depending := map[string]bool{resourceID: true}
+	var dependents []string
+	for _, id := range order[start+1:] {
+		if slices.ContainsFunc(igr.runtime.ResourceDescriptor(id).GetDependencies(), func(dependency string) bool {
+			return depending[dependency]
+		}) {
+			depending[id] = true
+			dependents = append(dependents, id)
+		}
+	}
+	return dependents
+}
+
+// deleteDependents deletes the objects of the dependents of a resource being
+// recreated, in reverse topological order, and returns how many of them still
+// exist. The resources following the recreated one are observed so that the
+// dependents resolve, as they do when the instance is deleted. Objects that
+// the instance doesn't own are left alone.
+func (igr *instanceGraphReconciler) deleteDependents(ctx context.Context, resourceID string) (int, error) {
+	dependents := igr.dependents(resourceID)
+	if len(dependents) == 0 {
+		return 0, nil
+	}
+
+	order := igr.runtime.TopologicalOrder()
+	observed := make(map[string]*unstructured.Unstructured, len(dependents))
+	for _, id := range order[slices.Index(order, resourceID)+1:] {
+		if err := igr.synchronize(ctx); err != nil {
+			return 0, err
+		}
+		resource, state := igr.runtime.GetResource(id)
+		if state != runtime.ResourceStateResolved {
+			continue
+		}
+		obj, err := igr.getObservedResource(ctx, igr.getResourceClient(id), id, resource.GetName())
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get resource %s: %w", id, err)
+		}
+		igr.runtime.SetResource(id, obj)
+		if slices.Contains(dependents, id) && igr.getOwnership(obj) == ownedByInstance {
+			observed[id] = obj
+		}
+	}
+
+	remaining := 0
+	for _, id := range slices.Backward(dependents) {
+		obj, ok := observed[id]
+		if !ok {
+			continue
+		}
+		remaining++
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		uid := obj.GetUID()
+		propagation := metav1.DeletePropagationForeground
+		err := igr.getResourceClient(id).Delete(ctx, obj.GetName(), metav1.DeleteOptions{
+			PropagationPolicy: &propagation,
+			Preconditions:     &metav1.Preconditions{UID: &uid},
+		})
+		if apierrors.IsNotFound(err) {
+			remaining--
+			continue
+		}
+		if err != nil {
+			igr.recordWarning(EventReasonResourceDeleteFailed, "Failed to delete %s %s for recreation: %v", obj.GetKind(), obj.GetName(), err)
+			return 0, fmt.Errorf("failed to delete resource %s: %w", id, err)
+		}
+		igr.recordEvent(EventReasonResourceDeleted, "Deleted %s %s (resource %s) to recreate it with resource %s",
+			obj.GetKind(), obj.GetName(), id, resourceID)
+	}
+	return remaining, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+func TestIsImmutableFieldError(t *testing.T) {
+	jobs := schema.GroupKind{Group: "batch", Kind: "Job"}
+	statefulSets := schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+	deployments := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	templatePath := field.NewPath("spec", "template")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "immutable job template",
+			err: apierrors.NewInvalid(jobs, "job", field.ErrorList{
+				field.Invalid(templatePath, nil, apimachineryvalidation.FieldImmutableErrorMsg),
+			}),
+			want: true,
+		},
+		{
+			name: "forbidden statefulset spec update",
+			err: apierrors.NewInvalid(statefulSets, "db", field.ErrorList{
+				field.Forbidden(field.NewPath("spec"), "updates to statefulset spec for fields other than 'replicas', "+
+					"'ordinals', 'template', 'updateStrategy', 'persistentVolumeClaimRetentionPolicy' and 'minReadySeconds' are forbidden"),
+			}),
+			want: true,
+		},
+		{
+			name: "immutable field among other causes",
+			err: apierrors.NewInvalid(jobs, "job", field.ErrorList{
+				field.Required(field.NewPath("spec", "backoffLimit"), ""),
+				field.Invalid(field.NewPath("spec", "selector"), nil, apimachineryvalidation.FieldImmutableErrorMsg),
+			}),
+			want: true,
+		},
+		{
+			name: "wrapped immutable field error",
+			err: fmt.Errorf("failed to update: %w", apierrors.NewInvalid(jobs, "job", field.ErrorList{
+				field.Invalid(templatePath, nil, apimachineryvalidation.FieldImmutableErrorMsg),
+			})),
+			want: true,
+		},
+		{
+			name: "invalid value",
+			err: apierrors.NewInvalid(deployments, "app", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+			}),
+		},
+		{
+			name: "forbidden value",
+			err: apierrors.NewInvalid(deployments, "app", field.ErrorList{
+				field.Forbidden(templatePath.Child("spec", "hostNetwork"), "host network is not allowed"),
+			}),
+		},
+		{
+			name: "forbidden value mentioning immutability",
+			err: apierrors.NewInvalid(deployments, "app", field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "selector"), "field is immutable"),
+			}),
+		},
+		{
+			name: "immutable value reported as required",
+			err: apierrors.NewInvalid(deployments, "app", field.ErrorList{
+				field.Required(field.NewPath("spec", "selector"), "field is immutable"),
+			}),
+		},
+		{
+			name: "invalid without causes",
+			err: &apierrors.StatusError{ErrStatus: metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusUnprocessableEntity,
+				Reason:  metav1.StatusReasonInvalid,
+				Message: "field is immutable",
+			}},
+		},
+		{
+			name: "conflict",
+			err:  apierrors.NewConflict(schema.GroupResource{Group: "batch", Resource: "jobs"}, "job", errors.New("field is immutable")),
+		},
+		{
+			name: "not an API error",
+			err:  errors.New("field is immutable"),
+		},
+		{
+			name: "nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isImmutableFieldError(tt.err))
+		})
+	}
+}
+
+func TestRecreateResourceWithDependents(t *testing.T) {
+	instance := newTestInstance("uid")
+	rt := newFakeRuntime(instance)
+	rt.addResource("job", &fakeResource{
+		desired: newTestConfigMap("job", map[string]interface{}{"template": "v2"}),
+		update:  v1alpha1.UpdatePolicyRecreate,
+		ready:   true,
+	})
+	rt.addResource("other", &fakeResource{desired: newTestConfigMap("other", nil), ready: true})
+	rt.addResource("config", &fakeResource{desired: newTestConfigMap("config", nil), dependencies: []string{"job"}, ready: true})
+	rt.addResource("app", &fakeResource{desired: newTestConfigMap("app", nil), dependencies: []string{"other", "config"}, ready: true})
+	igr, client, recorder := newTestReconciler(rt,
+		ownedBy(newTestConfigMap("job", map[string]interface{}{"template": "v1"}), instance.GetUID()),
+		ownedBy(newTestConfigMap("other", nil), instance.GetUID()),
+		ownedBy(newTestConfigMap("config", nil), instance.GetUID()),
+		ownedBy(newTestConfigMap("app", nil), instance.GetUID()),
+	)
+	client.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		return true, nil, apierrors.NewInvalid(schema.GroupKind{Group: "batch", Kind: "Job"}, "job", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "template"), nil, apimachineryvalidation.FieldImmutableErrorMsg),
+		})
+	})
+	configMaps := client.Resource(testConfigMapGVR).Namespace("default")
+	exists := func(name string) bool {
+		_, err := configMaps.Get(context.Background(), name, metav1.GetOptions{})
+		return err == nil
+	}
+	reconcile := func() {
+		// The runtime resolves the resources from scratch at each
+		// reconciliation.
+		for _, resource := range rt.resources {
+			resource.observed = nil
+		}
+		require.Error(t, igr.reconcileResource(context.Background(), "job"))
+		assert.Equal(t, "RECREATING", igr.state.ResourceStates["job"].State)
+	}
+
+	// The dependents are deleted first, the resource isn't.
+	reconcile()
+	assert.True(t, exists("job"))
+	assert.True(t, exists("other"))
+	assert.False(t, exists("config"))
+	assert.False(t, exists("app"))
+	assert.Equal(t, []string{EventReasonResourceDeleted, EventReasonResourceDeleted}, eventReasons(recorder))
+
+	// The resource is deleted once its dependents are gone.
+	reconcile()
+	assert.False(t, exists("job"))
+	assert.True(t, exists("other"))
+	assert.Equal(t, []string{EventReasonResourceRecreated}, eventReasons(recorder))
+
+	// Dependents are deleted in reverse topological order.
+	var deleted []string
+	for _, action := range client.Actions() {
+		if action, ok := action.(k8stesting.DeleteAction); ok {
+			deleted = append(deleted, action.GetName())
+		}
+	}
+	assert.Equal(t, []string{"app", "config", "job"}, deleted)
+}
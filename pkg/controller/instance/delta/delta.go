@@ -15,8 +15,10 @@ package delta
 
 import (
 	"fmt"
+	"slices"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
 // Difference represents a single field-level difference between two objects.
@@ -31,6 +33,61 @@ type Difference struct {
 	Observed interface{} `json:"observed"`
 }
 
+// Option configures a comparison.
+type Option func(*options) error
+
+type options struct {
+	ignored []Path
+	schema  *spec.Schema
+}
+
+// WithIgnoredPaths ignores the differences found at the given field paths, and
+// below them. See ParsePath for the path syntax.
+func WithIgnoredPaths(paths ...string) Option {
+	return func(o *options) error {
+		for _, path := range paths {
+			p, err := ParsePath(path)
+			if err != nil {
+				return err
+			}
+			o.ignored = append(o.ignored, p)
+		}
+		return nil
+	}
+}
+
+// WithSchema compares lists according to the OpenAPI schema of the objects.
+// Lists of type "map" are compared by their x-kubernetes-list-map-keys and
+// lists of type "set" regardless of their order, so that the API server (or
+// another controller) reordering items doesn't cause differences. Added or
+// removed items are still differences. Other lists are compared by position.
+func WithSchema(schema *spec.Schema) Option {
+	return func(o *options) error {
+		o.schema = schema
+		return nil
+	}
+}
+
+func newOptions(opts []Option) (*options, error) {
+	o := &options{}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// isIgnored returns true if the given concrete path is ignored.
+func (o *options) isIgnored(path []segment) bool {
+	for _, ignored := range o.ignored {
+		if ignored.matchesPrefix(path) {
+			return true
+		}
+	}
+	return false
+}
+
 // Compare takes desired and observed unstructured objects and returns a list of
 // their differences. It performs a deep comparison while being aware of Kubernetes
 // metadata specifics. The comparison:
@@ -39,16 +96,23 @@ type Difference struct {
 // - Walks object trees in parallel to find actual value differences
 // - Builds path strings to precisely identify where differences occurs
 // - Handles type mismatches, nil values, and empty vs nil collections
-func Compare(desired, observed *unstructured.Unstructured) ([]Difference, error) {
+// - Skips the fields ignored with WithIgnoredPaths
+// - Compares lists by their merge keys when a schema is set with WithSchema
+func Compare(desired, observed *unstructured.Unstructured, opts ...Option) ([]Difference, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	desiredCopy := desired.DeepCopy()
 	observedCopy := observed.DeepCopy()
 
 	cleanMetadata(desiredCopy)
 	cleanMetadata(observedCopy)
 
-	var differences []Difference
-	walkCompare(desiredCopy.Object, observedCopy.Object, "", &differences)
-	return differences, nil
+	w := &walker{options: o}
+	w.walkCompare(desiredCopy.Object, observedCopy.Object, "", nil, o.schema)
+	return w.differences, nil
 }
 
 // ignoredMetadataFields are Kubernetes metadata fields that should not trigger updates.
@@ -92,14 +156,27 @@ func cleanMetadata(obj *unstructured.Unstructured) {
 	}
 }
 
+// walker holds the state of a comparison.
+type walker struct {
+	*options
+	differences []Difference
+}
+
 // walkCompare recursively compares desired and observed values, recording any
 // differences found. It handles different types appropriately:
 // - For maps: recursively compares all keys/values
 // - For slices: checks length and recursively compares elements
 // - For primitives: directly compares values
 //
-// Records a Difference if values don't match or are of different types.
-func walkCompare(desired, observed interface{}, path string, differences *[]Difference) {
+// Records a Difference if values don't match or are of different types. Ignored
+// paths are skipped along with all their children. schema is the schema of the
+// compared values, or nil if it is unknown.
+func (w *walker) walkCompare(desired, observed interface{}, path string, segments []segment, schema *spec.Schema) {
+	if w.isIgnored(segments) {
+		return
+	}
+
+	differences := &w.differences
 	switch d := desired.(type) {
 	case map[string]interface{}:
 		e, ok := observed.(map[string]interface{})
@@ -111,7 +188,7 @@ func walkCompare(desired, observed interface{}, path string, differences *[]Diff
 			})
 			return
 		}
-		walkMap(d, e, path, differences)
+		w.walkMap(d, e, path, segments, schema)
 
 	case []interface{}:
 		e, ok := observed.([]interface{})
@@ -123,7 +200,7 @@ func walkCompare(desired, observed interface{}, path string, differences *[]Diff
 			})
 			return
 		}
-		walkSlice(d, e, path, differences)
+		w.walkSlice(d, e, path, segments, schema)
 
 	default:
 		if desired != observed {
@@ -140,16 +217,20 @@ func walkCompare(desired, observed interface{}, path string, differences *[]Diff
 //
 // - If key missing in observed: records a difference
 // - If key exists: recursively compares values
-func walkMap(desired, observed map[string]interface{}, path string, differences *[]Difference) {
+func (w *walker) walkMap(desired, observed map[string]interface{}, path string, segments []segment, schema *spec.Schema) {
 	for k, desiredVal := range desired {
 		newPath := k
 		if path != "" {
 			newPath = fmt.Sprintf("%s.%s", path, k)
 		}
+		newSegments := append(slices.Clip(segments), keySegment(k))
 
 		observedVal, exists := observed[k]
 		if !exists && desiredVal != nil {
-			*differences = append(*differences, Difference{
+			if w.isIgnored(newSegments) {
+				continue
+			}
+			w.differences = append(w.differences, Difference{
 				Path:     newPath,
 				Observed: nil,
 				Desired:  desiredVal,
@@ -157,16 +238,53 @@ func walkMap(desired, observed map[string]interface{}, path string, differences
 			continue
 		}
 
-		walkCompare(desiredVal, observedVal, newPath, differences)
+		w.walkCompare(desiredVal, observedVal, newPath, newSegments, propertySchema(schema, k))
 	}
 }
 
 // walkSlice compares two slices recursively:
-// - If lengths differ: records entire slice as different
+// - For "map" and "set" lists: matches the desired items with the observed
+// items regardless of their order, and records the desired items missing
+// from the observed list. The list is set by kro, and updates replace it
+// entirely, so observed items missing from the desired list (e.g items
+// removed from the graph) record the entire slice as different
+// - For other lists, if lengths differ: records entire slice as different
 // - If lengths match: recursively compares elements
-func walkSlice(desired, observed []interface{}, path string, differences *[]Difference) {
+func (w *walker) walkSlice(desired, observed []interface{}, path string, segments []segment, schema *spec.Schema) {
+	items := itemsSchema(schema)
+	if t, _ := listType(schema); t == listTypeMap || t == listTypeSet {
+		if matches, ok := matchListItems(desired, observed, schema); ok {
+			matched := make([]bool, len(observed))
+			for i, j := range matches {
+				newPath := fmt.Sprintf("%s[%d]", path, i)
+				newSegments := append(slices.Clip(segments), indexSegment(i))
+				if j >= 0 {
+					matched[j] = true
+					w.walkCompare(desired[i], observed[j], newPath, newSegments, items)
+					continue
+				}
+				if w.isIgnored(newSegments) {
+					continue
+				}
+				w.differences = append(w.differences, Difference{
+					Path:     newPath,
+					Observed: nil,
+					Desired:  desired[i],
+				})
+			}
+			if slices.Contains(matched, false) {
+				w.differences = append(w.differences, Difference{
+					Path:     path,
+					Observed: observed,
+					Desired:  desired,
+				})
+			}
+			return
+		}
+	}
+
 	if len(desired) != len(observed) {
-		*differences = append(*differences, Difference{
+		w.differences = append(w.differences, Difference{
 			Path:     path,
 			Observed: observed,
 			Desired:  desired,
@@ -176,6 +294,6 @@ func walkSlice(desired, observed []interface{}, path string, differences *[]Diff
 
 	for i := range desired {
 		newPath := fmt.Sprintf("%s[%d]", path, i)
-		walkCompare(desired[i], observed[i], newPath, differences)
+		w.walkCompare(desired[i], observed[i], newPath, append(slices.Clip(segments), indexSegment(i)), items)
 	}
 }
@@ -18,6 +18,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
 func TestCompare_Simple(t *testing.T) {
@@ -458,3 +459,268 @@ func TestCompare_EmptyMaps(t *testing.T) {
 		})
 	}
 }
+
+func TestCompare_IgnoredPaths(t *testing.T) {
+	desired := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					"example.com/managed": "desired",
+					"team":                "desired",
+				},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":      "app",
+								"image":     "nginx:1.19",
+								"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+							},
+							map[string]interface{}{
+								"name":      "sidecar",
+								"image":     "envoy:1.0",
+								"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	observed := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					"example.com/managed": "observed",
+					"team":                "observed",
+				},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(5),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":      "app",
+								"image":     "nginx:1.18",
+								"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "2"}},
+							},
+							map[string]interface{}{
+								"name":      "sidecar",
+								"image":     "envoy:1.0",
+								"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "2"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	differences, err := Compare(desired, observed, WithIgnoredPaths(
+		"spec.replicas",
+		"spec.template.spec.containers[*].resources",
+		"metadata.annotations['example.com/managed']",
+	))
+	assert.NoError(t, err)
+
+	paths := make([]string, 0, len(differences))
+	for _, d := range differences {
+		paths = append(paths, d.Path)
+	}
+	assert.ElementsMatch(t, []string{
+		"metadata.annotations.team",
+		"spec.template.spec.containers[0].image",
+	}, paths)
+
+	_, err = Compare(desired, observed, WithIgnoredPaths("spec..replicas"))
+	assert.Error(t, err)
+}
+
+func TestCompare_ListTypes(t *testing.T) {
+	portSchema := spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"containerPort": {SchemaProps: spec.SchemaProps{Type: []string{"integer"}}},
+				"protocol":      {SchemaProps: spec.SchemaProps{Type: []string{"string"}, Default: "TCP"}},
+			},
+		},
+	}
+	containerSchema := spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"name":  {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+				"image": {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+				"ports": {
+					SchemaProps: spec.SchemaProps{
+						Type:  []string{"array"},
+						Items: &spec.SchemaOrArray{Schema: &portSchema},
+					},
+					VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+						"x-kubernetes-list-type":     "map",
+						"x-kubernetes-list-map-keys": []interface{}{"containerPort", "protocol"},
+					}},
+				},
+			},
+		},
+	}
+	schema := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"spec": {
+					SchemaProps: spec.SchemaProps{
+						Type: []string{"object"},
+						Properties: map[string]spec.Schema{
+							"containers": {
+								SchemaProps: spec.SchemaProps{
+									Type:  []string{"array"},
+									Items: &spec.SchemaOrArray{Schema: &containerSchema},
+								},
+								VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+									"x-kubernetes-list-type":     "map",
+									"x-kubernetes-list-map-keys": []interface{}{"name"},
+								}},
+							},
+							"finalizers": {
+								SchemaProps: spec.SchemaProps{
+									Type:  []string{"array"},
+									Items: &spec.SchemaOrArray{Schema: spec.StringProperty()},
+								},
+								VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+									"x-kubernetes-list-type": "set",
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	newObject := func(fields map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{"spec": fields}}
+	}
+
+	tests := []struct {
+		name     string
+		desired  *unstructured.Unstructured
+		observed *unstructured.Unstructured
+		want     []string
+	}{
+		{
+			name: "reordered map list items",
+			desired: newObject(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "nginx"},
+					map[string]interface{}{"name": "sidecar", "image": "envoy"},
+				},
+			}),
+			observed: newObject(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "sidecar", "image": "envoy"},
+					map[string]interface{}{"name": "app", "image": "nginx"},
+				},
+			}),
+		},
+		{
+			name: "removed map list items",
+			desired: newObject(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "nginx"},
+				},
+			}),
+			observed: newObject(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "sidecar", "image": "envoy"},
+					map[string]interface{}{"name": "app", "image": "nginx"},
+				},
+			}),
+			want: []string{"spec.containers"},
+		},
+		{
+			name: "changed and missing map list items",
+			desired: newObject(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "nginx:1.19"},
+					map[string]interface{}{"name": "sidecar", "image": "envoy"},
+				},
+			}),
+			observed: newObject(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "nginx:1.18"},
+				},
+			}),
+			want: []string{"spec.containers[0].image", "spec.containers[1]"},
+		},
+		{
+			name: "map keys with defaults",
+			desired: newObject(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{
+						"name": "app",
+						"ports": []interface{}{
+							map[string]interface{}{"containerPort": int64(8080)},
+							map[string]interface{}{"containerPort": int64(53), "protocol": "UDP"},
+						},
+					},
+				},
+			}),
+			observed: newObject(map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{
+						"name": "app",
+						"ports": []interface{}{
+							map[string]interface{}{"containerPort": int64(53), "protocol": "UDP"},
+							map[string]interface{}{"containerPort": int64(8080), "protocol": "TCP"},
+						},
+					},
+				},
+			}),
+		},
+		{
+			name: "set list",
+			desired: newObject(map[string]interface{}{
+				"finalizers": []interface{}{"a", "b", "c"},
+			}),
+			observed: newObject(map[string]interface{}{
+				"finalizers": []interface{}{"c", "a", "d"},
+			}),
+			want: []string{"spec.finalizers[1]", "spec.finalizers"},
+		},
+		{
+			name: "map list items without keys fall back to positions",
+			desired: newObject(map[string]interface{}{
+				"containers": []interface{}{"app"},
+			}),
+			observed: newObject(map[string]interface{}{
+				"containers": []interface{}{"app", "sidecar"},
+			}),
+			want: []string{"spec.containers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			differences, err := Compare(tt.desired, tt.observed, WithSchema(schema))
+			assert.NoError(t, err)
+
+			paths := make([]string, 0, len(differences))
+			for _, d := range differences {
+				paths = append(paths, d.Path)
+			}
+			assert.ElementsMatch(t, tt.want, paths)
+		})
+	}
+
+	// Without a schema, lists are compared by position.
+	differences, err := Compare(tests[0].desired, tests[0].observed)
+	assert.NoError(t, err)
+	assert.Len(t, differences, 4)
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package delta
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// segment is a single element of a field path. It is either a map key, a list
+// index, or a wildcard matching any list index.
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func keySegment(key string) segment {
+	return segment{key: key}
+}
+
+func indexSegment(index int) segment {
+	return segment{index: index, isIndex: true}
+}
+
+// matches returns true if the rule segment s matches the concrete segment
+// other.
+func (s segment) matches(other segment) bool {
+	switch {
+	case s.wildcard:
+		return other.isIndex
+	case s.isIndex:
+		return other.isIndex && s.index == other.index
+	default:
+		return !other.isIndex && s.key == other.key
+	}
+}
+
+// Path is a parsed field path, as accepted by WithIgnoredPaths.
+type Path []segment
+
+// ParsePath parses a field path. Paths use the same syntax as the paths
+// reported in a Difference, with a few additions:
+//
+//   - "spec.replicas" selects a map field.
+//   - "spec.containers[0].image" selects a list item by index.
+//   - "spec.containers[*].image" selects the field in every list item.
+//   - "metadata.annotations['example.com/key']" selects a map key that
+//     contains dots.
+//
+// A path also matches all the fields below it, e.g "spec.template" matches
+// "spec.template.spec.containers[0].image".
+func ParsePath(path string) (Path, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+
+	var p Path
+	i := 0
+	expectKey := true
+	for i < len(path) {
+		switch {
+		case path[i] == '[':
+			if expectKey {
+				return nil, fmt.Errorf("invalid path %q: empty field name", path)
+			}
+			seg, n, err := parseBracket(path[i:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", path, err)
+			}
+			p = append(p, seg)
+			i += n
+			expectKey = false
+		case path[i] == '.':
+			if expectKey {
+				return nil, fmt.Errorf("invalid path %q: empty field name", path)
+			}
+			i++
+			expectKey = true
+		default:
+			if !expectKey {
+				return nil, fmt.Errorf("invalid path %q: expected '.' or '[' at position %d", path, i)
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			p = append(p, keySegment(path[i:i+end]))
+			i += end
+			expectKey = false
+		}
+	}
+	if expectKey {
+		return nil, fmt.Errorf("invalid path %q: empty field name", path)
+	}
+	return p, nil
+}
+
+// parseBracket parses a bracket segment at the beginning of s, and returns the
+// segment and its length.
+func parseBracket(s string) (segment, int, error) {
+	// Quoted map key, which may contain dots and brackets.
+	if len(s) > 1 && (s[1] == '\'' || s[1] == '"') {
+		closing := strings.Index(s[2:], string(s[1])+"]")
+		if closing < 0 {
+			return segment{}, 0, fmt.Errorf("unterminated quoted key")
+		}
+		return keySegment(s[2 : 2+closing]), closing + 4, nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return segment{}, 0, fmt.Errorf("missing closing bracket")
+	}
+	inner := s[1:end]
+	if inner == "*" {
+		return segment{wildcard: true}, end + 1, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil || index < 0 {
+		return segment{}, 0, fmt.Errorf("invalid list index %q", inner)
+	}
+	return indexSegment(index), end + 1, nil
+}
+
+// matchesPrefix returns true if the path matches the beginning of the given
+// concrete path, i.e the concrete path is the path itself or one of its
+// children.
+func (p Path) matchesPrefix(concrete []segment) bool {
+	if len(p) > len(concrete) {
+		return false
+	}
+	for i, s := range p {
+		if !s.matches(concrete[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+var listIndexRegexp = regexp.MustCompile(`\[\d+\]`)
+
+// NormalizePath replaces the list indexes of a Difference path with wildcards,
+// e.g "spec.containers[0].image" becomes "spec.containers[*].image". This is
+// useful to aggregate differences, e.g in metrics.
+func NormalizePath(path string) string {
+	return listIndexRegexp.ReplaceAllString(path, "[*]")
+}
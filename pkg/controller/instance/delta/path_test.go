@@ -0,0 +1,87 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package delta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    Path
+		wantErr bool
+	}{
+		{
+			name: "simple field",
+			path: "spec.replicas",
+			want: Path{keySegment("spec"), keySegment("replicas")},
+		},
+		{
+			name: "list index",
+			path: "spec.containers[1].image",
+			want: Path{keySegment("spec"), keySegment("containers"), indexSegment(1), keySegment("image")},
+		},
+		{
+			name: "list wildcard",
+			path: "spec.containers[*]",
+			want: Path{keySegment("spec"), keySegment("containers"), {wildcard: true}},
+		},
+		{
+			name: "nested lists",
+			path: "spec.matrix[0][*]",
+			want: Path{keySegment("spec"), keySegment("matrix"), indexSegment(0), {wildcard: true}},
+		},
+		{
+			name: "quoted key",
+			path: "metadata.annotations['example.com/key'].x",
+			want: Path{keySegment("metadata"), keySegment("annotations"), keySegment("example.com/key"), keySegment("x")},
+		},
+		{
+			name: "double quoted key with brackets",
+			path: `metadata.labels["a[0]"]`,
+			want: Path{keySegment("metadata"), keySegment("labels"), keySegment("a[0]")},
+		},
+		{name: "empty", path: "", wantErr: true},
+		{name: "empty field", path: "spec..replicas", wantErr: true},
+		{name: "trailing dot", path: "spec.", wantErr: true},
+		{name: "leading bracket", path: "[0]", wantErr: true},
+		{name: "invalid index", path: "spec.containers[a]", wantErr: true},
+		{name: "negative index", path: "spec.containers[-1]", wantErr: true},
+		{name: "missing bracket", path: "spec.containers[0", wantErr: true},
+		{name: "unterminated quote", path: "metadata.annotations['a.b]", wantErr: true},
+		{name: "missing dot", path: "spec.containers[0]image", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePath(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	assert.Equal(t, "spec.containers[*].ports[*].name", NormalizePath("spec.containers[0].ports[12].name"))
+	assert.Equal(t, "spec.replicas", NormalizePath("spec.replicas"))
+}
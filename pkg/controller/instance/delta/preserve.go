@@ -0,0 +1,111 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package delta
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// PreserveIgnored copies the observed values of the paths ignored with
+// WithIgnoredPaths into the desired object. Updates replace the whole object, so without this, updating
+// a resource for an unrelated change would also revert the ignored fields
+// (e.g spec.replicas managed by an autoscaler) to their desired values.
+//
+// Ignored fields missing from the observed object are left untouched in the
+// desired object. When a schema is set with WithSchema, the items of "map"
+// and "set" lists are matched the same way Compare matches them.
+func PreserveIgnored(desired, observed *unstructured.Unstructured, opts ...Option) error {
+	o, err := newOptions(opts)
+	if err != nil {
+		return err
+	}
+	for _, path := range o.ignored {
+		preserve(desired.Object, observed.Object, path, o.schema)
+	}
+	return nil
+}
+
+// preserve copies the observed values matching path into desired. Missing
+// intermediate maps are created in desired, so that the observed value is
+// preserved even if the desired object doesn't set it.
+func preserve(desired map[string]interface{}, observed interface{}, path Path, schema *spec.Schema) {
+	observedMap, ok := observed.(map[string]interface{})
+	if !ok || len(path) == 0 || path[0].isIndex || path[0].wildcard {
+		return
+	}
+
+	key := path[0].key
+	schema = propertySchema(schema, key)
+	observedVal, exists := observedMap[key]
+	if !exists {
+		return
+	}
+	rest := path[1:]
+
+	if len(rest) == 0 {
+		desired[key] = runtime.DeepCopyJSONValue(observedVal)
+		return
+	}
+
+	switch o := observedVal.(type) {
+	case map[string]interface{}:
+		d, ok := desired[key].(map[string]interface{})
+		if !ok {
+			d = map[string]interface{}{}
+			desired[key] = d
+		}
+		preserve(d, o, rest, schema)
+	case []interface{}:
+		d, ok := desired[key].([]interface{})
+		if !ok {
+			return
+		}
+		preserveList(d, o, rest, schema)
+	}
+}
+
+// preserveList copies the observed values matching path into the items of
+// the desired list. Items are matched as described in matchListItems, and
+// selected by their index in the desired list.
+func preserveList(desired, observed []interface{}, path Path, schema *spec.Schema) {
+	matches, ok := matchListItems(desired, observed, schema)
+	if !ok {
+		matches, _ = matchListItems(desired, observed, nil)
+	}
+	items := itemsSchema(schema)
+
+	seg := path[0]
+	rest := path[1:]
+	for i, j := range matches {
+		if j < 0 || !seg.matches(indexSegment(i)) {
+			continue
+		}
+		if len(rest) == 0 {
+			desired[i] = runtime.DeepCopyJSONValue(observed[j])
+			continue
+		}
+		switch o := observed[j].(type) {
+		case map[string]interface{}:
+			if d, ok := desired[i].(map[string]interface{}); ok {
+				preserve(d, o, rest, items)
+			}
+		case []interface{}:
+			if d, ok := desired[i].([]interface{}); ok {
+				preserveList(d, o, rest, items)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package delta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestPreserveIgnored(t *testing.T) {
+	desired := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "nginx:1.19"},
+				},
+			},
+		},
+	}
+	observed := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": int64(5),
+				"containers": []interface{}{
+					map[string]interface{}{
+						"name":      "app",
+						"image":     "nginx:1.18",
+						"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "2"}},
+					},
+				},
+			},
+		},
+	}
+
+	err := PreserveIgnored(desired, observed, WithIgnoredPaths(
+		"spec.replicas",
+		"spec.containers[*].resources",
+		"spec.missing.field",
+	))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"replicas": int64(5),
+		"containers": []interface{}{
+			map[string]interface{}{
+				"name":      "app",
+				"image":     "nginx:1.19",
+				"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "2"}},
+			},
+		},
+	}, desired.Object["spec"])
+
+	assert.Error(t, PreserveIgnored(desired, observed, WithIgnoredPaths("spec.[0]")))
+}
+
+func TestPreserveIgnored_MapList(t *testing.T) {
+	schema := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Properties: map[string]spec.Schema{
+				"containers": {
+					SchemaProps: spec.SchemaProps{
+						Type: []string{"array"},
+					},
+					VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{
+						"x-kubernetes-list-type":     "map",
+						"x-kubernetes-list-map-keys": []interface{}{"name"},
+					}},
+				},
+			},
+		},
+	}
+	desired := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "nginx:1.19"},
+				map[string]interface{}{"name": "sidecar", "image": "envoy:1.0"},
+			},
+		},
+	}
+	observed := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "sidecar", "image": "envoy:2.0"},
+				map[string]interface{}{"name": "app", "image": "nginx:1.18"},
+			},
+		},
+	}
+
+	err := PreserveIgnored(desired, observed, WithIgnoredPaths("containers[1].image"), WithSchema(schema))
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "app", "image": "nginx:1.19"},
+		map[string]interface{}{"name": "sidecar", "image": "envoy:2.0"},
+	}, desired.Object["containers"])
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package delta
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	// listTypeExtension is the OpenAPI extension describing how a list is
+	// merged, see https://kubernetes.io/docs/reference/using-api/server-side-apply/#merge-strategy
+	listTypeExtension = "x-kubernetes-list-type"
+	// listMapKeysExtension is the OpenAPI extension listing the fields that
+	// identify the items of a "map" list.
+	listMapKeysExtension = "x-kubernetes-list-map-keys"
+
+	listTypeMap = "map"
+	listTypeSet = "set"
+)
+
+// propertySchema returns the schema of the given field of an object schema,
+// or nil if it is unknown.
+func propertySchema(s *spec.Schema, key string) *spec.Schema {
+	if s == nil {
+		return nil
+	}
+	if property, ok := s.Properties[key]; ok {
+		return &property
+	}
+	if s.AdditionalProperties != nil {
+		return s.AdditionalProperties.Schema
+	}
+	return nil
+}
+
+// itemsSchema returns the schema of the items of a list schema, or nil if it
+// is unknown.
+func itemsSchema(s *spec.Schema) *spec.Schema {
+	if s == nil || s.Items == nil {
+		return nil
+	}
+	if s.Items.Schema != nil {
+		return s.Items.Schema
+	}
+	if len(s.Items.Schemas) > 0 {
+		return &s.Items.Schemas[0]
+	}
+	return nil
+}
+
+// listType returns the list type of a list schema, and the map keys for "map"
+// lists. Lists without a schema or list type are atomic, and compared by
+// position.
+func listType(s *spec.Schema) (string, []string) {
+	if s == nil {
+		return "", nil
+	}
+	t, _ := s.Extensions.GetString(listTypeExtension)
+	if t != listTypeMap {
+		return t, nil
+	}
+	keys, _ := s.Extensions.GetStringSlice(listMapKeysExtension)
+	if len(keys) == 0 {
+		return "", nil
+	}
+	return t, keys
+}
+
+// matchListItems pairs the items of the desired list with the items of the
+// observed list, and returns the index of the observed item matching each
+// desired item, or -1 if there is none.
+//
+// Items of "map" lists are matched by their map keys, items of "set" lists by
+// value, and items of any other list by position. ok is false if the items
+// don't conform to the list type, in which case the lists should be compared
+// by position.
+func matchListItems(desired, observed []interface{}, s *spec.Schema) (matches []int, ok bool) {
+	matches = make([]int, len(desired))
+	t, keys := listType(s)
+	switch t {
+	case listTypeMap:
+		items := itemsSchema(s)
+		observedKeys := make(map[string]int, len(observed))
+		for j := len(observed) - 1; j >= 0; j-- {
+			key, ok := listMapKey(observed[j], keys, items)
+			if !ok {
+				return nil, false
+			}
+			observedKeys[key] = j
+		}
+		for i := range desired {
+			key, ok := listMapKey(desired[i], keys, items)
+			if !ok {
+				return nil, false
+			}
+			j, found := observedKeys[key]
+			if !found {
+				j = -1
+			}
+			matches[i] = j
+		}
+	case listTypeSet:
+		for i := range desired {
+			matches[i] = -1
+			for j := range observed {
+				if reflect.DeepEqual(desired[i], observed[j]) {
+					matches[i] = j
+					break
+				}
+			}
+		}
+	default:
+		for i := range desired {
+			matches[i] = -1
+			if i < len(observed) {
+				matches[i] = i
+			}
+		}
+	}
+	return matches, true
+}
+
+// listMapKey returns the identity of an item of a "map" list. Key fields
+// missing from the item take their default value, if any, as the API server
+// would set it (e.g the protocol of container ports).
+func listMapKey(item interface{}, keys []string, items *spec.Schema) (string, bool) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value, exists := m[key]
+		if !exists {
+			if property := propertySchema(items, key); property != nil {
+				value = property.Default
+			}
+		}
+		values[i] = value
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Event reasons recorded on instances. Events go through the manager's event
+// broadcaster, which aggregates and rate limits similar events per object. So
+// reasons that can fire on every requeue (e.g exceeded timeouts) collapse
+// into a single event with an increasing count instead of flooding the API.
+const (
+	EventReasonResourceCreated      = "ResourceCreated"
+	EventReasonResourceUpdated      = "ResourceUpdated"
+	EventReasonResourceDeleted      = "ResourceDeleted"
+	EventReasonResourceAdopted      = "ResourceAdopted"
+	EventReasonResourceRecreated    = "ResourceRecreated"
+	EventReasonResourceConflict     = "ResourceConflict"
+	EventReasonResourceCreateFailed = "ResourceCreateFailed"
+	EventReasonResourceUpdateFailed = "ResourceUpdateFailed"
+	EventReasonResourceDeleteFailed = "ResourceDeleteFailed"
+	EventReasonWaitingForReadiness  = "WaitingForReadiness"
+	EventReasonReadyTimeout         = "ReadyTimeoutExceeded"
+	EventReasonDeleteTimeout        = "DeleteTimeoutExceeded"
+	EventReasonResourceAbandoned    = "ResourceAbandoned"
+	EventReasonEvaluationFailed     = "EvaluationFailed"
+	EventReasonImpersonationFailed  = "ImpersonationFailed"
+	EventReasonMissingPermissions   = "MissingPermissions"
+	EventReasonStalled              = "Stalled"
+)
+
+// recordEvent records a normal event on the instance.
+func (igr *instanceGraphReconciler) recordEvent(reason, messageFmt string, args ...interface{}) {
+	recordEvent(igr.recorder, igr.runtime.GetInstance(), corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// recordWarning records a warning event on the instance.
+func (igr *instanceGraphReconciler) recordWarning(reason, messageFmt string, args ...interface{}) {
+	recordEvent(igr.recorder, igr.runtime.GetInstance(), corev1.EventTypeWarning, reason, messageFmt, args...)
+}
+
+// recordEvent records an event if a recorder is configured. Controllers
+// created without a recorder silently skip events.
+func recordEvent(recorder record.EventRecorder, obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder == nil || obj == nil {
+		return
+	}
+	recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+}
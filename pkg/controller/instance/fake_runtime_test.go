@@ -0,0 +1,174 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph/variable"
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/runtime"
+)
+
+var (
+	testInstanceGVR  = schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "apps"}
+	testConfigMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+)
+
+// fakeRuntime is a runtime.Interface serving static, resolved resources.
+type fakeRuntime struct {
+	instance  *unstructured.Unstructured
+	order     []string
+	resources map[string]*fakeResource
+}
+
+// fakeResource is a resource of the fake runtime, and its descriptor.
+type fakeResource struct {
+	desired  *unstructured.Unstructured
+	observed *unstructured.Unstructured
+	// ready is the readiness reported once the resource is observed.
+	ready bool
+	// dependencies are the resources the resource depends on.
+	dependencies  []string
+	adoption      v1alpha1.AdoptionPolicy
+	update        v1alpha1.UpdatePolicy
+	readyTimeout  time.Duration
+	deleteTimeout time.Duration
+}
+
+var _ runtime.Interface = &fakeRuntime{}
+
+func newFakeRuntime(instance *unstructured.Unstructured) *fakeRuntime {
+	return &fakeRuntime{instance: instance, resources: map[string]*fakeResource{}}
+}
+
+// addResource adds a resource, in topological order.
+func (r *fakeRuntime) addResource(id string, resource *fakeResource) {
+	r.order = append(r.order, id)
+	r.resources[id] = resource
+}
+
+func (r *fakeRuntime) Synchronize() (bool, error) { return false, nil }
+func (r *fakeRuntime) TopologicalOrder() []string { return r.order }
+func (r *fakeRuntime) ResourceDescriptor(id string) runtime.ResourceDescriptor {
+	return r.resources[id]
+}
+
+func (r *fakeRuntime) GetResource(id string) (*unstructured.Unstructured, runtime.ResourceState) {
+	resource := r.resources[id]
+	if resource.observed != nil {
+		return resource.observed, runtime.ResourceStateResolved
+	}
+	return resource.desired.DeepCopy(), runtime.ResourceStateResolved
+}
+
+func (r *fakeRuntime) SetResource(id string, obj *unstructured.Unstructured) {
+	r.resources[id].observed = obj
+}
+
+func (r *fakeRuntime) GetInstance() *unstructured.Unstructured    { return r.instance }
+func (r *fakeRuntime) SetInstance(obj *unstructured.Unstructured) { r.instance = obj }
+
+func (r *fakeRuntime) IsResourceReady(id string) (bool, string, error) {
+	if r.resources[id].ready {
+		return true, "", nil
+	}
+	return false, "readyWhen expressions are not satisfied", nil
+}
+
+func (r *fakeRuntime) WantToCreateResource(string) (bool, error) { return true, nil }
+func (r *fakeRuntime) IgnoreResource(string)                     {}
+
+func (r *fakeResource) GetGroupVersionResource() schema.GroupVersionResource {
+	return testConfigMapGVR
+}
+func (r *fakeResource) GetVariables() []*variable.ResourceField          { return nil }
+func (r *fakeResource) GetDependencies() []string                        { return r.dependencies }
+func (r *fakeResource) GetReadyWhenExpressions() []string                { return nil }
+func (r *fakeResource) GetIncludeWhenExpressions() []string              { return nil }
+func (r *fakeResource) IsNamespaced() bool                               { return true }
+func (r *fakeResource) GetAdoptionPolicy() v1alpha1.AdoptionPolicy       { return r.adoption }
+func (r *fakeResource) GetIgnoreDifferences() []string                   { return nil }
+func (r *fakeResource) GetUpdatePolicy() v1alpha1.UpdatePolicy           { return r.update }
+func (r *fakeResource) GetHealthCheckPolicy() v1alpha1.HealthCheckPolicy { return "" }
+func (r *fakeResource) GetHealthCheckExpressions() []string              { return nil }
+func (r *fakeResource) GetReadyTimeout() time.Duration                   { return r.readyTimeout }
+func (r *fakeResource) GetDeleteTimeout() time.Duration                  { return r.deleteTimeout }
+func (r *fakeResource) GetSchema() *spec.Schema                          { return nil }
+
+// newTestInstance returns an instance of the test ResourceGraphDefinition.
+func newTestInstance(uid types.UID) *unstructured.Unstructured {
+	instance := &unstructured.Unstructured{}
+	instance.SetAPIVersion("kro.run/v1alpha1")
+	instance.SetKind("App")
+	instance.SetNamespace("default")
+	instance.SetName("test")
+	instance.SetUID(uid)
+	return instance
+}
+
+// newTestConfigMap returns a ConfigMap holding data.
+func newTestConfigMap(name string, data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"data":       data,
+	}}
+}
+
+// ownedBy labels obj as owned by the instance with the given UID.
+func ownedBy(obj *unstructured.Unstructured, uid types.UID) *unstructured.Unstructured {
+	obj.SetLabels(map[string]string{
+		metadata.OwnedLabel:      "true",
+		metadata.InstanceIDLabel: string(uid),
+	})
+	return obj
+}
+
+// newTestReconciler returns a reconciler of the runtime instance, whose
+// client serves objects.
+func newTestReconciler(rt *fakeRuntime, objects ...k8sruntime.Object) (*instanceGraphReconciler, *dynamicfake.FakeDynamicClient, *record.FakeRecorder) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(), map[schema.GroupVersionResource]string{
+		testInstanceGVR:  "AppList",
+		testConfigMapGVR: "ConfigMapList",
+	}, objects...)
+	recorder := record.NewFakeRecorder(100)
+	labeler := metadata.NewInstanceLabeler(rt.GetInstance())
+	subResourcesLabeler, _ := labeler.Merge(metadata.NewKROMetaLabeler())
+	igr := &instanceGraphReconciler{
+		log:                         logr.Discard(),
+		gvr:                         testInstanceGVR,
+		client:                      client,
+		runtime:                     rt,
+		instanceLabeler:             labeler,
+		instanceSubResourcesLabeler: subResourcesLabeler,
+		reconcileConfig:             ReconcileConfig{DefaultRequeueDuration: 3 * time.Second},
+		state:                       newInstanceState(),
+		recorder:                    recorder,
+		readiness:                   newReadinessTracker(),
+		paused:                      newPausedInstances(testInstanceGVR),
+	}
+	return igr, client, recorder
+}
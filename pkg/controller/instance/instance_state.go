@@ -19,6 +19,15 @@ const (
 	InstanceStateActive     = "ACTIVE"
 	InstanceStateDeleting   = "DELETING"
 	InstanceStateError      = "ERROR"
+	InstanceStateDegraded   = "DEGRADED"
+	InstanceStatePaused     = "PAUSED"
+)
+
+const (
+	// ResourceStateAbandoned is the state of resources whose deletion exceeded
+	// their delete timeout and were abandoned, see
+	// metadata.AbandonOnDeleteTimeoutAnnotation.
+	ResourceStateAbandoned = "ABANDONED"
 )
 
 // newInstanceState creates a new InstanceState with initialized fields
@@ -46,4 +55,25 @@ type InstanceState struct {
 	ResourceStates map[string]*ResourceState
 	// Any error encountered during reconciliation
 	ReconcileErr error
+	// AccessReviewed is true when the pre-flight access review ran
+	AccessReviewed bool
+	// MissingPermissions lists the permissions the execution identity lacks
+	MissingPermissions []string
+	// AccessReviewErr captures any error encountered while reviewing access
+	AccessReviewErr error
+	// Degradation is set when a resource exceeded its readiness or deletion
+	// timeout
+	Degradation *Degradation
+	// PausedReason is set when the reconciliation of the instance is paused
+	PausedReason string
+	// Plan records the changes of dry-run reconciliations
+	Plan *Plan
+}
+
+// Degradation describes why an instance is degraded.
+type Degradation struct {
+	// Reason is a CamelCase reason, e.g ReadyTimeoutExceeded
+	Reason string
+	// Message names the resource and what it is waiting for
+	Message string
 }
@@ -27,6 +27,12 @@ const (
 	MetricImpersonationErrors = "controller_impersonation_errors_total"
 	// MetricImpersonationDuration tracks the duration of impersonation operations
 	MetricImpersonationDuration = "controller_impersonation_duration_seconds"
+	// MetricResourceUpdatePathsTotal is the total number of field differences
+	// that triggered a resource update, by field path
+	MetricResourceUpdatePathsTotal = "instance_resource_update_paths_total"
+	// MetricPausedInstances is the number of instances whose reconciliation
+	// is paused
+	MetricPausedInstances = "instance_paused"
 )
 
 var (
@@ -54,6 +60,22 @@ var (
 		},
 		[]string{"namespace", "service_account"},
 	)
+
+	resourceUpdatePathsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: MetricResourceUpdatePathsTotal,
+			Help: "Total number of field differences that triggered a resource update, list indexes are replaced by [*]",
+		},
+		[]string{"gvr", "resource_id", "path"},
+	)
+
+	pausedInstancesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: MetricPausedInstances,
+			Help: "Number of instances whose reconciliation is paused, by instance GVR",
+		},
+		[]string{"gvr"},
+	)
 )
 
 func recordImpersonateError(namespace, sa string, category errorCategory) {
@@ -65,5 +87,7 @@ func init() {
 		impersonationTotal,
 		impersonationErrors,
 		impersonationDuration,
+		resourceUpdatePathsTotal,
+		pausedInstancesGauge,
 	)
 }
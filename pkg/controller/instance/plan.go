@@ -0,0 +1,165 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/pkg/controller/instance/delta"
+)
+
+// PlanAction is the change a reconciliation would make to a resource.
+type PlanAction string
+
+const (
+	// PlanActionCreate creates the resource.
+	PlanActionCreate PlanAction = "Create"
+	// PlanActionUpdate updates the resource in place.
+	PlanActionUpdate PlanAction = "Update"
+	// PlanActionAdopt adopts an existing object that isn't owned by the
+	// instance.
+	PlanActionAdopt PlanAction = "Adopt"
+	// PlanActionRecreate deletes and re-creates the resource.
+	PlanActionRecreate PlanAction = "Recreate"
+	// PlanActionDelete deletes the resource.
+	PlanActionDelete PlanAction = "Delete"
+	// PlanActionNone leaves the resource unchanged.
+	PlanActionNone PlanAction = "None"
+	// PlanActionSkip doesn't manage the resource, because its includeWhen
+	// expressions evaluated to false.
+	PlanActionSkip PlanAction = "Skip"
+	// PlanActionUnknown is used for resources whose template depends on
+	// values that are only known once other changes are applied.
+	PlanActionUnknown PlanAction = "Unknown"
+)
+
+// Plan lists the changes a reconciliation of an instance would make to its
+// resources, in the order they would be made.
+type Plan struct {
+	Resources []PlannedChange `json:"resources"`
+}
+
+// PlannedChange is the change a reconciliation would make to a resource.
+type PlannedChange struct {
+	// ID is the resource ID in the ResourceGraphDefinition.
+	ID string `json:"id"`
+	// Kind, Name and Namespace identify the object, when its template could
+	// be resolved.
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// Action is the change made to the resource.
+	Action PlanAction `json:"action"`
+	// Reason explains the action, if needed.
+	Reason string `json:"reason,omitempty"`
+	// Differences lists the fields changed by updates.
+	Differences []delta.Difference `json:"differences,omitempty"`
+}
+
+// Plan computes the changes a reconciliation of the instance would make to
+// its resources, without applying them. Creates and updates are sent to the
+// API server with server-side dry-run, so admission and validation errors are
+// reported as they would be by a real reconciliation. Instances being deleted
+// are planned for deletion.
+//
+// The instance doesn't have to exist, in which case all of its resources are
+// planned as if it was being created.
+func (c *Controller) Plan(ctx context.Context, instance *unstructured.Unstructured) (*Plan, error) {
+	log := c.log.WithValues("namespace", instance.GetNamespace(), "name", instance.GetName())
+	igr, err := c.newInstanceGraphReconciler(ctx, log, instance)
+	if err != nil {
+		return nil, err
+	}
+	if err := igr.plan(ctx); err != nil {
+		return nil, err
+	}
+	return igr.state.Plan, nil
+}
+
+// plan reconciles, or deletes, the instance in dry-run mode and records the
+// changes in the instance state plan.
+func (igr *instanceGraphReconciler) plan(ctx context.Context) error {
+	igr.dryRun = true
+	// Nothing happens during a plan, so there is nothing to record either.
+	igr.recorder = nil
+	igr.state.Plan = &Plan{Resources: []PlannedChange{}}
+
+	if !igr.runtime.GetInstance().GetDeletionTimestamp().IsZero() {
+		return igr.handleInstanceDeletion(ctx)
+	}
+	return igr.reconcileInstance(ctx)
+}
+
+// planInstance is the reconciliation of instances with the
+// metadata.ReconcilePlan annotation value. The plan is written in the
+// instance status and refreshed periodically, but never applied.
+func (igr *instanceGraphReconciler) planInstance(ctx context.Context) error {
+	if err := igr.plan(ctx); err != nil {
+		return err
+	}
+	return igr.pausedRequeue()
+}
+
+// planChange records the change made to a resource in the plan, if the
+// instance is being planned.
+func (igr *instanceGraphReconciler) planChange(
+	resourceID string,
+	obj *unstructured.Unstructured,
+	action PlanAction,
+	reason string,
+	differences []delta.Difference,
+) {
+	if igr.state.Plan == nil {
+		return
+	}
+	change := PlannedChange{
+		ID:          resourceID,
+		Action:      action,
+		Reason:      reason,
+		Differences: differences,
+	}
+	if obj != nil {
+		change.Kind = obj.GetKind()
+		change.Name = obj.GetName()
+		change.Namespace = obj.GetNamespace()
+	}
+	igr.state.Plan.Resources = append(igr.state.Plan.Resources, change)
+}
+
+// dryRunOption returns the DryRun option of the create and update requests.
+func (igr *instanceGraphReconciler) dryRunOption() []string {
+	if igr.dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// planStatus converts the plan to the unstructured content of the instance
+// status.
+func planStatus(plan *Plan) (map[string]interface{}, error) {
+	b, err := json.Marshal(plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	var status map[string]interface{}
+	if err := json.Unmarshal(b, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal plan: %w", err)
+	}
+	return status, nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/controller/instance/delta"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// dryRunClient simulates server-side dry-run on top of the fake dynamic
+// client, which ignores the DryRun option: dry-run creates and updates are
+// answered without reaching the fake client, so any write recorded by the
+// fake client is a real one.
+type dryRunClient struct {
+	dynamic.Interface
+	// rejected holds the errors of the dry-run updates, by object name.
+	rejected map[string]error
+}
+
+func (c *dryRunClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &dryRunResource{NamespaceableResourceInterface: c.Interface.Resource(gvr), rejected: c.rejected}
+}
+
+type dryRunResource struct {
+	dynamic.NamespaceableResourceInterface
+	rejected map[string]error
+}
+
+func (r *dryRunResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &dryRunNamespacedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(namespace), rejected: r.rejected}
+}
+
+type dryRunNamespacedResource struct {
+	dynamic.ResourceInterface
+	rejected map[string]error
+}
+
+func (r *dryRunNamespacedResource) Create(
+	ctx context.Context,
+	obj *unstructured.Unstructured,
+	options metav1.CreateOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	if slices.Contains(options.DryRun, metav1.DryRunAll) {
+		return obj.DeepCopy(), nil
+	}
+	return r.ResourceInterface.Create(ctx, obj, options, subresources...)
+}
+
+func (r *dryRunNamespacedResource) Update(
+	ctx context.Context,
+	obj *unstructured.Unstructured,
+	options metav1.UpdateOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	if slices.Contains(options.DryRun, metav1.DryRunAll) {
+		if err := r.rejected[obj.GetName()]; err != nil {
+			return nil, err
+		}
+		return obj.DeepCopy(), nil
+	}
+	return r.ResourceInterface.Update(ctx, obj, options, subresources...)
+}
+
+// writes returns the verbs of the requests made to the fake client that
+// aren't reads.
+func writes(client *dynamicfake.FakeDynamicClient) []string {
+	var verbs []string
+	for _, action := range client.Actions() {
+		if verb := action.GetVerb(); verb != "get" && verb != "list" && verb != "watch" {
+			verbs = append(verbs, verb+" "+action.GetResource().Resource)
+		}
+	}
+	return verbs
+}
+
+// planActions returns the planned action of each resource, by resource ID.
+func planActions(plan *Plan) map[string]PlanAction {
+	actions := make(map[string]PlanAction)
+	for _, change := range plan.Resources {
+		actions[change.ID] = change.Action
+	}
+	return actions
+}
+
+func TestPlan(t *testing.T) {
+	instance := newTestInstance("uid")
+	rt := newFakeRuntime(instance)
+	rt.addResource("created", &fakeResource{desired: newTestConfigMap("created", map[string]interface{}{"key": "value"})})
+	rt.addResource("updated", &fakeResource{desired: newTestConfigMap("updated", map[string]interface{}{"key": "new"})})
+	rt.addResource("unchanged", &fakeResource{desired: newTestConfigMap("unchanged", map[string]interface{}{"key": "value"})})
+	rt.addResource("adopted", &fakeResource{
+		desired:  newTestConfigMap("adopted", map[string]interface{}{"key": "value"}),
+		adoption: v1alpha1.AdoptionPolicyAdopt,
+	})
+	rt.addResource("recreated", &fakeResource{
+		desired: newTestConfigMap("recreated", map[string]interface{}{"key": "new"}),
+		update:  v1alpha1.UpdatePolicyRecreate,
+	})
+	rt.addResource("createOnly", &fakeResource{
+		desired: newTestConfigMap("create-only", map[string]interface{}{"key": "new"}),
+		update:  v1alpha1.UpdatePolicyCreateOnly,
+	})
+
+	igr, client, _ := newTestReconciler(rt,
+		instance.DeepCopy(),
+		ownedBy(newTestConfigMap("updated", map[string]interface{}{"key": "old"}), instance.GetUID()),
+		ownedBy(newTestConfigMap("unchanged", map[string]interface{}{"key": "value"}), instance.GetUID()),
+		newTestConfigMap("adopted", map[string]interface{}{"key": "value"}),
+		ownedBy(newTestConfigMap("recreated", map[string]interface{}{"key": "old"}), instance.GetUID()),
+		ownedBy(newTestConfigMap("create-only", map[string]interface{}{"key": "old"}), instance.GetUID()),
+	)
+	igr.client = &dryRunClient{Interface: igr.client, rejected: map[string]error{
+		"recreated": apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "recreated", field.ErrorList{
+			field.Invalid(field.NewPath("data"), nil, apimachineryvalidation.FieldImmutableErrorMsg),
+		}),
+	}}
+
+	require.NoError(t, igr.plan(context.Background()))
+	assert.Empty(t, writes(client))
+	assert.Equal(t, map[string]PlanAction{
+		"created":    PlanActionCreate,
+		"updated":    PlanActionUpdate,
+		"unchanged":  PlanActionNone,
+		"adopted":    PlanActionAdopt,
+		"recreated":  PlanActionRecreate,
+		"createOnly": PlanActionNone,
+	}, planActions(igr.state.Plan))
+
+	// Changes are listed in the order they would be made, with the fields
+	// they change.
+	ids := make([]string, 0, len(igr.state.Plan.Resources))
+	for _, change := range igr.state.Plan.Resources {
+		ids = append(ids, change.ID)
+	}
+	assert.Equal(t, rt.order, ids)
+	updated := igr.state.Plan.Resources[1]
+	assert.Equal(t, PlannedChange{
+		ID:        "updated",
+		Kind:      "ConfigMap",
+		Name:      "updated",
+		Namespace: "default",
+		Action:    PlanActionUpdate,
+		Differences: []delta.Difference{
+			{Path: "data.key", Desired: "new", Observed: "old"},
+		},
+	}, updated)
+	assert.Contains(t, igr.state.Plan.Resources[4].Reason, "field is immutable")
+
+	// The instance is left untouched, without finalizer.
+	stored, err := client.Resource(testInstanceGVR).Namespace("default").Get(context.Background(), "test", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, stored.GetFinalizers())
+}
+
+func TestPlanDeletion(t *testing.T) {
+	instance := newTestInstance("uid")
+	instance.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	require.NoError(t, metadata.SetInstanceFinalizerUnstructured(instance))
+
+	rt := newFakeRuntime(instance)
+	rt.addResource("owned", &fakeResource{desired: newTestConfigMap("owned", nil)})
+	rt.addResource("deleting", &fakeResource{desired: newTestConfigMap("deleting", nil)})
+	rt.addResource("foreign", &fakeResource{desired: newTestConfigMap("foreign", nil)})
+	rt.addResource("missing", &fakeResource{desired: newTestConfigMap("missing", nil)})
+
+	deleting := ownedBy(newTestConfigMap("deleting", nil), instance.GetUID())
+	deleting.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	deleting.SetFinalizers([]string{"example.com/cleanup"})
+	igr, client, _ := newTestReconciler(rt,
+		instance.DeepCopy(),
+		ownedBy(newTestConfigMap("owned", nil), instance.GetUID()),
+		deleting,
+		newTestConfigMap("foreign", nil),
+	)
+
+	require.NoError(t, igr.plan(context.Background()))
+	assert.Empty(t, writes(client))
+	// Objects that aren't owned, or already gone, are not part of the plan.
+	assert.Equal(t, []PlannedChange{
+		{ID: "deleting", Kind: "ConfigMap", Name: "deleting", Namespace: "default", Action: PlanActionDelete, Reason: "deletion in progress"},
+		{ID: "owned", Kind: "ConfigMap", Name: "owned", Namespace: "default", Action: PlanActionDelete},
+	}, igr.state.Plan.Resources)
+}
+
+func TestReconcilePlannedInstance(t *testing.T) {
+	instance := newTestInstance("uid")
+	instance.SetAnnotations(map[string]string{metadata.ReconcileAnnotation: metadata.ReconcilePlan})
+	rt := newFakeRuntime(instance)
+	rt.addResource("created", &fakeResource{desired: newTestConfigMap("created", map[string]interface{}{"key": "value"})})
+	igr, client, _ := newTestReconciler(rt, instance.DeepCopy())
+	igr.client = &dryRunClient{Interface: igr.client}
+
+	// Only the plan is written, in the instance status.
+	assert.Error(t, igr.reconcile(context.Background()))
+	assert.Equal(t, []string{"update apps"}, writes(client))
+	assert.Equal(t, InstanceStatePaused, igr.state.State)
+	assert.Equal(t, map[string]PlanAction{"created": PlanActionCreate}, planActions(igr.state.Plan))
+
+	stored, err := client.Resource(testInstanceGVR).Namespace("default").Get(context.Background(), "test", metav1.GetOptions{})
+	require.NoError(t, err)
+	actions, _, err := unstructured.NestedSlice(stored.Object, "status", "plan", "resources")
+	require.NoError(t, err)
+	assert.Len(t, actions, 1)
+}
+
+func TestReconcileDeletesPausedInstances(t *testing.T) {
+	for _, mode := range []string{metadata.ReconcilePaused, metadata.ReconcilePlan} {
+		t.Run(mode, func(t *testing.T) {
+			instance := newTestInstance("uid")
+			instance.SetAnnotations(map[string]string{metadata.ReconcileAnnotation: mode})
+			instance.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+			require.NoError(t, metadata.SetInstanceFinalizerUnstructured(instance))
+
+			rt := newFakeRuntime(instance)
+			rt.addResource("owned", &fakeResource{desired: newTestConfigMap("owned", nil)})
+			igr, client, _ := newTestReconciler(rt, instance.DeepCopy(), ownedBy(newTestConfigMap("owned", nil), instance.GetUID()))
+
+			// The resources are deleted first, then the instance finalizer is
+			// removed.
+			assert.ErrorContains(t, igr.reconcile(context.Background()), "resource deletion in progress")
+			assert.Equal(t, InstanceStateDeleting, igr.state.State)
+			assert.Contains(t, writes(client), "delete configmaps")
+
+			igr.state = newInstanceState()
+			require.NoError(t, igr.reconcile(context.Background()))
+			hasFinalizer, err := metadata.HasInstanceFinalizerUnstructured(rt.GetInstance())
+			require.NoError(t, err)
+			assert.False(t, hasFinalizer)
+		})
+	}
+}
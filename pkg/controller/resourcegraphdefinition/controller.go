@@ -15,8 +15,11 @@ package resourcegraphdefinition
 
 import (
 	"context"
+	"sync"
 
 	"github.com/go-logr/logr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlrtcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
@@ -33,18 +36,49 @@ import (
 //+kubebuilder:rbac:groups=kro.run,resources=resourcegraphdefinitions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kro.run,resources=resourcegraphdefinitions/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kro.run,resources=resourcegraphdefinitions/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kro.run,resources=resourcegraphdefinitionrevisions,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews;subjectaccessreviews,verbs=create
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingadmissionpolicies;validatingadmissionpolicybindings,verbs=get;list
 
 // ResourceGraphDefinitionReconciler reconciles a ResourceGraphDefinition object
 type ResourceGraphDefinitionReconciler struct {
 	allowCRDDeletion bool
 
-	// Client and instanceLogger are set with SetupWithManager
+	// Client, instanceLogger and the event recorders are set with SetupWithManager
 
 	client.Client
 	instanceLogger logr.Logger
+	// recorder records events on ResourceGraphDefinitions.
+	recorder record.EventRecorder
+	// instanceRecorder records events on instances, it is shared by all the
+	// micro controllers.
+	instanceRecorder record.EventRecorder
+	// elected is closed once the manager is elected leader. When instances are
+	// sharded, every replica serves the graphs of the ResourceGraphDefinitions,
+	// but only the leader reconciles them and drives rollouts.
+	elected <-chan struct{}
 
 	clientSet  *kroclient.Set
 	crdManager kroclient.CRDClient
+	// impersonationCache is the impersonated clients cache shared by all the
+	// micro controllers.
+	impersonationCache *kroclient.ImpersonationCache
+	// serviceAccountAuthorizer authorizes the service accounts requested by
+	// instances, it is shared by all the micro controllers.
+	serviceAccountAuthorizer *kroclient.ServiceAccountAuthorizer
+	// resourceCache is the resources cache shared by all the micro
+	// controllers, resources are read live when it is nil.
+	resourceCache *kroclient.ResourceCache
+	// serviceAccounts tracks the last seen DefaultServiceAccounts of each
+	// ResourceGraphDefinition, to invalidate cached clients when they change.
+	serviceAccounts sync.Map
+	// revisions holds the graphRevisions of each ResourceGraphDefinition, for
+	// staged rollouts.
+	revisions sync.Map
+	// startup tracks the ResourceGraphDefinitions reconciled since the
+	// controller started, for the readiness check.
+	startup startupTracker
 
 	metadataLabeler         metadata.Labeler
 	rgBuilder               *graph.Builder
@@ -57,18 +91,28 @@ func NewResourceGraphDefinitionReconciler(
 	allowCRDDeletion bool,
 	dynamicController *dynamiccontroller.DynamicController,
 	builder *graph.Builder,
+	impersonationCache *kroclient.ImpersonationCache,
+	serviceAccountAuthorizer *kroclient.ServiceAccountAuthorizer,
+	resourceCache *kroclient.ResourceCache,
 	maxConcurrentReconciles int,
 ) *ResourceGraphDefinitionReconciler {
 	crdWrapper := clientSet.CRD(kroclient.CRDWrapperConfig{})
 
+	if impersonationCache == nil {
+		impersonationCache = kroclient.NewImpersonationCache(clientSet, kroclient.DefaultImpersonationCacheSize)
+	}
+
 	return &ResourceGraphDefinitionReconciler{
-		clientSet:               clientSet,
-		allowCRDDeletion:        allowCRDDeletion,
-		crdManager:              crdWrapper,
-		dynamicController:       dynamicController,
-		metadataLabeler:         metadata.NewKROMetaLabeler(),
-		rgBuilder:               builder,
-		maxConcurrentReconciles: maxConcurrentReconciles,
+		clientSet:                clientSet,
+		impersonationCache:       impersonationCache,
+		serviceAccountAuthorizer: serviceAccountAuthorizer,
+		resourceCache:            resourceCache,
+		allowCRDDeletion:         allowCRDDeletion,
+		crdManager:               crdWrapper,
+		dynamicController:        dynamicController,
+		metadataLabeler:          metadata.NewKROMetaLabeler(),
+		rgBuilder:                builder,
+		maxConcurrentReconciles:  maxConcurrentReconciles,
 	}
 }
 
@@ -76,6 +120,9 @@ func NewResourceGraphDefinitionReconciler(
 func (r *ResourceGraphDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Client = mgr.GetClient()
 	r.instanceLogger = mgr.GetLogger()
+	r.recorder = mgr.GetEventRecorderFor("resourcegraphdefinition-controller")
+	r.instanceRecorder = mgr.GetEventRecorderFor("instance-controller")
+	r.elected = mgr.Elected()
 
 	logConstructor := func(req *reconcile.Request) logr.Logger {
 		log := mgr.GetLogger().WithName("rgd-controller").WithValues(
@@ -89,7 +136,7 @@ func (r *ResourceGraphDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) e
 		return log
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	err := ctrl.NewControllerManagedBy(mgr).
 		Named("ResourceGraphDefinition").
 		For(&v1alpha1.ResourceGraphDefinition{}).
 		WithEventFilter(predicate.GenerationChangedPredicate{}).
@@ -100,9 +147,40 @@ func (r *ResourceGraphDefinitionReconciler) SetupWithManager(mgr ctrl.Manager) e
 			},
 		).
 		Complete(reconcile.AsReconciler[*v1alpha1.ResourceGraphDefinition](mgr.GetClient(), r))
+	if err != nil || r.dynamicController.NeedLeaderElection() {
+		return err
+	}
+
+	// The replicas sharding the instances all serve the micro controllers of
+	// the ResourceGraphDefinitions, only the elected one reconciles them.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("ResourceGraphDefinitionServer").
+		For(&v1alpha1.ResourceGraphDefinition{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		WithOptions(
+			ctrlrtcontroller.Options{
+				LogConstructor:          logConstructor,
+				MaxConcurrentReconciles: r.maxConcurrentReconciles,
+				NeedLeaderElection:      ptr.To(false),
+			},
+		).
+		Complete(reconcile.AsReconciler[*v1alpha1.ResourceGraphDefinition](mgr.GetClient(), graphServer{r}))
+}
+
+// isElected returns true once the manager is elected leader, or right away
+// when leader election is disabled.
+func (r *ResourceGraphDefinitionReconciler) isElected() bool {
+	select {
+	case <-r.elected:
+		return true
+	default:
+		return false
+	}
 }
 
 func (r *ResourceGraphDefinitionReconciler) Reconcile(ctx context.Context, o *v1alpha1.ResourceGraphDefinition) (ctrl.Result, error) {
+	defer r.startup.observe(o.Name)
+
 	if !o.DeletionTimestamp.IsZero() {
 		if err := r.cleanupResourceGraphDefinition(ctx, o); err != nil {
 			return ctrl.Result{}, err
@@ -117,8 +195,15 @@ func (r *ResourceGraphDefinitionReconciler) Reconcile(ctx context.Context, o *v1
 		return ctrl.Result{}, err
 	}
 
-	topologicalOrder, resourcesInformation, reconcileErr := r.reconcileResourceGraphDefinition(ctx, o)
+	// Rollouts in progress are driven by requeues, their graph is already
+	// served.
+	if !r.rolloutInProgress(o) {
+		topologicalOrder, resourcesInformation, accessCondition, reconcileErr := r.reconcileResourceGraphDefinition(ctx, o)
+		err := r.setResourceGraphDefinitionStatus(ctx, o, topologicalOrder, resourcesInformation, accessCondition, reconcileErr)
+		if err != nil || reconcileErr != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
-	return ctrl.Result{},
-		r.setResourceGraphDefinitionStatus(ctx, o, topologicalOrder, resourcesInformation, reconcileErr)
+	return r.reconcileRollout(ctx, o)
 }
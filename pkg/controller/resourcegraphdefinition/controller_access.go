@@ -0,0 +1,147 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	kroclient "github.com/kro-run/kro/pkg/client"
+	"github.com/kro-run/kro/pkg/graph"
+)
+
+// resourceVerbs are the verbs instance controllers use on sub-resources.
+var resourceVerbs = []string{"get", "create", "update", "delete"}
+
+// executionIdentity is an identity instances of a ResourceGraphDefinition are
+// reconciled with, and the namespace it is used in.
+type executionIdentity struct {
+	name      string
+	clientSet *kroclient.Set
+	// namespace is the namespace the identity manages resources in, empty
+	// means all namespaces.
+	namespace string
+}
+
+// reviewResourceGraphDefinitionAccess checks that the default identities used
+// to reconcile instances hold the permissions required by every resource of
+// the graph, and returns the corresponding ResourcesAccessible condition.
+//
+// The default identities are the kro controller itself when no default service
+// account is configured, or each namespace scoped default service account. The
+// "*" service account is skipped as its namespace is only known per instance,
+// in which case the instance controller runs the review.
+func (r *ResourceGraphDefinitionReconciler) reviewResourceGraphDefinitionAccess(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	processedRGD *graph.Graph,
+) *v1alpha1.Condition {
+	identities, err := r.executionIdentities(rgd)
+	if err != nil {
+		return newResourcesAccessibleCondition(metav1.ConditionUnknown, "AccessReviewFailed", err.Error())
+	}
+	if len(identities) == 0 {
+		return nil
+	}
+
+	var missing []string
+	for _, identity := range identities {
+		denied, err := identity.clientSet.ReviewAccess(ctx, requiredPermissions(processedRGD, identity.namespace))
+		if err != nil {
+			ctrl.LoggerFrom(ctx).V(1).Info("failed to review access", "identity", identity.name, "error", err)
+			return newResourcesAccessibleCondition(metav1.ConditionUnknown, "AccessReviewFailed", err.Error())
+		}
+		for _, p := range denied {
+			missing = append(missing, fmt.Sprintf("%s cannot %s", identity.name, p))
+		}
+	}
+
+	if len(missing) > 0 {
+		r.recordWarning(rgd, EventReasonMissingPermissions, "Missing permissions: %s", strings.Join(missing, ", "))
+		return newResourcesAccessibleCondition(metav1.ConditionFalse, "MissingPermissions",
+			"Missing permissions: "+strings.Join(missing, ", "))
+	}
+	return newResourcesAccessibleCondition(metav1.ConditionTrue, "PermissionsGranted",
+		"All the permissions required by the resources are granted")
+}
+
+// executionIdentities returns the default identities used to reconcile the
+// instances of the given ResourceGraphDefinition.
+func (r *ResourceGraphDefinitionReconciler) executionIdentities(rgd *v1alpha1.ResourceGraphDefinition) ([]executionIdentity, error) {
+	if len(rgd.Spec.DefaultServiceAccounts) == 0 {
+		return []executionIdentity{{name: "kro", clientSet: r.clientSet}}, nil
+	}
+
+	namespaces := make([]string, 0, len(rgd.Spec.DefaultServiceAccounts))
+	for namespace := range rgd.Spec.DefaultServiceAccounts {
+		if namespace != v1alpha1.DefaultServiceAccountKey {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	slices.Sort(namespaces)
+
+	identities := make([]executionIdentity, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		userName := serviceaccount.MakeUsername(namespace, rgd.Spec.DefaultServiceAccounts[namespace])
+		clientSet, err := r.impersonationCache.Get(userName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create impersonated client for %s: %w", userName, err)
+		}
+		identities = append(identities, executionIdentity{
+			name:      userName,
+			clientSet: clientSet,
+			namespace: namespace,
+		})
+	}
+	return identities, nil
+}
+
+// requiredPermissions returns the permissions needed to manage the resources
+// of the graph in the given namespace. Resources with a static namespace in
+// their template are reviewed in that namespace instead.
+func requiredPermissions(processedRGD *graph.Graph, namespace string) []kroclient.Permission {
+	var permissions []kroclient.Permission
+	for _, resourceID := range processedRGD.TopologicalOrder {
+		resource := processedRGD.Resources[resourceID]
+
+		resourceNamespace := ""
+		if resource.IsNamespaced() {
+			resourceNamespace = namespace
+			if ns := resource.Unstructured().GetNamespace(); ns != "" && !strings.Contains(ns, "${") {
+				resourceNamespace = ns
+			}
+		}
+
+		for _, verb := range resourceVerbs {
+			permissions = append(permissions, kroclient.Permission{
+				GVR:       resource.GetGroupVersionResource(),
+				Namespace: resourceNamespace,
+				Verb:      verb,
+			})
+		}
+	}
+	return permissions
+}
+
+func newResourcesAccessibleCondition(status metav1.ConditionStatus, reason, message string) *v1alpha1.Condition {
+	condition := v1alpha1.NewCondition(v1alpha1.ResourceGraphDefinitionConditionTypeResourcesAccessible, status, reason, message)
+	return &condition
+}
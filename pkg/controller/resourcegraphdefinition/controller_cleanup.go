@@ -38,6 +38,8 @@ func (r *ResourceGraphDefinitionReconciler) cleanupResourceGraphDefinition(ctx c
 	if err := r.shutdownResourceGraphDefinitionMicroController(ctx, &gvr); err != nil {
 		return fmt.Errorf("failed to shutdown microcontroller: %w", err)
 	}
+	r.forgetServiceAccounts(ctx, rgd)
+	r.revisions.Delete(rgd.Name)
 
 	group := rgd.Spec.Schema.Group
 	if group == "" {
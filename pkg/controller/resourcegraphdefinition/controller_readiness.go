@@ -0,0 +1,105 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// startupTracker tracks the ResourceGraphDefinitions present when the
+// controller starts, until all of them have been reconciled once.
+type startupTracker struct {
+	mu sync.Mutex
+	// initial holds the names of the ResourceGraphDefinitions listed by the
+	// first readiness check, nil until then.
+	initial map[string]struct{}
+	// reconciled holds the names of the ResourceGraphDefinitions reconciled
+	// since the controller started.
+	reconciled map[string]struct{}
+	// done is true once all the initial ResourceGraphDefinitions have been
+	// reconciled, the tracker then stops recording.
+	done bool
+}
+
+// observe records that a ResourceGraphDefinition has been reconciled.
+func (t *startupTracker) observe(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return
+	}
+	if t.reconciled == nil {
+		t.reconciled = make(map[string]struct{})
+	}
+	t.reconciled[name] = struct{}{}
+}
+
+// check returns an error until all the initial ResourceGraphDefinitions have
+// been reconciled. The ResourceGraphDefinitions deleted in the meantime are
+// not waited for.
+func (t *startupTracker) check(req *http.Request, r *ResourceGraphDefinitionReconciler) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return nil
+	}
+
+	var list v1alpha1.ResourceGraphDefinitionList
+	if err := r.List(req.Context(), &list); err != nil {
+		return fmt.Errorf("failed to list resource graph definitions: %w", err)
+	}
+	if t.initial == nil {
+		t.initial = make(map[string]struct{}, len(list.Items))
+		for _, rgd := range list.Items {
+			t.initial[rgd.Name] = struct{}{}
+		}
+	}
+
+	pending := 0
+	for _, rgd := range list.Items {
+		_, initial := t.initial[rgd.Name]
+		_, reconciled := t.reconciled[rgd.Name]
+		if initial && !reconciled {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d resource graph definitions haven't been reconciled yet", pending)
+	}
+
+	t.done = true
+	t.initial = nil
+	t.reconciled = nil
+	return nil
+}
+
+// ReadyCheck fails until the ResourceGraphDefinitions present at startup have
+// all been reconciled once, successfully or not, so that a broken one doesn't
+// block rollouts, and until the informers of their instances synced. Replicas
+// waiting to be elected are ready to take over. It implements
+// healthz.Checker.
+func (r *ResourceGraphDefinitionReconciler) ReadyCheck(req *http.Request) error {
+	if r.dynamicController.NeedLeaderElection() && !r.isElected() {
+		return nil
+	}
+
+	if err := r.startup.check(req, r); err != nil {
+		return err
+	}
+	return r.dynamicController.ReadyCheck(req)
+}
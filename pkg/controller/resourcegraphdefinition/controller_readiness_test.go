@@ -0,0 +1,136 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/dynamiccontroller"
+)
+
+// testSharder is a dynamiccontroller.Sharder owning all the objects.
+type testSharder struct{}
+
+func (testSharder) Ready() <-chan struct{}      { return nil }
+func (testSharder) Rebalanced() <-chan struct{} { return nil }
+func (testSharder) Selector() labels.Selector   { return labels.Everything() }
+func (testSharder) Assign(metav1.Object) (string, bool) {
+	return "", true
+}
+
+// newReadinessTestReconciler returns a reconciler listing the given
+// ResourceGraphDefinitions. The instances are sharded when sharded is true.
+func newReadinessTestReconciler(t *testing.T, sharded bool, elected <-chan struct{}, names ...string) *ResourceGraphDefinitionReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, name := range names {
+		builder = builder.WithObjects(&v1alpha1.ResourceGraphDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+
+	config := dynamiccontroller.Config{}
+	if sharded {
+		config.Sharder = testSharder{}
+	}
+	return &ResourceGraphDefinitionReconciler{
+		Client:            builder.Build(),
+		elected:           elected,
+		dynamicController: dynamiccontroller.NewDynamicController(logr.Discard(), config, dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())),
+	}
+}
+
+func electedChannel() <-chan struct{} {
+	elected := make(chan struct{})
+	close(elected)
+	return elected
+}
+
+func TestStartupTrackerWaitsForInitialRGDs(t *testing.T) {
+	r := newReadinessTestReconciler(t, false, electedChannel(), "a", "b")
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	assert.EqualError(t, r.ReadyCheck(req), "2 resource graph definitions haven't been reconciled yet")
+	r.startup.observe("a")
+	assert.EqualError(t, r.ReadyCheck(req), "1 resource graph definitions haven't been reconciled yet")
+
+	// ResourceGraphDefinitions created after the first check aren't waited
+	// for.
+	require.NoError(t, r.Create(context.Background(), &v1alpha1.ResourceGraphDefinition{ObjectMeta: metav1.ObjectMeta{Name: "c"}}))
+	r.startup.observe("b")
+	assert.NoError(t, r.ReadyCheck(req))
+}
+
+func TestStartupTrackerSkipsDeletedRGDs(t *testing.T) {
+	r := newReadinessTestReconciler(t, false, electedChannel(), "a", "b")
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	assert.Error(t, r.ReadyCheck(req))
+	r.startup.observe("a")
+	require.NoError(t, r.Delete(context.Background(), &v1alpha1.ResourceGraphDefinition{ObjectMeta: metav1.ObjectMeta{Name: "b"}}))
+	assert.NoError(t, r.ReadyCheck(req))
+}
+
+func TestStartupTrackerLatchesDone(t *testing.T) {
+	r := newReadinessTestReconciler(t, false, electedChannel(), "a")
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	r.startup.observe("a")
+	require.NoError(t, r.ReadyCheck(req))
+	assert.True(t, r.startup.done)
+	assert.Nil(t, r.startup.initial)
+	assert.Nil(t, r.startup.reconciled)
+
+	// Once done, the tracker stops recording and no longer lists the
+	// ResourceGraphDefinitions.
+	r.startup.observe("b")
+	assert.Nil(t, r.startup.reconciled)
+	r.Client = nil
+	assert.NoError(t, r.ReadyCheck(req))
+}
+
+func TestReadyCheckBeforeElection(t *testing.T) {
+	elected := make(chan struct{})
+	r := newReadinessTestReconciler(t, false, elected, "a")
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	// Replicas waiting to be elected are ready, without listing the
+	// ResourceGraphDefinitions.
+	assert.NoError(t, r.ReadyCheck(req))
+	assert.Nil(t, r.startup.initial)
+
+	close(elected)
+	assert.Error(t, r.ReadyCheck(req))
+	r.startup.observe("a")
+	assert.NoError(t, r.ReadyCheck(req))
+
+	// When instances are sharded, every replica reconciles them and waits
+	// for the ResourceGraphDefinitions, elected or not.
+	r = newReadinessTestReconciler(t, true, make(chan struct{}), "a")
+	assert.Error(t, r.ReadyCheck(req))
+	r.startup.observe("a")
+	assert.NoError(t, r.ReadyCheck(req))
+}
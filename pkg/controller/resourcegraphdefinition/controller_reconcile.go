@@ -16,11 +16,15 @@ package resourcegraphdefinition
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/kro-run/kro/api/v1alpha1"
 	instancectrl "github.com/kro-run/kro/pkg/controller/instance"
@@ -32,21 +36,36 @@ import (
 // reconcileResourceGraphDefinition orchestrates the reconciliation of a ResourceGraphDefinition by:
 // 1. Processing the resource graph
 // 2. Ensuring CRDs are present
-// 3. Setting up and starting the microcontroller
-func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinition(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) ([]string, []v1alpha1.ResourceInformation, error) {
+// 3. Recording the revision
+// 4. Setting up and starting the microcontroller
+//
+// It also reviews the permissions of the default identities used to reconcile
+// instances, and returns the resulting ResourcesAccessible condition, which is
+// nil if the review did not run.
+func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinition(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+) ([]string, []v1alpha1.ResourceInformation, *v1alpha1.Condition, error) {
 	log := ctrl.LoggerFrom(ctx)
 
 	// Process resource graph definition graph first to validate structure
 	log.V(1).Info("reconciling resource graph definition graph")
 	processedRGD, resourcesInfo, err := r.reconcileResourceGraphDefinitionGraph(ctx, rgd)
 	if err != nil {
-		return nil, nil, err
+		r.recordWarning(rgd, EventReasonGraphBuildFailed, "Failed to build resource graph: %v", err)
+		// Keep reconciling the instances with the last graph that was built
+		topologicalOrder, resourcesInfo := r.serveLastKnownGoodGraph(ctx, rgd)
+		return topologicalOrder, resourcesInfo, nil, err
 	}
 
+	// Review the permissions of the default identities, this is informational
+	// only, instances can still be reconciled by other identities.
+	accessCondition := r.reviewResourceGraphDefinitionAccess(ctx, rgd, processedRGD)
+
 	// Setup metadata labeling
 	graphExecLabeler, err := r.setupLabeler(rgd)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to setup labeler: %w", err)
+		return nil, nil, accessCondition, fmt.Errorf("failed to setup labeler: %w", err)
 	}
 
 	crd := processedRGD.Instance.GetCRD()
@@ -54,23 +73,150 @@ func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinition(ctx
 
 	// Ensure CRD exists and is up to date
 	log.V(1).Info("reconciling resource graph definition CRD")
-	if err := r.reconcileResourceGraphDefinitionCRD(ctx, crd); err != nil {
-		return processedRGD.TopologicalOrder, resourcesInfo, err
+	if err := r.reconcileResourceGraphDefinitionCRD(ctx, rgd, crd); err != nil {
+		r.recordWarning(rgd, EventReasonCRDSyncFailed, "Failed to sync CustomResourceDefinition %s: %v", crd.Name, err)
+		return processedRGD.TopologicalOrder, resourcesInfo, accessCondition, err
+	}
+
+	// Drop the cached clients of service accounts that are no longer used
+	r.syncServiceAccounts(ctx, rgd)
+
+	// Record the revision, instances can be pinned to it. Failing to record
+	// it doesn't prevent serving the current graph.
+	revision, previousRevision, err := r.reconcileRevision(ctx, rgd, processedRGD, graphExecLabeler)
+	if err != nil {
+		r.recordWarning(rgd, EventReasonRevisionFailed, "Failed to record revision %d: %v", revision, err)
 	}
 
 	// Setup and start microcontroller
+	log.V(1).Info("reconciling resource graph definition micro controller")
+	if err := r.serveGraph(ctx, rgd, processedRGD, revision, previousRevision, graphExecLabeler); err != nil {
+		gvr := processedRGD.Instance.GetGroupVersionResource()
+		r.recordWarning(rgd, EventReasonMicroControllerFailed, "Failed to start micro controller for %s: %v", gvr, err)
+		return processedRGD.TopologicalOrder, resourcesInfo, accessCondition, err
+	}
+	r.graphRevisions(rgd.Name).setGeneration(rgd.Generation)
+
+	return processedRGD.TopologicalOrder, resourcesInfo, accessCondition, nil
+}
+
+// serveGraph sets up the micro controller reconciling the instances with the
+// graph of a revision, and starts serving it.
+func (r *ResourceGraphDefinitionReconciler) serveGraph(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	processedRGD *graph.Graph,
+	revision, previousRevision int64,
+	labeler metadata.Labeler,
+) error {
 	gvr := processedRGD.Instance.GetGroupVersionResource()
-	controller := r.setupMicroController(gvr, processedRGD, rgd.Spec.DefaultServiceAccounts, graphExecLabeler)
+	controller := r.setupMicroController(gvr, processedRGD, rgd.Spec.DefaultServiceAccounts, rgd.Spec.ServiceAccountPolicy, rgd.Spec.Paused, labeler)
+	revisions := r.graphRevisions(rgd.Name)
+	controller.SetRevisions(revisions.add(gvr, revision, previousRevision, processedRGD, rgd.Spec.Rollout != nil))
+	r.dynamicController.SetQueueConfig(gvr, queueConfig(rgd.Spec.Queue))
+	scope, err := instanceScope(rgd.Spec.Scope)
+	if err != nil {
+		return err
+	}
+	r.dynamicController.SetScope(gvr, scope)
+	r.dynamicController.SetStalledHandler(gvr, controller.Stalled)
 
-	log.V(1).Info("reconciling resource graph definition micro controller")
 	// TODO: the context that is passed here is tied to the reconciliation of the rgd, we might need to make
 	// a new context with our own cancel function here to allow us to cleanly term the dynamic controller
 	// rather than have it ignore this context and use the background context.
 	if err := r.reconcileResourceGraphDefinitionMicroController(ctx, &gvr, controller.Reconcile); err != nil {
-		return processedRGD.TopologicalOrder, resourcesInfo, err
+		return err
+	}
+	revisions.setServed(revision)
+	return nil
+}
+
+// queueConfig returns the configuration of the queue of the instances, unset
+// fields use the defaults of the dynamic controller.
+func queueConfig(policy *v1alpha1.QueuePolicy) dynamiccontroller.QueueConfig {
+	if policy == nil {
+		return dynamiccontroller.QueueConfig{}
+	}
+	return dynamiccontroller.QueueConfig{
+		Weight:                  int(policy.Weight),
+		MaxConcurrentReconciles: int(policy.MaxConcurrentReconciles),
+		RateLimit:               int(policy.RateLimit),
+		BurstLimit:              int(policy.BurstLimit),
+	}
+}
+
+// instanceScope returns the scope of the instances, within the scope of the
+// dynamic controller.
+func instanceScope(scope *v1alpha1.InstanceScope) (dynamiccontroller.Scope, error) {
+	if scope == nil {
+		return dynamiccontroller.Scope{}, nil
 	}
+	var selector labels.Selector
+	if scope.NamespaceSelector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(scope.NamespaceSelector)
+		if err != nil {
+			return dynamiccontroller.Scope{}, fmt.Errorf("invalid namespace selector: %w", err)
+		}
+	}
+	return dynamiccontroller.Scope{
+		Namespaces:        scope.Namespaces,
+		NamespaceSelector: selector,
+	}, nil
+}
+
+// serveLastKnownGoodGraph keeps reconciling the instances with the graph of
+// the latest revision that was built, when the current generation of the
+// ResourceGraphDefinition fails to build. The operational settings, e.g
+// paused, are taken from the current generation. It returns the topological
+// order and the resources information of the served graph, which are empty
+// if there is no previous revision.
+func (r *ResourceGraphDefinitionReconciler) serveLastKnownGoodGraph(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+) ([]string, []v1alpha1.ResourceInformation) {
+	log := ctrl.LoggerFrom(ctx)
+
+	revisions, err := r.listRevisions(ctx, rgd)
+	if err != nil {
+		log.Error(err, "failed to list revisions")
+		return nil, nil
+	}
+	var revision, previousRevision int64
+	for _, rev := range revisions {
+		if rev.Spec.Revision < rgd.Generation {
+			revision, previousRevision = rev.Spec.Revision, revision
+		}
+	}
+	if revision == 0 {
+		return nil, nil
+	}
+
+	graphs := r.graphRevisions(rgd.Name)
+	processedRGD, err := graphs.Graph(ctx, revision)
+	if err != nil {
+		log.Error(err, "failed to build the last known good graph", "revision", revision)
+		return nil, nil
+	}
+	labeler, err := r.setupLabeler(rgd)
+	if err != nil {
+		log.Error(err, "failed to setup labeler")
+		return nil, nil
+	}
+	// The warning is only recorded when the served graph changes, the status
+	// conditions report the failures after that.
+	alreadyServed := graphs.servedRevision() == revision && graphs.servedGeneration() == 0
+	if err := r.serveGraph(ctx, rgd, processedRGD, revision, previousRevision, labeler); err != nil {
+		log.Error(err, "failed to serve the last known good graph", "revision", revision)
+		return nil, nil
+	}
+	graphs.setGeneration(0)
 
-	return processedRGD.TopologicalOrder, resourcesInfo, nil
+	if !alreadyServed {
+		r.recordWarning(rgd, EventReasonServingPreviousGraph,
+			"Reconciling instances with the graph of generation %d until generation %d is fixed", revision, rgd.Generation)
+	}
+	return processedRGD.TopologicalOrder, resourcesInformation(processedRGD)
 }
 
 // setupLabeler creates and merges the required labelers for the resource graph definition
@@ -84,6 +230,8 @@ func (r *ResourceGraphDefinitionReconciler) setupMicroController(
 	gvr schema.GroupVersionResource,
 	processedRGD *graph.Graph,
 	defaultSVCs map[string]string,
+	serviceAccountPolicy *v1alpha1.ServiceAccountPolicy,
+	paused bool,
 	labeler metadata.Labeler,
 ) *instancectrl.Controller {
 	instanceLogger := r.instanceLogger.WithName(fmt.Sprintf("%s-controller", gvr.Resource)).WithValues(
@@ -95,42 +243,55 @@ func (r *ResourceGraphDefinitionReconciler) setupMicroController(
 	return instancectrl.NewController(
 		instanceLogger,
 		instancectrl.ReconcileConfig{
-			DefaultRequeueDuration:    3 * time.Second,
-			DeletionGraceTimeDuration: 30 * time.Second,
-			DeletionPolicy:            "Delete",
+			DefaultRequeueDuration: 3 * time.Second,
+			MaxRequeueDuration:     5 * time.Minute,
+			Paused:                 paused,
+			PausedRequeueDuration:  time.Minute,
+			DeletionPolicy:         "Delete",
 		},
 		gvr,
 		processedRGD,
 		r.clientSet,
+		r.impersonationCache,
+		r.resourceCache,
 		defaultSVCs,
+		serviceAccountPolicy,
+		r.serviceAccountAuthorizer,
 		labeler,
+		r.instanceRecorder,
 	)
 }
 
 // reconcileResourceGraphDefinitionGraph processes the resource graph definition to build a dependency graph
 // and extract resource information
-func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionGraph(_ context.Context, rgd *v1alpha1.ResourceGraphDefinition) (*graph.Graph, []v1alpha1.ResourceInformation, error) {
-	processedRGD, err := r.rgBuilder.NewResourceGraphDefinition(rgd)
+func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionGraph(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) (*graph.Graph, []v1alpha1.ResourceInformation, error) {
+	processedRGD, err := r.rgBuilder.NewResourceGraphDefinition(ctx, rgd)
 	if err != nil {
 		return nil, nil, newGraphError(err)
 	}
 
+	return processedRGD, resourcesInformation(processedRGD), nil
+}
+
+// resourcesInformation returns the information of the resources of the graph
+// that have dependencies.
+func resourcesInformation(processedRGD *graph.Graph) []v1alpha1.ResourceInformation {
 	resourcesInfo := make([]v1alpha1.ResourceInformation, 0, len(processedRGD.Resources))
 	for name, resource := range processedRGD.Resources {
 		deps := resource.GetDependencies()
 		if len(deps) > 0 {
-			resourcesInfo = append(resourcesInfo, buildResourceInfo(name, deps))
+			resourcesInfo = append(resourcesInfo, buildResourceInfo(name, deps, resource.GetExplicitDependencies()))
 		}
 	}
-
-	return processedRGD, resourcesInfo, nil
+	return resourcesInfo
 }
 
-// buildResourceInfo creates a ResourceInformation struct from name and dependencies
-func buildResourceInfo(name string, deps []string) v1alpha1.ResourceInformation {
+// buildResourceInfo creates a ResourceInformation struct from name and dependencies,
+// flagging the dependencies declared with dependsOn.
+func buildResourceInfo(name string, deps, explicitDeps []string) v1alpha1.ResourceInformation {
 	dependencies := make([]v1alpha1.Dependency, 0, len(deps))
 	for _, dep := range deps {
-		dependencies = append(dependencies, v1alpha1.Dependency{ID: dep})
+		dependencies = append(dependencies, v1alpha1.Dependency{ID: dep, Explicit: slices.Contains(explicitDeps, dep)})
 	}
 	return v1alpha1.ResourceInformation{
 		ID:           name,
@@ -138,11 +299,25 @@ func buildResourceInfo(name string, deps []string) v1alpha1.ResourceInformation
 	}
 }
 
-// reconcileResourceGraphDefinitionCRD ensures the CRD is present and up to date in the cluster
-func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionCRD(ctx context.Context, crd *v1.CustomResourceDefinition) error {
-	if err := r.crdManager.Ensure(ctx, *crd); err != nil {
+// reconcileResourceGraphDefinitionCRD ensures the CRD is present and up to date in the cluster.
+// An event is recorded on the resource graph definition whenever the CRD is created or
+// its spec changes.
+func (r *ResourceGraphDefinitionReconciler) reconcileResourceGraphDefinitionCRD(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	crd *v1.CustomResourceDefinition,
+) error {
+	current, result, err := r.crdManager.Ensure(ctx, *crd)
+	if err != nil {
 		return newCRDError(err)
 	}
+
+	switch result {
+	case controllerutil.OperationResultCreated:
+		r.recordEvent(rgd, EventReasonCRDCreated, "Created CustomResourceDefinition %s", crd.Name)
+	case controllerutil.OperationResultUpdated:
+		r.recordEvent(rgd, EventReasonCRDUpdated, "Updated CustomResourceDefinition %s (generation %d)", crd.Name, current.Generation)
+	}
 	return nil
 }
 
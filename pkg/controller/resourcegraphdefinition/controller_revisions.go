@@ -0,0 +1,435 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	instancectrl "github.com/kro-run/kro/pkg/controller/instance"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// graphRevisions caches the graphs of the revisions of a
+// ResourceGraphDefinition. The graphs of the revisions that are not cached are
+// built from their ResourceGraphDefinitionRevision.
+type graphRevisions struct {
+	mu  sync.Mutex
+	gvr schema.GroupVersionResource
+	// served is the revision served by the micro controller.
+	served int64
+	// generation is the generation of the ResourceGraphDefinition whose
+	// graph is served, or 0 when the graph of a previous revision is served
+	// because the latest generation fails to build.
+	generation int64
+	graphs     map[int64]*graph.Graph
+	// failures are the errors of the graphs that failed to build, they are
+	// only built again after a backoff.
+	failures map[int64]*graphFailure
+	// build builds the graph of a revision.
+	build func(ctx context.Context, revision int64) (*graph.Graph, error)
+}
+
+// graphFailure is the error of a graph that failed to build.
+type graphFailure struct {
+	err     error
+	backoff time.Duration
+	retryAt time.Time
+}
+
+const (
+	// graphFailureBackoff is the delay before building again the graph of
+	// a revision that failed to build, doubled after each failure.
+	graphFailureBackoff = 5 * time.Second
+	// maxGraphFailureBackoff bounds graphFailureBackoff.
+	maxGraphFailureBackoff = 5 * time.Minute
+	// defaultRevisionHistoryLimit is the number of
+	// ResourceGraphDefinitionRevisions kept when the ResourceGraphDefinition
+	// doesn't set a revision history limit.
+	defaultRevisionHistoryLimit = 10
+)
+
+var _ instancectrl.RevisionGraphs = &graphRevisions{}
+
+// graphRevisions returns the graph revisions of the ResourceGraphDefinition.
+func (r *ResourceGraphDefinitionReconciler) graphRevisions(name string) *graphRevisions {
+	revisions, _ := r.revisions.LoadOrStore(name, &graphRevisions{
+		graphs:   make(map[int64]*graph.Graph),
+		failures: make(map[int64]*graphFailure),
+		build: func(ctx context.Context, revision int64) (*graph.Graph, error) {
+			return r.buildRevisionGraph(ctx, name, revision)
+		},
+	})
+	return revisions.(*graphRevisions)
+}
+
+// add records the graph of the current revision, and returns the revisions
+// the micro controller reconciles instances with. The cached graphs of the
+// other revisions are dropped when the rollout isn't staged.
+func (g *graphRevisions) add(
+	gvr schema.GroupVersionResource,
+	revision, previous int64,
+	processedRGD *graph.Graph,
+	staged bool,
+) instancectrl.Revisions {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.gvr != gvr || !staged {
+		g.graphs = make(map[int64]*graph.Graph)
+		g.failures = make(map[int64]*graphFailure)
+	}
+	g.gvr = gvr
+	g.graphs[revision] = processedRGD
+
+	return instancectrl.Revisions{
+		Current:  revision,
+		Previous: previous,
+		Graphs:   g,
+		Staged:   staged,
+	}
+}
+
+// Graph returns the graph of a revision, building it from its
+// ResourceGraphDefinitionRevision if it isn't cached. The graphs that fail to
+// build are only built again after a backoff, their error is returned until
+// then.
+func (g *graphRevisions) Graph(ctx context.Context, revision int64) (*graph.Graph, error) {
+	g.mu.Lock()
+	cached, ok := g.graphs[revision]
+	failure := g.failures[revision]
+	g.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	if failure != nil && time.Now().Before(failure.retryAt) {
+		return nil, failure.err
+	}
+
+	built, err := g.build(ctx, revision)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err != nil {
+		backoff := graphFailureBackoff
+		if failure != nil {
+			backoff = min(2*failure.backoff, maxGraphFailureBackoff)
+		}
+		g.failures[revision] = &graphFailure{err: err, backoff: backoff, retryAt: time.Now().Add(backoff)}
+		return nil, err
+	}
+	delete(g.failures, revision)
+	g.graphs[revision] = built
+	return built, nil
+}
+
+// setServed records the revision served by the micro controller.
+func (g *graphRevisions) setServed(revision int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.served = revision
+}
+
+// servedRevision returns the revision served by the micro controller, or 0
+// if none is.
+func (g *graphRevisions) servedRevision() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.served
+}
+
+// setGeneration records the generation of the ResourceGraphDefinition whose
+// graph is served, 0 for the last known good graph.
+func (g *graphRevisions) setGeneration(generation int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.generation = generation
+}
+
+// servedGeneration returns the generation of the ResourceGraphDefinition
+// whose graph is served, or 0 when the last known good graph is.
+func (g *graphRevisions) servedGeneration() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.generation
+}
+
+// prune drops the cached graphs of the revisions older than the given one.
+func (g *graphRevisions) prune(revision int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for r := range g.graphs {
+		if r < revision {
+			delete(g.graphs, r)
+		}
+	}
+	for r := range g.failures {
+		if r < revision {
+			delete(g.failures, r)
+		}
+	}
+}
+
+// reconcileRevision creates the ResourceGraphDefinitionRevision of the
+// generation of the ResourceGraphDefinition, if it changes the graph of the
+// latest revision, and prunes the revisions beyond the revision history
+// limit. It returns the current revision, the one of the latest generation
+// that changed the graph, and the previous one.
+func (r *ResourceGraphDefinitionReconciler) reconcileRevision(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	processedRGD *graph.Graph,
+	labeler metadata.Labeler,
+) (int64, int64, error) {
+	revisions, err := r.listRevisions(ctx, rgd)
+	if err != nil {
+		return rgd.Generation, 0, err
+	}
+	hash, err := graphHash(&rgd.Spec)
+	if err != nil {
+		return rgd.Generation, 0, err
+	}
+
+	current, previous, recorded := currentRevision(revisions, rgd.Generation, hash)
+	if !recorded {
+		revision, err := newRevision(rgd, processedRGD)
+		if err != nil {
+			return current, previous, err
+		}
+		labeler.ApplyLabels(revision)
+		revision.Labels[metadata.ResourceGraphDefinitionVersionLabel] = strconv.FormatInt(rgd.Generation, 10)
+
+		if err := r.Create(ctx, revision); err != nil && !apierrors.IsAlreadyExists(err) {
+			return current, previous, fmt.Errorf("failed to create revision %s: %w", revision.Name, err)
+		}
+		ctrl.LoggerFrom(ctx).V(1).Info("created resource graph definition revision", "revision", revision.Name)
+		revisions = append(revisions, *revision)
+	}
+
+	return current, previous, r.pruneRevisions(ctx, rgd, processedRGD, revisions, current, previous)
+}
+
+// currentRevision returns the current and previous revisions of a generation
+// of the ResourceGraphDefinition, given its recorded revisions in increasing
+// order and the hash of its graph, and whether the current revision is
+// recorded. Generations that don't change the graph of the latest revision,
+// e.g that only change the operational settings, keep it current.
+func currentRevision(
+	revisions []v1alpha1.ResourceGraphDefinitionRevision,
+	generation int64,
+	hash string,
+) (int64, int64, bool) {
+	var latest, previous *v1alpha1.ResourceGraphDefinitionRevisionSpec
+	for i := range revisions {
+		if revisions[i].Spec.Revision <= generation {
+			latest, previous = &revisions[i].Spec, latest
+		}
+	}
+
+	switch {
+	case latest == nil:
+		return generation, 0, false
+	case latest.Revision == generation, latest.GraphHash == hash:
+		if previous == nil {
+			return latest.Revision, 0, true
+		}
+		return latest.Revision, previous.Revision, true
+	default:
+		return generation, latest.Revision, false
+	}
+}
+
+// listRevisions returns the ResourceGraphDefinitionRevisions of the
+// ResourceGraphDefinition, in increasing revision order.
+func (r *ResourceGraphDefinitionReconciler) listRevisions(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+) ([]v1alpha1.ResourceGraphDefinitionRevision, error) {
+	list := &v1alpha1.ResourceGraphDefinitionRevisionList{}
+	if err := r.List(ctx, list, client.MatchingLabels{metadata.ResourceGraphDefinitionNameLabel: rgd.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+
+	revisions := make([]v1alpha1.ResourceGraphDefinitionRevision, 0, len(list.Items))
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], rgd) {
+			revisions = append(revisions, list.Items[i])
+		}
+	}
+	slices.SortFunc(revisions, func(a, b v1alpha1.ResourceGraphDefinitionRevision) int {
+		return cmp.Compare(a.Spec.Revision, b.Spec.Revision)
+	})
+	return revisions, nil
+}
+
+// pruneRevisions deletes the oldest ResourceGraphDefinitionRevisions beyond
+// the revision history limit. The current and previous revisions, and the
+// revisions instances are pinned to or still use during staged rollouts, are
+// kept.
+func (r *ResourceGraphDefinitionReconciler) pruneRevisions(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	processedRGD *graph.Graph,
+	revisions []v1alpha1.ResourceGraphDefinitionRevision,
+	current, previous int64,
+) error {
+	limit := defaultRevisionHistoryLimit
+	if rgd.Spec.RevisionHistoryLimit != nil {
+		limit = int(*rgd.Spec.RevisionHistoryLimit)
+	}
+	if len(revisions) <= limit {
+		return nil
+	}
+
+	inUse := map[int64]bool{current: true, previous: true, r.graphRevisions(rgd.Name).servedRevision(): true}
+	instances, err := r.listInstances(ctx, processedRGD.Instance.GetGroupVersionResource())
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+	for i := range instances {
+		for _, revision := range instanceRevisions(&instances[i], current, previous) {
+			inUse[revision] = true
+		}
+	}
+
+	for i := range revisions[:len(revisions)-limit] {
+		revision := &revisions[i]
+		if inUse[revision.Spec.Revision] {
+			continue
+		}
+		if err := r.Delete(ctx, revision); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete revision %s: %w", revision.Name, err)
+		}
+		ctrl.LoggerFrom(ctx).V(1).Info("deleted resource graph definition revision", "revision", revision.Name)
+	}
+	return nil
+}
+
+// instanceRevisions returns the revisions an instance is pinned to or is
+// reconciled with during staged rollouts.
+func instanceRevisions(instance *unstructured.Unstructured, current, previous int64) []int64 {
+	var revisions []int64
+	annotations := instance.GetAnnotations()
+	if pinned, err := strconv.ParseInt(annotations[metadata.PinnedRevisionAnnotation], 10, 64); err == nil {
+		revisions = append(revisions, pinned)
+	}
+	if _, ok := annotations[metadata.ResourceGraphDefinitionRevisionAnnotation]; ok {
+		return append(revisions, instanceRevision(instance, current))
+	}
+	// Instances managed before the rollout policy was set use the previous
+	// revision.
+	return append(revisions, current, previous)
+}
+
+// newRevision returns the ResourceGraphDefinitionRevision of the generation of
+// the ResourceGraphDefinition.
+func newRevision(rgd *v1alpha1.ResourceGraphDefinition, processedRGD *graph.Graph) (*v1alpha1.ResourceGraphDefinitionRevision, error) {
+	definition, err := json.Marshal(rgd.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal definition: %w", err)
+	}
+	hash, err := graphHash(&rgd.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var instanceSchema runtime.RawExtension
+	if versions := processedRGD.Instance.GetCRD().Spec.Versions; len(versions) > 0 && versions[0].Schema != nil {
+		raw, err := json.Marshal(versions[0].Schema.OpenAPIV3Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema: %w", err)
+		}
+		instanceSchema.Raw = raw
+	}
+
+	return &v1alpha1.ResourceGraphDefinitionRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: revisionName(rgd.Name, rgd.Generation),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rgd, v1alpha1.GroupVersion.WithKind("ResourceGraphDefinition")),
+			},
+		},
+		Spec: v1alpha1.ResourceGraphDefinitionRevisionSpec{
+			ResourceGraphDefinitionName: rgd.Name,
+			Revision:                    rgd.Generation,
+			GraphHash:                   hash,
+			TopologicalOrder:            processedRGD.TopologicalOrder,
+			Schema:                      instanceSchema,
+			Definition:                  runtime.RawExtension{Raw: definition},
+		},
+	}, nil
+}
+
+// buildRevisionGraph builds the graph of a revision of the
+// ResourceGraphDefinition from its ResourceGraphDefinitionRevision.
+func (r *ResourceGraphDefinitionReconciler) buildRevisionGraph(ctx context.Context, name string, revision int64) (*graph.Graph, error) {
+	rgdRevision := &v1alpha1.ResourceGraphDefinitionRevision{}
+	if err := r.Get(ctx, types.NamespacedName{Name: revisionName(name, revision)}, rgdRevision); err != nil {
+		return nil, fmt.Errorf("failed to get revision %d: %w", revision, err)
+	}
+
+	rgd := &v1alpha1.ResourceGraphDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Generation: revision},
+	}
+	if err := json.Unmarshal(rgdRevision.Spec.Definition.Raw, &rgd.Spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal definition of revision %d: %w", revision, err)
+	}
+	processedRGD, err := r.rgBuilder.NewResourceGraphDefinition(ctx, rgd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graph of revision %d: %w", revision, err)
+	}
+	return processedRGD, nil
+}
+
+// graphHash returns the hash of the fields of the ResourceGraphDefinition spec
+// the graph is built from.
+func graphHash(spec *v1alpha1.ResourceGraphDefinitionSpec) (string, error) {
+	b, err := json.Marshal(v1alpha1.ResourceGraphDefinitionSpec{
+		Schema:        spec.Schema,
+		Resources:     spec.Resources,
+		HealthChecks:  spec.HealthChecks,
+		ReadyTimeout:  spec.ReadyTimeout,
+		DeleteTimeout: spec.DeleteTimeout,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal graph: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// revisionName returns the name of the ResourceGraphDefinitionRevision of a
+// revision of the ResourceGraphDefinition.
+func revisionName(name string, revision int64) string {
+	return fmt.Sprintf("%s-%d", name, revision)
+}
@@ -0,0 +1,184 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/graph"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+func TestGraphRevisionsCachesFailures(t *testing.T) {
+	builds := 0
+	var buildErr error
+	revisions := &graphRevisions{
+		graphs:   make(map[int64]*graph.Graph),
+		failures: make(map[int64]*graphFailure),
+		build: func(context.Context, int64) (*graph.Graph, error) {
+			builds++
+			return &graph.Graph{}, buildErr
+		},
+	}
+	ctx := context.Background()
+
+	// Failures are returned without building the graph again until the
+	// backoff expires.
+	buildErr = errors.New("revision not found")
+	_, err := revisions.Graph(ctx, 1)
+	require.ErrorIs(t, err, buildErr)
+	_, err = revisions.Graph(ctx, 1)
+	require.ErrorIs(t, err, buildErr)
+	assert.Equal(t, 1, builds)
+	assert.Equal(t, graphFailureBackoff, revisions.failures[1].backoff)
+
+	// The backoff doubles after each failure.
+	revisions.failures[1].retryAt = time.Now()
+	_, err = revisions.Graph(ctx, 1)
+	require.ErrorIs(t, err, buildErr)
+	assert.Equal(t, 2, builds)
+	assert.Equal(t, 2*graphFailureBackoff, revisions.failures[1].backoff)
+
+	// Graphs are cached once built.
+	buildErr = nil
+	revisions.failures[1].retryAt = time.Now()
+	g, err := revisions.Graph(ctx, 1)
+	require.NoError(t, err)
+	assert.NotNil(t, g)
+	assert.Empty(t, revisions.failures)
+	_, err = revisions.Graph(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, builds)
+}
+
+func TestInstanceRevisions(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		managed     bool
+		want        []int64
+	}{
+		{
+			name: "new instance",
+			want: []int64{5, 4},
+		},
+		{
+			name:    "instance managed before the rollout policy was set",
+			managed: true,
+			want:    []int64{5, 4},
+		},
+		{
+			name:        "instance of a staged rollout",
+			annotations: map[string]string{metadata.ResourceGraphDefinitionRevisionAnnotation: "3"},
+			want:        []int64{3},
+		},
+		{
+			name: "pinned instance",
+			annotations: map[string]string{
+				metadata.PinnedRevisionAnnotation:                  "1",
+				metadata.ResourceGraphDefinitionRevisionAnnotation: "5",
+			},
+			want: []int64{1, 5},
+		},
+		{
+			name:        "invalid pinned revision",
+			annotations: map[string]string{metadata.PinnedRevisionAnnotation: "latest"},
+			want:        []int64{5, 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			instance.SetAnnotations(tt.annotations)
+			if tt.managed {
+				require.NoError(t, metadata.SetInstanceFinalizerUnstructured(instance))
+			}
+			assert.Equal(t, tt.want, instanceRevisions(instance, 5, 4))
+		})
+	}
+}
+
+func TestCurrentRevision(t *testing.T) {
+	revision := func(revision int64, hash string) v1alpha1.ResourceGraphDefinitionRevision {
+		return v1alpha1.ResourceGraphDefinitionRevision{
+			Spec: v1alpha1.ResourceGraphDefinitionRevisionSpec{Revision: revision, GraphHash: hash},
+		}
+	}
+	tests := []struct {
+		name         string
+		revisions    []v1alpha1.ResourceGraphDefinitionRevision
+		generation   int64
+		hash         string
+		wantCurrent  int64
+		wantPrevious int64
+		wantRecorded bool
+	}{
+		{
+			name:        "first generation",
+			generation:  1,
+			hash:        "a",
+			wantCurrent: 1,
+		},
+		{
+			name:         "recorded generation",
+			revisions:    []v1alpha1.ResourceGraphDefinitionRevision{revision(1, "a"), revision(3, "b")},
+			generation:   3,
+			hash:         "b",
+			wantCurrent:  3,
+			wantPrevious: 1,
+			wantRecorded: true,
+		},
+		{
+			name:         "generation changing the graph",
+			revisions:    []v1alpha1.ResourceGraphDefinitionRevision{revision(1, "a"), revision(3, "b")},
+			generation:   4,
+			hash:         "c",
+			wantCurrent:  4,
+			wantPrevious: 3,
+		},
+		{
+			name:         "generation not changing the graph",
+			revisions:    []v1alpha1.ResourceGraphDefinitionRevision{revision(1, "a"), revision(3, "b")},
+			generation:   5,
+			hash:         "b",
+			wantCurrent:  3,
+			wantPrevious: 1,
+			wantRecorded: true,
+		},
+		{
+			name:         "generation reverting the graph",
+			revisions:    []v1alpha1.ResourceGraphDefinitionRevision{revision(1, "a"), revision(3, "b")},
+			generation:   5,
+			hash:         "a",
+			wantCurrent:  5,
+			wantPrevious: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, previous, recorded := currentRevision(tt.revisions, tt.generation, tt.hash)
+			assert.Equal(t, tt.wantCurrent, current)
+			assert.Equal(t, tt.wantPrevious, previous)
+			assert.Equal(t, tt.wantRecorded, recorded)
+		})
+	}
+}
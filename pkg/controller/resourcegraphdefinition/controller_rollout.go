@@ -0,0 +1,338 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	instancectrl "github.com/kro-run/kro/pkg/controller/instance"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// rolloutRequeueDuration is the interval at which rollouts in progress are
+// driven forward.
+const rolloutRequeueDuration = 10 * time.Second
+
+// maxReportedInstances bounds the number of instances named in rollout
+// messages.
+const maxReportedInstances = 5
+
+// rolloutInProgress returns true when the micro controller already serves the
+// generation of the ResourceGraphDefinition and the rollout of its revision
+// isn't complete, in which case the reconciliation only drives the rollout
+// forward.
+func (r *ResourceGraphDefinitionReconciler) rolloutInProgress(rgd *v1alpha1.ResourceGraphDefinition) bool {
+	if rgd.Spec.Rollout == nil || rgd.Status.Rollout == nil || rgd.Status.Rollout.State == v1alpha1.RolloutStateComplete {
+		return false
+	}
+	revisions := r.graphRevisions(rgd.Name)
+	return revisions.servedGeneration() == rgd.Generation && revisions.servedRevision() == rgd.Status.Rollout.Revision
+}
+
+// reconcileRollout moves the instances to the latest revision as allowed by
+// the rollout policy, reports the progress in the ResourceGraphDefinition
+// status, and requeues the ResourceGraphDefinition until the rollout is
+// complete.
+func (r *ResourceGraphDefinitionReconciler) reconcileRollout(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+) (ctrl.Result, error) {
+	if !r.isElected() {
+		// Only the leader drives rollouts.
+		return ctrl.Result{}, nil
+	}
+
+	if rgd.Spec.Rollout == nil {
+		return ctrl.Result{}, r.setRolloutStatus(ctx, rgd, nil)
+	}
+
+	status, err := r.rollout(ctx, rgd, r.graphRevisions(rgd.Name))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setRolloutStatus(ctx, rgd, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	if status.State == v1alpha1.RolloutStateComplete {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: rolloutRequeueDuration}, nil
+}
+
+// rollout computes the progress of the rollout of the latest revision, and
+// moves the next instances to it by updating their
+// metadata.ResourceGraphDefinitionRevisionAnnotation.
+//
+// Instances moved to the revision are unavailable until they report it in
+// their status and are ACTIVE. At most MaxUnavailable instances are
+// unavailable at a time, and the instances of a wave are only moved once all
+// the instances of the previous waves are. The rollout halts while instances
+// moved to the revision are degraded or failing, unless the failure policy is
+// Continue.
+func (r *ResourceGraphDefinitionReconciler) rollout(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	revisions *graphRevisions,
+) (*v1alpha1.RolloutStatus, error) {
+	log := ctrl.LoggerFrom(ctx)
+	policy := rgd.Spec.Rollout
+	target := revisions.servedRevision()
+
+	revisions.mu.Lock()
+	gvr := revisions.gvr
+	revisions.mu.Unlock()
+	if gvr.Empty() {
+		return nil, fmt.Errorf("revision %d is not served", target)
+	}
+
+	instanceClient := r.clientSet.Dynamic().Resource(gvr)
+	instances, err := r.listInstances(ctx, gvr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	status := &v1alpha1.RolloutStatus{
+		Revision:  target,
+		Instances: int32(len(instances)),
+	}
+
+	selectors := make([]labels.Selector, 0, len(policy.Waves))
+	for _, wave := range policy.Waves {
+		selector, err := metav1.LabelSelectorAsSelector(&wave.Selector)
+		if err != nil {
+			status.State = v1alpha1.RolloutStateHalted
+			status.Message = fmt.Sprintf("invalid selector of wave %s: %v", wave.Name, err)
+			return status, nil
+		}
+		selectors = append(selectors, selector)
+	}
+
+	// Instances that are not selected by any wave are in the last one.
+	pending := make([][]*unstructured.Unstructured, len(selectors)+1)
+	var failing []string
+	for i := range instances {
+		instance := &instances[i]
+		if _, ok := instance.GetAnnotations()[metadata.PinnedRevisionAnnotation]; ok {
+			status.PinnedInstances++
+			continue
+		}
+		if instanceRevision(instance, target) < target {
+			wave := len(selectors)
+			for j, selector := range selectors {
+				if selector.Matches(labels.Set(instance.GetLabels())) {
+					wave = j
+					break
+				}
+			}
+			pending[wave] = append(pending[wave], instance)
+			continue
+		}
+
+		status.UpdatedInstances++
+		state, _, _ := unstructured.NestedString(instance.Object, "status", "state")
+		observed, _, _ := unstructured.NestedInt64(instance.Object, "status", "revision")
+		if observed != target || state != instancectrl.InstanceStateActive {
+			status.UnavailableInstances++
+		}
+		if observed == target && isFailingInstanceState(state) {
+			status.DegradedInstances++
+			failing = append(failing, instanceName(instance))
+		}
+	}
+
+	if status.DegradedInstances > 0 && policy.OnFailure != v1alpha1.RolloutFailurePolicyContinue {
+		status.State = v1alpha1.RolloutStateHalted
+		status.Message = fmt.Sprintf("%d instances are degraded or failing with revision %d: %s",
+			status.DegradedInstances, target, summarizeInstances(failing))
+		if previous := rgd.Status.Rollout; previous == nil || previous.Revision != target || previous.State != v1alpha1.RolloutStateHalted {
+			r.recordWarning(rgd, EventReasonRolloutHalted, "Halted the rollout of revision %d: %s", target, status.Message)
+		}
+		return status, nil
+	}
+
+	wave := -1
+	remaining := 0
+	for i, instances := range pending {
+		if len(instances) > 0 && wave == -1 {
+			wave = i
+		}
+		remaining += len(instances)
+	}
+	if wave == -1 {
+		status.State = v1alpha1.RolloutStateComplete
+		status.Message = fmt.Sprintf("all instances use revision %d", target)
+		if status.PinnedInstances > 0 {
+			status.Message = fmt.Sprintf("%s, except %d pinned instances", status.Message, status.PinnedInstances)
+		}
+		if previous := rgd.Status.Rollout; previous == nil || previous.Revision != target || previous.State != v1alpha1.RolloutStateComplete {
+			r.recordEvent(rgd, EventReasonRolloutComplete, "Rolled out revision %d to %d instances", target, status.Instances)
+		}
+		revisions.prune(target)
+		return status, nil
+	}
+
+	status.State = v1alpha1.RolloutStateProgressing
+	if wave < len(policy.Waves) {
+		status.CurrentWave = policy.Waves[wave].Name
+	}
+
+	maxUnavailable := intstr.FromInt32(1)
+	if policy.MaxUnavailable != nil {
+		maxUnavailable = *policy.MaxUnavailable
+	}
+	budget, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailable, int(status.Instances), true)
+	if err != nil {
+		status.State = v1alpha1.RolloutStateHalted
+		status.Message = fmt.Sprintf("invalid maxUnavailable: %v", err)
+		return status, nil
+	}
+	budget = max(budget, 1) - int(status.UnavailableInstances)
+
+	for _, instance := range pending[wave][:max(0, min(budget, len(pending[wave])))] {
+		if err := setInstanceRevision(ctx, instanceClient.Namespace(instance.GetNamespace()), instance.GetName(), target); err != nil {
+			return nil, fmt.Errorf("failed to move instance %s to revision %d: %w", instanceName(instance), target, err)
+		}
+		log.V(1).Info("moved instance to new revision", "instance", instanceName(instance), "revision", target)
+		status.UpdatedInstances++
+		status.UnavailableInstances++
+		remaining--
+	}
+
+	status.Message = fmt.Sprintf("%d instances left to move to revision %d", remaining, target)
+	return status, nil
+}
+
+// setRolloutStatus updates the rollout status of the ResourceGraphDefinition,
+// if it changed.
+func (r *ResourceGraphDefinitionReconciler) setRolloutStatus(
+	ctx context.Context,
+	rgd *v1alpha1.ResourceGraphDefinition,
+	status *v1alpha1.RolloutStatus,
+) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &v1alpha1.ResourceGraphDefinition{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(rgd), current); err != nil {
+			return fmt.Errorf("failed to get current resource graph definition: %w", err)
+		}
+		if equality.Semantic.DeepEqual(current.Status.Rollout, status) {
+			return nil
+		}
+
+		dc := current.DeepCopy()
+		dc.Status.Rollout = status
+		return r.Status().Patch(ctx, dc, client.MergeFrom(current))
+	})
+}
+
+// instanceRevision returns the revision an instance is reconciled with. New
+// instances use the latest revision, and instances managed before the rollout
+// policy was set use the previous ones.
+func instanceRevision(instance *unstructured.Unstructured, latest int64) int64 {
+	value, ok := instance.GetAnnotations()[metadata.ResourceGraphDefinitionRevisionAnnotation]
+	if !ok {
+		if managed, _ := metadata.HasInstanceFinalizerUnstructured(instance); !managed {
+			return latest
+		}
+		return 0
+	}
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// setInstanceRevision updates the metadata.ResourceGraphDefinitionRevisionAnnotation
+// of an instance.
+func setInstanceRevision(ctx context.Context, client dynamic.ResourceInterface, name string, revision int64) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				metadata.ResourceGraphDefinitionRevisionAnnotation: strconv.FormatInt(revision, 10),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// isFailingInstanceState returns true for the states of degraded or failing
+// instances.
+func isFailingInstanceState(state string) bool {
+	switch state {
+	case instancectrl.InstanceStateDegraded, instancectrl.InstanceStateError, instancectrl.InstanceStateFailed:
+		return true
+	}
+	return false
+}
+
+func instanceName(instance *unstructured.Unstructured) string {
+	return types.NamespacedName{Namespace: instance.GetNamespace(), Name: instance.GetName()}.String()
+}
+
+// summarizeInstances joins the first instance names, for messages.
+func summarizeInstances(names []string) string {
+	if len(names) <= maxReportedInstances {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:maxReportedInstances], ", "), len(names)-maxReportedInstances)
+}
+
+// listInstances lists the instances in the scope of the dynamic controller,
+// namespace by namespace when the scope lists the namespaces, so that the
+// controller doesn't need permissions in the other namespaces.
+func (r *ResourceGraphDefinitionReconciler) listInstances(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+) ([]unstructured.Unstructured, error) {
+	namespaces := r.dynamicController.Scope(gvr).Namespaces
+	if namespaces == nil {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var instances []unstructured.Unstructured
+	for _, namespace := range namespaces {
+		list, err := r.clientSet.Dynamic().Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range list.Items {
+			if r.dynamicController.InScope(gvr, &instance) {
+				instances = append(instances, instance)
+			}
+		}
+	}
+	return instances, nil
+}
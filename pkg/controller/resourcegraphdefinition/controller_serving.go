@@ -0,0 +1,111 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// crdRequeueDuration is the interval at which replicas that aren't elected
+// check whether the CRD of a ResourceGraphDefinition is ready.
+const crdRequeueDuration = 5 * time.Second
+
+// graphServer serves the graphs of the ResourceGraphDefinitions on the
+// replicas that aren't elected, when instances are sharded. It sets up the
+// micro controllers reconciling the instances of the buckets of the replica,
+// and leaves the ResourceGraphDefinitions, their CRDs and revisions to the
+// elected replica.
+type graphServer struct {
+	*ResourceGraphDefinitionReconciler
+}
+
+func (s graphServer) Reconcile(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) (ctrl.Result, error) {
+	if s.isElected() {
+		// The elected replica serves the graphs as it reconciles the
+		// ResourceGraphDefinitions.
+		return ctrl.Result{}, nil
+	}
+	defer s.startup.observe(rgd.Name)
+
+	if !rgd.DeletionTimestamp.IsZero() {
+		gvr := metadata.GetResourceGraphDefinitionInstanceGVR(rgd.Spec.Schema.Group, rgd.Spec.Schema.APIVersion, rgd.Spec.Schema.Kind)
+		if err := s.shutdownResourceGraphDefinitionMicroController(ctx, &gvr); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to shutdown microcontroller: %w", err)
+		}
+		s.forgetServiceAccounts(ctx, rgd)
+		s.revisions.Delete(rgd.Name)
+		return ctrl.Result{}, nil
+	}
+
+	processedRGD, _, err := s.reconcileResourceGraphDefinitionGraph(ctx, rgd)
+	if err != nil {
+		// Keep reconciling the instances with the last graph that was built
+		s.serveLastKnownGoodGraph(ctx, rgd)
+		return ctrl.Result{}, err
+	}
+
+	// The CRD is created by the elected replica.
+	crd := processedRGD.Instance.GetCRD()
+	current, err := s.crdManager.Get(ctx, crd.Name)
+	if apierrors.IsNotFound(err) || (err == nil && !crdEstablished(current)) {
+		ctrl.LoggerFrom(ctx).V(1).Info("waiting for the CRD to be ready", "crd", crd.Name)
+		return ctrl.Result{RequeueAfter: crdRequeueDuration}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get CRD %s: %w", crd.Name, err)
+	}
+
+	labeler, err := s.setupLabeler(rgd)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to setup labeler: %w", err)
+	}
+	s.syncServiceAccounts(ctx, rgd)
+
+	// The revision of the generation may not be recorded yet, it is only
+	// needed by the instances pinned to it.
+	revisions, err := s.listRevisions(ctx, rgd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	hash, err := graphHash(&rgd.Spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	revision, previousRevision, _ := currentRevision(revisions, rgd.Generation, hash)
+
+	if err := s.serveGraph(ctx, rgd, processedRGD, revision, previousRevision, labeler); err != nil {
+		return ctrl.Result{}, err
+	}
+	s.graphRevisions(rgd.Name).setGeneration(rgd.Generation)
+	return ctrl.Result{}, nil
+}
+
+// crdEstablished returns true if the CRD is established.
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established {
+			return condition.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
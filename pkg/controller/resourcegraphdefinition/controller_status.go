@@ -61,6 +61,17 @@ func (sp *StatusProcessor) processGraphError(err error) {
 	sp.state = v1alpha1.ResourceGraphDefinitionStateInactive
 }
 
+// processStaleGraphError handles graph-related errors of a new generation,
+// while the micro controller keeps serving the graph of a previous one.
+func (sp *StatusProcessor) processStaleGraphError(err error, activeGeneration int64) {
+	sp.conditions = []v1alpha1.Condition{
+		newGraphVerifiedCondition(metav1.ConditionFalse, err.Error()),
+		newReconcilerReadyCondition(metav1.ConditionTrue, fmt.Sprintf("Serving generation %d", activeGeneration)),
+		newCustomResourceDefinitionSyncedCondition(metav1.ConditionTrue, ""),
+	}
+	sp.state = v1alpha1.ResourceGraphDefinitionStateActive
+}
+
 // processCRDError handles CRD-related errors
 func (sp *StatusProcessor) processCRDError(err error) {
 	sp.conditions = []v1alpha1.Condition{
@@ -88,23 +99,29 @@ func (r *ResourceGraphDefinitionReconciler) setResourceGraphDefinitionStatus(
 	resourcegraphdefinition *v1alpha1.ResourceGraphDefinition,
 	topologicalOrder []string,
 	resources []v1alpha1.ResourceInformation,
+	accessCondition *v1alpha1.Condition,
 	reconcileErr error,
 ) error {
 	log, _ := logr.FromContext(ctx)
 	log.V(1).Info("calculating resource graph definition status and conditions")
 
 	processor := NewStatusProcessor()
+	activeGeneration := r.graphRevisions(resourcegraphdefinition.Name).servedRevision()
+	var failedGeneration int64
 
 	if reconcileErr == nil {
 		processor.setDefaultConditions()
 	} else {
 		log.V(1).Info("processing reconciliation error", "error", reconcileErr)
+		failedGeneration = resourcegraphdefinition.Generation
 
 		var graphErr *graphError
 		var crdErr *crdError
 		var microControllerErr *microControllerError
 
 		switch {
+		case errors.As(reconcileErr, &graphErr) && activeGeneration != 0:
+			processor.processStaleGraphError(reconcileErr, activeGeneration)
 		case errors.As(reconcileErr, &graphErr):
 			processor.processGraphError(reconcileErr)
 		case errors.As(reconcileErr, &crdErr):
@@ -117,6 +134,10 @@ func (r *ResourceGraphDefinitionReconciler) setResourceGraphDefinitionStatus(
 		}
 	}
 
+	if accessCondition != nil {
+		processor.conditions = append(processor.conditions, *accessCondition)
+	}
+
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Get fresh copy to avoid conflicts
 		current := &v1alpha1.ResourceGraphDefinition{}
@@ -130,10 +151,13 @@ func (r *ResourceGraphDefinitionReconciler) setResourceGraphDefinitionStatus(
 		dc.Status.State = processor.state
 		dc.Status.TopologicalOrder = topologicalOrder
 		dc.Status.Resources = resources
+		dc.Status.ActiveGeneration = activeGeneration
+		dc.Status.FailedGeneration = failedGeneration
 
 		log.V(1).Info("updating resource graph definition status",
 			"state", dc.Status.State,
 			"conditions", len(dc.Status.Conditions),
+			"activeGeneration", dc.Status.ActiveGeneration,
 		)
 
 		return r.Status().Patch(ctx, dc, client.MergeFrom(current))
@@ -0,0 +1,52 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// Event reasons recorded on ResourceGraphDefinitions. Repeated events (e.g the
+// same graph error on every retry) are aggregated by the event broadcaster.
+const (
+	EventReasonGraphBuildFailed      = "GraphBuildFailed"
+	EventReasonCRDCreated            = "CustomResourceDefinitionCreated"
+	EventReasonCRDUpdated            = "CustomResourceDefinitionUpdated"
+	EventReasonCRDSyncFailed         = "CustomResourceDefinitionSyncFailed"
+	EventReasonMicroControllerFailed = "MicroControllerFailed"
+	EventReasonMissingPermissions    = "MissingPermissions"
+	EventReasonRolloutHalted         = "RolloutHalted"
+	EventReasonRolloutComplete       = "RolloutComplete"
+	EventReasonRevisionFailed        = "RevisionFailed"
+	EventReasonServingPreviousGraph  = "ServingPreviousGraph"
+)
+
+// recordEvent records a normal event on the resource graph definition.
+func (r *ResourceGraphDefinitionReconciler) recordEvent(rgd *v1alpha1.ResourceGraphDefinition, reason, messageFmt string, args ...interface{}) {
+	// Replicas that aren't elected only serve the graphs.
+	if r.recorder == nil || !r.isElected() {
+		return
+	}
+	r.recorder.Eventf(rgd, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// recordWarning records a warning event on the resource graph definition.
+func (r *ResourceGraphDefinitionReconciler) recordWarning(rgd *v1alpha1.ResourceGraphDefinition, reason, messageFmt string, args ...interface{}) {
+	if r.recorder == nil || !r.isElected() {
+		return
+	}
+	r.recorder.Eventf(rgd, corev1.EventTypeWarning, reason, messageFmt, args...)
+}
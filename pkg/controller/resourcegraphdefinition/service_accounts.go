@@ -0,0 +1,84 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resourcegraphdefinition
+
+import (
+	"context"
+	"maps"
+
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/kro-run/kro/api/v1alpha1"
+)
+
+// syncServiceAccounts records the DefaultServiceAccounts of the given
+// ResourceGraphDefinition, and invalidates the cached impersonated clients of
+// the service accounts that were removed or replaced since the last reconciliation.
+func (r *ResourceGraphDefinitionReconciler) syncServiceAccounts(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) {
+	current := maps.Clone(rgd.Spec.DefaultServiceAccounts)
+	previous, loaded := r.serviceAccounts.Swap(rgd.Name, current)
+	if !loaded {
+		return
+	}
+	r.invalidateServiceAccounts(ctx, staleServiceAccounts(previous.(map[string]string), current))
+}
+
+// forgetServiceAccounts stops tracking the DefaultServiceAccounts of the given
+// ResourceGraphDefinition and invalidates all their cached impersonated clients.
+func (r *ResourceGraphDefinitionReconciler) forgetServiceAccounts(ctx context.Context, rgd *v1alpha1.ResourceGraphDefinition) {
+	previous, loaded := r.serviceAccounts.LoadAndDelete(rgd.Name)
+	if !loaded {
+		return
+	}
+	r.invalidateServiceAccounts(ctx, previous.(map[string]string))
+}
+
+// invalidateServiceAccounts removes the cached clients impersonating any of the
+// given service accounts. The map follows the DefaultServiceAccounts format,
+// where the v1alpha1.DefaultServiceAccountKey applies to every namespace.
+func (r *ResourceGraphDefinitionReconciler) invalidateServiceAccounts(ctx context.Context, serviceAccounts map[string]string) {
+	if len(serviceAccounts) == 0 {
+		return
+	}
+	removed := r.impersonationCache.Invalidate(func(user string) bool {
+		namespace, name, err := serviceaccount.SplitUsername(user)
+		if err != nil {
+			return false
+		}
+		if sa, ok := serviceAccounts[namespace]; ok && sa == name {
+			return true
+		}
+		sa, ok := serviceAccounts[v1alpha1.DefaultServiceAccountKey]
+		return ok && sa == name
+	})
+	if removed > 0 {
+		ctrl.LoggerFrom(ctx).V(1).Info("invalidated impersonated clients", "count", removed)
+	}
+}
+
+// staleServiceAccounts returns the entries of previous that are missing or
+// different in current.
+func staleServiceAccounts(previous, current map[string]string) map[string]string {
+	stale := make(map[string]string)
+	for namespace, sa := range previous {
+		if sa == "" {
+			continue
+		}
+		if current[namespace] != sa {
+			stale[namespace] = sa
+		}
+	}
+	return stale
+}
@@ -57,26 +57,35 @@ package dynamiccontroller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
-	"golang.org/x/time/rate"
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	metadataclient "k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/kro-run/kro/pkg/metadata"
 	"github.com/kro-run/kro/pkg/requeue"
+	"github.com/kro-run/kro/pkg/tracing"
 )
 
 // Config holds the configuration for DynamicController
@@ -87,11 +96,22 @@ type Config struct {
 	// the resources, even if there haven't been any changes.
 	ResyncPeriod time.Duration
 	// QueueMaxRetries is the maximum number of retries for an item in the queue
-	// will be retried before being dropped.
+	// before it is stalled. Stalled items are re-admitted after
+	// StalledRetryDelay.
 	//
 	// NOTE(a-hilaly): I'm not very sure how useful is this, i'm trying to avoid
 	// situations where reconcile errors exhaust the queue.
 	QueueMaxRetries int
+	// StalledRetryDelay is the delay before a stalled item is re-admitted to
+	// the queue. It doubles each time the item stalls again, up to
+	// MaxStalledRetryDelay.
+	StalledRetryDelay time.Duration
+	// MaxStalledRetryDelay is the maximum delay before a stalled item is
+	// re-admitted to the queue.
+	MaxStalledRetryDelay time.Duration
+	// ProgressDeadline is the duration after which LivenessCheck fails if
+	// items are queued but the workers made no progress. 0 disables the check.
+	ProgressDeadline time.Duration
 	// ShutdownTimeout is the maximum duration to wait for the controller to
 	// gracefully shutdown. We ideally want to avoid forceful shutdowns, giving
 	// the controller enough time to finish processing any pending items.
@@ -100,10 +120,51 @@ type Config struct {
 	MinRetryDelay time.Duration
 	// MaxRetryDelay is the maximum delay before retrying an item in the queue
 	MaxRetryDelay time.Duration
-	// RateLimit is the maximum number of events processed per second
+	// RateLimit is the maximum number of events processed per second, per
+	// GVR.
 	RateLimit int
-	// BurstLimit is the maximum number of events in a burst
+	// BurstLimit is the maximum number of events in a burst, per GVR.
 	BurstLimit int
+	// MaxConcurrentReconcilesPerGVR is the default maximum number of items of
+	// a GVR processed at a time. 0 means no limit other than the number of
+	// workers.
+	MaxConcurrentReconcilesPerGVR int
+	// Sharder, when set, shards the objects across the controller replicas.
+	// Every replica then runs the controller, and only watches and reconciles
+	// the objects of its shard.
+	Sharder Sharder
+	// Namespaces are the namespaces the controller watches and reconciles
+	// objects in. nil means all the namespaces.
+	Namespaces []string
+	// NamespaceSelector selects the namespaces the controller watches and
+	// reconciles objects in by label. nil means all the namespaces.
+	NamespaceSelector labels.Selector
+	// MetadataClient, when set, makes the informers cache the metadata of the
+	// objects only, which is enough to trigger reconciles since the handlers
+	// read the objects from the API server.
+	MetadataClient metadataclient.Interface
+	// MaxCachedAnnotationSize is the size, in bytes, above which annotation
+	// values are stripped from the objects cached by the informers. Defaults
+	// to DefaultMaxCachedAnnotationSize.
+	MaxCachedAnnotationSize int
+}
+
+// Sharder assigns the objects served by the controller to the shards of the
+// controller replicas. Objects are labeled with their shard in the
+// metadata.ShardLabel, so that the informers only watch the objects of the
+// shard of the replica.
+type Sharder interface {
+	// Ready returns a channel that is closed once the shards are assigned.
+	Ready() <-chan struct{}
+	// Rebalanced returns a channel receiving a value when the shard of the
+	// replica changes.
+	Rebalanced() <-chan struct{}
+	// Selector returns the label selector of the objects the replica
+	// watches. It must match the objects that aren't labeled yet.
+	Selector() labels.Selector
+	// Assign returns the value of the metadata.ShardLabel of an object, and
+	// whether the replica owns it.
+	Assign(obj metav1.Object) (string, bool)
 }
 
 // DynamicController (DC) is a single controller capable of managing multiple different
@@ -130,34 +191,110 @@ type DynamicController struct {
 	// handler is responsible for managing a specific GVR.
 	handlers sync.Map
 
-	// queue is the workqueue used to process items
-	queue workqueue.TypedRateLimitingInterface[ObjectIdentifiers]
+	// scopes is a safe map of GVR to the Scope set with SetScope.
+	scopes sync.Map
+
+	// stalledHandlers is a safe map of GVR to the StalledHandler set with
+	// SetStalledHandler.
+	stalledHandlers sync.Map
+	// stalled tracks the items that failed more than QueueMaxRetries times.
+	stalled *stalledItems
+	// lastProgress is the time, in unix nanoseconds, a worker last took or
+	// finished an item. It is 0 until the controller starts.
+	lastProgress atomic.Int64
+
+	// namespaces is the informer of the namespaces, started when a scope
+	// selects namespaces by label.
+	namespaces   cache.SharedIndexInformer
+	namespacesMu sync.Mutex
+
+	// queue is the workqueue used to process items. It has a sub-queue per
+	// GVR, so that GVRs are processed fairly.
+	queue *fairQueue
+
+	// ctx is the context the controller runs with. Informers are stopped when
+	// it is canceled.
+	ctx context.Context
+	// started is closed once the controller runs, and ctx is set.
+	started chan struct{}
 
 	log logr.Logger
 }
 
+var _ manager.LeaderElectionRunnable = &DynamicController{}
+
+// errNoHandler is returned when syncing an item of a GVR that isn't served.
+var errNoHandler = errors.New("no handler found for GVR")
+
 type Handler func(ctx context.Context, req ctrl.Request) error
 
 type informerWrapper struct {
-	informer dynamicinformer.DynamicSharedInformerFactory
+	// informers holds the informer factory of each watched namespace, or of
+	// metav1.NamespaceAll when the GVR is watched in all the namespaces.
+	informers map[string]informerFactory
+	// scope is the scope the informers were started with.
+	scope    Scope
 	shutdown func()
 }
 
+// informer returns the informer of the GVR watching a namespace, or nil if
+// the namespace isn't watched.
+func (w *informerWrapper) informer(gvr schema.GroupVersionResource, namespace string) cache.SharedIndexInformer {
+	factory, ok := w.informers[metav1.NamespaceAll]
+	if !ok {
+		factory, ok = w.informers[namespace]
+	}
+	if !ok {
+		return nil
+	}
+	return factory.ForResource(gvr).Informer()
+}
+
+// hasSynced returns true once the informers of all the namespaces synced.
+func (w *informerWrapper) hasSynced(gvr schema.GroupVersionResource) bool {
+	for _, factory := range w.informers {
+		if !factory.ForResource(gvr).Informer().HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// Shutdown waits for the informers of all the namespaces to shut down.
+func (w *informerWrapper) Shutdown() {
+	for _, factory := range w.informers {
+		factory.Shutdown()
+	}
+}
+
 // NewDynamicController creates a new DynamicController instance.
 func NewDynamicController(
 	log logr.Logger,
 	config Config,
 	kubeClient dynamic.Interface) *DynamicController {
 	logger := log.WithName("dynamic-controller")
+	if config.MaxCachedAnnotationSize <= 0 {
+		config.MaxCachedAnnotationSize = DefaultMaxCachedAnnotationSize
+	}
+	if config.StalledRetryDelay <= 0 {
+		config.StalledRetryDelay = DefaultStalledRetryDelay
+	}
+	if config.MaxStalledRetryDelay <= 0 {
+		config.MaxStalledRetryDelay = DefaultMaxStalledRetryDelay
+	}
 
 	dc := &DynamicController{
 		config:     config,
 		kubeClient: kubeClient,
-		queue: workqueue.NewTypedRateLimitingQueueWithConfig(workqueue.NewTypedMaxOfRateLimiter(
-			workqueue.NewTypedItemExponentialFailureRateLimiter[ObjectIdentifiers](config.MinRetryDelay, config.MaxRetryDelay),
-			&workqueue.TypedBucketRateLimiter[ObjectIdentifiers]{Limiter: rate.NewLimiter(rate.Limit(config.RateLimit), config.BurstLimit)},
-		), workqueue.TypedRateLimitingQueueConfig[ObjectIdentifiers]{Name: "dynamic-controller-queue"}),
-		log: logger,
+		queue: newFairQueue(QueueConfig{
+			Weight:                  1,
+			MaxConcurrentReconciles: config.MaxConcurrentReconcilesPerGVR,
+			RateLimit:               config.RateLimit,
+			BurstLimit:              config.BurstLimit,
+		}, config.MinRetryDelay, config.MaxRetryDelay),
+		stalled: newStalledItems(),
+		started: make(chan struct{}),
+		log:     logger,
 		// pass version and pod id from env
 	}
 
@@ -167,31 +304,25 @@ func NewDynamicController(
 // AllInformerHaveSynced checks if all registered informers have synced, returns
 // true if they have.
 func (dc *DynamicController) AllInformerHaveSynced() bool {
-	var allSynced bool
-	var informerCount int
+	allSynced := true
 
 	// Unfortunately we can't know the number of informers in advance, so we need to
 	// iterate over all of them to check if they have synced.
 
 	dc.informers.Range(func(key, value interface{}) bool {
-		informerCount++
-		// possibly panic if the value is not a SharedIndexInformer
-		informer, ok := value.(cache.SharedIndexInformer)
+		wrapper, ok := value.(*informerWrapper)
 		if !ok {
 			dc.log.Error(nil, "Failed to cast informer", "key", key)
 			allSynced = false
 			return false
 		}
-		if !informer.HasSynced() {
+		if !wrapper.hasSynced(key.(schema.GroupVersionResource)) {
 			allSynced = false
 			return false
 		}
 		return true
 	})
 
-	if informerCount == 0 {
-		return true
-	}
 	return allSynced
 }
 
@@ -206,13 +337,35 @@ func (dc *DynamicController) WaitForInformersSync(stopCh <-chan struct{}) bool {
 	return cache.WaitForCacheSync(stopCh, dc.AllInformerHaveSynced)
 }
 
-// Run starts the DynamicController.
-func (dc *DynamicController) Run(ctx context.Context) error {
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Only the
+// leader reconciles instances, so the controller, and the informers it runs,
+// only start once the manager is elected. When the objects are sharded, every
+// replica runs the controller.
+func (dc *DynamicController) NeedLeaderElection() bool {
+	return dc.config.Sharder == nil
+}
+
+// Start runs the DynamicController until the context is canceled, and then
+// shuts it down gracefully. It implements manager.Runnable.
+func (dc *DynamicController) Start(ctx context.Context) error {
 	defer utilruntime.HandleCrash()
 	defer dc.queue.ShutDown()
 
 	dc.log.Info("Starting dynamic controller")
 	defer dc.log.Info("Shutting down dynamic controller")
+	dc.progressed()
+
+	if dc.config.Sharder != nil {
+		select {
+		case <-dc.config.Sharder.Ready():
+		case <-ctx.Done():
+			return nil
+		}
+		go dc.rebalance(ctx)
+	}
+
+	dc.ctx = ctx
+	close(dc.started)
 
 	// Wait for all informers to sync
 	if !dc.WaitForInformersSync(ctx.Done()) {
@@ -222,12 +375,50 @@ func (dc *DynamicController) Run(ctx context.Context) error {
 	// Spin up workers.
 	//
 	// TODO(a-hilaly): Allow for dynamic scaling of workers.
+	var workers sync.WaitGroup
 	for i := 0; i < dc.config.Workers; i++ {
-		go wait.UntilWithContext(ctx, dc.worker, time.Second)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			wait.UntilWithContext(ctx, dc.worker, time.Second)
+		}()
 	}
 
 	<-ctx.Done()
-	return dc.gracefulShutdown(dc.config.ShutdownTimeout)
+	return dc.gracefulShutdown(dc.config.ShutdownTimeout, &workers)
+}
+
+// rebalance restarts the informers with the selector of the shard of the
+// replica when it changes, until the context is canceled.
+func (dc *DynamicController) rebalance(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-dc.config.Sharder.Rebalanced():
+		}
+
+		dc.log.Info("Restarting informers after shard rebalancing")
+		dc.informers.Range(func(key, value interface{}) bool {
+			gvr := key.(schema.GroupVersionResource)
+			dc.stopInformers(gvr)
+			if err := dc.startInformers(ctx, gvr, value.(*informerWrapper).scope); err != nil {
+				dc.log.Error(err, "Failed to restart informer", "gvr", gvr)
+			}
+			return true
+		})
+	}
+}
+
+// runContext returns the context the controller runs with, waiting for the
+// controller to start, e.g for the manager to be elected.
+func (dc *DynamicController) runContext(ctx context.Context) (context.Context, error) {
+	select {
+	case <-dc.started:
+		return dc.ctx, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("dynamic controller is not running: %w", ctx.Err())
+	}
 }
 
 // worker processes items from the queue.
@@ -242,6 +433,8 @@ func (dc *DynamicController) processNextWorkItem(ctx context.Context) bool {
 	if shutdown {
 		return false
 	}
+	dc.progressed()
+	defer dc.progressed()
 	defer dc.queue.Done(item)
 
 	queueLength.Set(float64(dc.queue.Len()))
@@ -249,6 +442,13 @@ func (dc *DynamicController) processNextWorkItem(ctx context.Context) bool {
 	err := dc.syncFunc(ctx, item)
 	if err == nil || apierrors.IsNotFound(err) {
 		dc.queue.Forget(item)
+		dc.stalled.remove(item)
+		return true
+	}
+	if errors.Is(err, errNoHandler) {
+		dc.log.V(1).Info("Dropping item of a GVR that is not served", "item", item)
+		dc.queue.Forget(item)
+		dc.stalled.remove(item)
 		return true
 	}
 
@@ -260,24 +460,29 @@ func (dc *DynamicController) processNextWorkItem(ctx context.Context) bool {
 		dc.log.Error(typedErr, "Error syncing item, not requeuing", "item", item)
 		requeueTotal.WithLabelValues(gvrKey, "no_requeue").Inc()
 		dc.queue.Forget(item)
+		dc.stalled.remove(item)
 	case *requeue.RequeueNeeded:
 		dc.log.V(1).Info("Requeue needed", "item", item, "error", typedErr)
 		requeueTotal.WithLabelValues(gvrKey, "requeue").Inc()
+		dc.stalled.remove(item)
 		dc.queue.Add(item) // Add without rate limiting
 	case *requeue.RequeueNeededAfter:
 		dc.log.V(1).Info("Requeue needed after delay", "item", item, "error", typedErr, "delay", typedErr.Duration())
 		requeueTotal.WithLabelValues(gvrKey, "requeue_after").Inc()
+		dc.stalled.remove(item)
 		dc.queue.AddAfter(item, typedErr.Duration())
 	default:
 		// Arriving here means we have an unexpected error, we should requeue the item
-		// with rate limiting.
-		requeueTotal.WithLabelValues(gvrKey, "rate_limited").Inc()
-		if dc.queue.NumRequeues(item) < dc.config.QueueMaxRetries {
+		// with rate limiting. Items failing past the max retries, or failing
+		// again once re-admitted, are stalled.
+		switch {
+		case dc.stalled.has(item) || dc.queue.NumRequeues(item) >= dc.config.QueueMaxRetries:
+			requeueTotal.WithLabelValues(gvrKey, "stalled").Inc()
+			dc.stall(ctx, item, err)
+		default:
+			requeueTotal.WithLabelValues(gvrKey, "rate_limited").Inc()
 			dc.log.Error(err, "Error syncing item, requeuing with rate limit", "item", item)
 			dc.queue.AddRateLimited(item)
-		} else {
-			dc.log.Error(err, "Dropping item from queue after max retries", "item", item)
-			dc.queue.Forget(item)
 		}
 	}
 
@@ -285,10 +490,19 @@ func (dc *DynamicController) processNextWorkItem(ctx context.Context) bool {
 }
 
 // syncFunc reconciles a single item.
-func (dc *DynamicController) syncFunc(ctx context.Context, oi ObjectIdentifiers) error {
+func (dc *DynamicController) syncFunc(ctx context.Context, oi ObjectIdentifiers) (err error) {
 	gvrKey := fmt.Sprintf("%s/%s/%s", oi.GVR.Group, oi.GVR.Version, oi.GVR.Resource)
 	dc.log.V(1).Info("Syncing resourcegraphdefinition instance request", "gvr", gvrKey, "namespacedKey", oi.NamespacedKey)
 
+	// The number of requeues helps telling apart time spent reconciling from
+	// time spent backing off in the queue.
+	ctx, span := tracing.Start(ctx, "dynamiccontroller.sync",
+		attribute.String("kro.gvr", gvrKey),
+		attribute.String("kro.namespaced_key", oi.NamespacedKey),
+		attribute.Int("kro.queue.requeues", dc.queue.NumRequeues(oi)),
+	)
+	defer tracing.End(span, &err)
+
 	startTime := time.Now()
 	defer func() {
 		duration := time.Since(startTime)
@@ -302,9 +516,9 @@ func (dc *DynamicController) syncFunc(ctx context.Context, oi ObjectIdentifiers)
 
 	genericHandler, ok := dc.handlers.Load(oi.GVR)
 	if !ok {
-		// NOTE(a-hilaly): this might mean that the GVR is not registered, or the workflow operator
-		// is not found. We should probably handle this in a better way.
-		return fmt.Errorf("no handler found for GVR: %s", gvrKey)
+		// The GVR is not served anymore, e.g the ResourceGraphDefinition was
+		// deleted while its items were queued.
+		return fmt.Errorf("%w: %s", errNoHandler, gvrKey)
 	}
 
 	// this is worth a panic if it fails...
@@ -312,26 +526,87 @@ func (dc *DynamicController) syncFunc(ctx context.Context, oi ObjectIdentifiers)
 	if !ok {
 		return fmt.Errorf("invalid handler type for GVR: %s", gvrKey)
 	}
-	err := handlerFunc(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: oi.NamespacedKey}})
+
+	if dc.config.Sharder != nil {
+		owned, err := dc.assignShard(ctx, oi)
+		if err != nil || !owned {
+			return err
+		}
+	}
+
+	err = handlerFunc(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: oi.NamespacedKey}})
 	if err != nil {
 		handlerErrorsTotal.WithLabelValues(gvrKey).Inc()
 	}
 	return err
 }
 
-// gracefulShutdown performs a graceful shutdown of the controller.
-func (dc *DynamicController) gracefulShutdown(timeout time.Duration) error {
+// assignShard labels the object with its shard if it isn't, and returns
+// whether the replica owns it. Objects that are not in the informer cache
+// anymore, e.g because they moved to another shard, are not owned.
+func (dc *DynamicController) assignShard(ctx context.Context, oi ObjectIdentifiers) (bool, error) {
+	value, ok := dc.informers.Load(oi.GVR)
+	if !ok {
+		return false, nil
+	}
+	namespace, _, err := cache.SplitMetaNamespaceKey(oi.NamespacedKey)
+	if err != nil {
+		return false, err
+	}
+	informer := value.(*informerWrapper).informer(oi.GVR, namespace)
+	if informer == nil {
+		return false, nil
+	}
+	obj, exists, err := informer.GetIndexer().GetByKey(oi.NamespacedKey)
+	if err != nil || !exists {
+		return false, err
+	}
+	u, err := meta.Accessor(obj)
+	if err != nil {
+		return false, err
+	}
+
+	shard, owned := dc.config.Sharder.Assign(u)
+	if u.GetLabels()[metadata.ShardLabel] != shard {
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]string{metadata.ShardLabel: shard},
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+		_, err = dc.kubeClient.Resource(oi.GVR).Namespace(u.GetNamespace()).Patch(ctx, u.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to label object with its shard: %w", err)
+		}
+		dc.log.V(1).Info("Labeled object with its shard", "gvr", oi.GVR, "namespacedKey", oi.NamespacedKey, "shard", shard)
+	}
+	return owned, nil
+}
+
+// gracefulShutdown performs a graceful shutdown of the controller. It stops
+// handing out items, and waits for the workers to finish the items they are
+// processing and for the informers to shut down.
+func (dc *DynamicController) gracefulShutdown(timeout time.Duration, workers *sync.WaitGroup) error {
 	dc.log.Info("Starting graceful shutdown")
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
+	dc.queue.ShutDown()
+
 	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		workers.Wait()
+	}()
 	dc.informers.Range(func(key, value interface{}) bool {
 		wg.Add(1)
 		go func(informer *informerWrapper) {
 			defer wg.Done()
-			informer.informer.Shutdown()
+			informer.Shutdown()
 		}(value.(*informerWrapper))
 		return true
 	})
@@ -345,9 +620,9 @@ func (dc *DynamicController) gracefulShutdown(timeout time.Duration) error {
 
 	select {
 	case <-done:
-		dc.log.Info("All informers shut down successfully")
+		dc.log.Info("All workers and informers shut down successfully")
 	case <-ctx.Done():
-		dc.log.Error(ctx.Err(), "Timeout waiting for informers to shut down")
+		dc.log.Error(ctx.Err(), "Timeout waiting for workers and informers to shut down")
 		return ctx.Err()
 	}
 
@@ -366,19 +641,22 @@ type ObjectIdentifiers struct {
 }
 
 // updateFunc is the update event handler for the GVR informers
-func (dc *DynamicController) updateFunc(old, new interface{}) {
-	newObj, ok := new.(*unstructured.Unstructured)
-	if !ok {
-		dc.log.Error(nil, "failed to cast new object to unstructured")
+func (dc *DynamicController) updateFunc(gvr schema.GroupVersionResource, old, new interface{}) {
+	newObj, err := meta.Accessor(new)
+	if err != nil {
+		dc.log.Error(err, "failed to get metadata of new object")
 		return
 	}
-	oldObj, ok := old.(*unstructured.Unstructured)
-	if !ok {
-		dc.log.Error(nil, "failed to cast old object to unstructured")
+	oldObj, err := meta.Accessor(old)
+	if err != nil {
+		dc.log.Error(err, "failed to get metadata of old object")
 		return
 	}
 
-	if newObj.GetGeneration() == oldObj.GetGeneration() {
+	// Annotations control how kro reconciles objects (e.g pausing them, or
+	// moving them to a new revision), so their changes are not skipped.
+	if newObj.GetGeneration() == oldObj.GetGeneration() &&
+		maps.Equal(newObj.GetAnnotations(), oldObj.GetAnnotations()) {
 		dc.log.V(2).Info("Skipping update due to unchanged generation",
 			"name", newObj.GetName(),
 			"namespace", newObj.GetNamespace(),
@@ -386,26 +664,34 @@ func (dc *DynamicController) updateFunc(old, new interface{}) {
 		return
 	}
 
-	dc.enqueueObject(new, "update")
+	dc.enqueueObject(gvr, new, "update")
 }
 
-// enqueueObject adds an object to the workqueue
-func (dc *DynamicController) enqueueObject(obj interface{}, eventType string) {
+// enqueueObject adds an object of a GVR to the workqueue. Objects are either
+// Unstructured, or PartialObjectMetadata when the informers cache the
+// metadata only.
+func (dc *DynamicController) enqueueObject(gvr schema.GroupVersionResource, obj interface{}, eventType string) {
 	namespacedKey, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
 	if err != nil {
 		dc.log.Error(err, "Failed to get key for object", "eventType", eventType)
 		return
 	}
 
-	u, ok := obj.(*unstructured.Unstructured)
-	if !ok {
-		err := fmt.Errorf("object is not an Unstructured")
-		dc.log.Error(err, "Failed to cast object to Unstructured", "eventType", eventType, "namespacedKey", namespacedKey)
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, err := meta.Accessor(obj)
+	if err != nil {
+		dc.log.Error(err, "Failed to get metadata of object", "eventType", eventType, "namespacedKey", namespacedKey)
 		return
 	}
 
-	gvk := u.GroupVersionKind()
-	gvr := metadata.GVKtoGVR(gvk)
+	// Objects being deleted are reconciled even when they left the scope, so
+	// that their finalizers are removed.
+	if u.GetDeletionTimestamp() == nil && !dc.InScope(gvr, u) {
+		dc.log.V(2).Info("Skipping object out of scope", "gvr", gvr, "namespacedKey", namespacedKey)
+		return
+	}
 
 	objectIdentifiers := ObjectIdentifiers{
 		NamespacedKey: namespacedKey,
@@ -420,58 +706,100 @@ func (dc *DynamicController) enqueueObject(obj interface{}, eventType string) {
 	dc.queue.Add(objectIdentifiers)
 }
 
-// StartServingGVK registers a new GVK to the informers map safely.
+// StartServingGVK registers a new GVK to the informers map safely. It waits for
+// the controller to start, and the informer runs until the controller stops.
+// The informers of a GVR already served are restarted if its Scope changed.
 func (dc *DynamicController) StartServingGVK(ctx context.Context, gvr schema.GroupVersionResource, handler Handler) error {
 	dc.log.V(1).Info("Registering new GVK", "gvr", gvr)
 
-	_, exists := dc.informers.Load(gvr)
-	if exists {
-		// Even thought the informer is already registered, we should still
-		// still update the handler, as it might have changed.
-		dc.handlers.Store(gvr, handler)
-		return nil
+	runCtx, err := dc.runContext(ctx)
+	if err != nil {
+		return err
 	}
 
-	// Create a new informer
-	gvkInformer := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
-		dc.kubeClient,
-		dc.config.ResyncPeriod,
-		// Maybe we can make this configurable in the future. Thinking that
-		// we might want to filter out some resources, by namespace or labels
-		"",
-		nil,
-	)
+	// Even thought the informer might already be registered, we should still
+	// update the handler, as it might have changed.
+	dc.handlers.Store(gvr, handler)
 
-	informer := gvkInformer.ForResource(gvr).Informer()
+	scope := dc.Scope(gvr)
+	if value, exists := dc.informers.Load(gvr); exists {
+		if value.(*informerWrapper).scope.Equal(scope) {
+			return nil
+		}
+		dc.log.Info("Restarting informers after scope change", "gvr", gvr)
+		dc.stopInformers(gvr)
+	}
 
-	// Set up event handlers
-	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    func(obj interface{}) { dc.enqueueObject(obj, "add") },
-		UpdateFunc: dc.updateFunc,
-		DeleteFunc: func(obj interface{}) { dc.enqueueObject(obj, "delete") },
-	})
-	if err != nil {
-		dc.log.Error(err, "Failed to add event handler", "gvr", gvr)
-		return fmt.Errorf("failed to add event handler for GVR %s: %w", gvr, err)
+	return dc.startInformers(runCtx, gvr, scope)
+}
+
+// startInformers starts the informers of a GVR, one per namespace of its
+// scope, and waits for their caches to sync.
+func (dc *DynamicController) startInformers(ctx context.Context, gvr schema.GroupVersionResource, scope Scope) error {
+	if scope.NamespaceSelector != nil {
+		if err := dc.startNamespaceInformer(ctx); err != nil {
+			return err
+		}
 	}
-	informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
-		dc.log.Error(err, "Watch error", "gvr", gvr)
-	})
-	dc.handlers.Store(gvr, handler)
 
-	informerContext := context.Background()
-	cancelableContext, cancel := context.WithCancel(informerContext)
-	// Start the informer
-	go func() {
-		dc.log.V(1).Info("Starting informer", "gvr", gvr)
-		// time.Sleep(5 * time.Millisecond)
-		informer.Run(cancelableContext.Done())
-	}()
+	// Sharded replicas only watch the objects of their shard.
+	var tweakListOptions func(*metav1.ListOptions)
+	if dc.config.Sharder != nil {
+		selector := dc.config.Sharder.Selector().String()
+		tweakListOptions = func(options *metav1.ListOptions) {
+			options.LabelSelector = selector
+		}
+	}
+
+	// Namespaces are watched separately, so that the controller only needs
+	// permissions in the namespaces it serves.
+	namespaces := scope.Namespaces
+	if namespaces == nil {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	cancelableContext, cancel := context.WithCancel(ctx)
+	wrapper := &informerWrapper{
+		informers: make(map[string]informerFactory, len(namespaces)),
+		scope:     scope,
+		shutdown:  cancel,
+	}
+	for _, namespace := range namespaces {
+		factory := dc.newInformerFactory(namespace, tweakListOptions)
+		informer := factory.ForResource(gvr).Informer()
+
+		// The objects are stripped before they are cached, to reduce the
+		// memory of the informers.
+		if err := informer.SetTransform(stripObject(dc.config.MaxCachedAnnotationSize)); err != nil {
+			cancel()
+			return fmt.Errorf("failed to set transform for GVR %s: %w", gvr, err)
+		}
+
+		// Set up event handlers
+		_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { dc.enqueueObject(gvr, obj, "add") },
+			UpdateFunc: func(old, new interface{}) { dc.updateFunc(gvr, old, new) },
+			DeleteFunc: func(obj interface{}) { dc.enqueueObject(gvr, obj, "delete") },
+		})
+		if err != nil {
+			cancel()
+			dc.log.Error(err, "Failed to add event handler", "gvr", gvr)
+			return fmt.Errorf("failed to add event handler for GVR %s: %w", gvr, err)
+		}
+		informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+			dc.log.Error(err, "Watch error", "gvr", gvr, "namespace", namespace)
+		})
+		wrapper.informers[namespace] = factory
+
+		// Start the informer
+		dc.log.V(1).Info("Starting informer", "gvr", gvr, "namespace", namespace)
+		go informer.Run(cancelableContext.Done())
+	}
 
 	dc.log.V(1).Info("Waiting for cache sync", "gvr", gvr)
 	startTime := time.Now()
 	// Wait for cache sync with a timeout
-	synced := cache.WaitForCacheSync(cancelableContext.Done(), informer.HasSynced)
+	synced := cache.WaitForCacheSync(cancelableContext.Done(), func() bool { return wrapper.hasSynced(gvr) })
 	syncDuration := time.Since(startTime)
 	informerSyncDuration.WithLabelValues(gvr.String()).Observe(syncDuration.Seconds())
 
@@ -480,52 +808,76 @@ func (dc *DynamicController) StartServingGVK(ctx context.Context, gvr schema.Gro
 		return fmt.Errorf("failed to sync informer cache for GVR %s", gvr)
 	}
 
-	dc.informers.Store(gvr, &informerWrapper{
-		informer: gvkInformer,
-		shutdown: cancel,
-	})
+	dc.informers.Store(gvr, wrapper)
 	gvrCount.Inc()
 	dc.log.V(1).Info("Successfully registered GVK", "gvr", gvr)
 	return nil
 }
 
-// UnregisterGVK safely removes a GVK from the controller and cleans up associated resources.
-func (dc *DynamicController) StopServiceGVK(ctx context.Context, gvr schema.GroupVersionResource) error {
-	dc.log.Info("Unregistering GVK", "gvr", gvr)
-
-	// Retrieve the informer
-	informerObj, ok := dc.informers.Load(gvr)
-	if !ok {
-		dc.log.V(1).Info("GVK not registered, nothing to unregister", "gvr", gvr)
-		return nil
+// newInformerFactory returns the factory of the informers of a namespace,
+// caching the metadata of the objects only when the controller has a metadata
+// client.
+func (dc *DynamicController) newInformerFactory(
+	namespace string,
+	tweakListOptions func(*metav1.ListOptions),
+) informerFactory {
+	if dc.config.MetadataClient != nil {
+		return metadatainformer.NewFilteredSharedInformerFactory(
+			dc.config.MetadataClient,
+			dc.config.ResyncPeriod,
+			namespace,
+			tweakListOptions,
+		)
 	}
+	return dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		dc.kubeClient,
+		dc.config.ResyncPeriod,
+		namespace,
+		tweakListOptions,
+	)
+}
 
-	wrapper, ok := informerObj.(*informerWrapper)
+// stopInformers stops the informers of a GVR, and waits for them to shut
+// down.
+func (dc *DynamicController) stopInformers(gvr schema.GroupVersionResource) {
+	value, ok := dc.informers.LoadAndDelete(gvr)
 	if !ok {
-		return fmt.Errorf("invalid informer type for GVR: %s", gvr)
+		return
 	}
+	wrapper := value.(*informerWrapper)
 
-	// Stop the informer
 	dc.log.V(1).Info("Stopping informer", "gvr", gvr)
-
 	// Cancel the context to stop the informer
 	wrapper.shutdown()
 	// Wait for the informer to shut down
-	wrapper.informer.Shutdown()
+	wrapper.Shutdown()
+	gvrCount.Dec()
+}
+
+// SetQueueConfig configures the sub-queue of a GVR, e.g its weight and
+// concurrency. Unset fields use the defaults of the controller.
+func (dc *DynamicController) SetQueueConfig(gvr schema.GroupVersionResource, config QueueConfig) {
+	dc.queue.SetConfig(gvr, config)
+}
 
-	// Remove the informer from the map
-	dc.informers.Delete(gvr)
+// UnregisterGVK safely removes a GVK from the controller and cleans up associated resources.
+func (dc *DynamicController) StopServiceGVK(ctx context.Context, gvr schema.GroupVersionResource) error {
+	dc.log.Info("Unregistering GVK", "gvr", gvr)
+
+	if _, ok := dc.informers.Load(gvr); !ok {
+		dc.log.V(1).Info("GVK not registered, nothing to unregister", "gvr", gvr)
+		return nil
+	}
+	dc.stopInformers(gvr)
 
-	// Unregister the handler if any
+	// Unregister the handlers and the scope if any
 	dc.handlers.Delete(gvr)
+	dc.stalledHandlers.Delete(gvr)
+	dc.scopes.Delete(gvr)
+	dc.stalled.drop(gvr)
 
-	gvrCount.Dec()
 	// Clean up any pending items in the queue for this GVR
-	// NOTE(a-hilaly): This is a bit heavy.. maybe we can find a better way to do this.
-	// Thinking that we might want to have a queue per GVR.
-	// dc.cleanupQueue(gvr)
-	// time.Sleep(1 * time.Second)
-	// isStopped := wrapper.informer.ForResource(gvr).Informer().IsStopped()
+	dc.queue.Drop(gvr)
 	dc.log.V(1).Info("Successfully unregistered GVK", "gvr", gvr)
 	return nil
 }
@@ -67,6 +67,10 @@ func TestNewDynamicController(t *testing.T) {
 		MaxRetryDelay:   1000 * time.Second,
 		RateLimit:       10,
 		BurstLimit:      100,
+
+		MaxCachedAnnotationSize: DefaultMaxCachedAnnotationSize,
+		StalledRetryDelay:       DefaultStalledRetryDelay,
+		MaxStalledRetryDelay:    DefaultMaxStalledRetryDelay,
 	}
 
 	dc := NewDynamicController(logger, config, client)
@@ -102,7 +106,7 @@ func TestRegisterAndUnregisterGVK(t *testing.T) {
 
 	// Start the controller in a goroutine
 	go func() {
-		err := dc.Run(ctx)
+		err := dc.Start(ctx)
 		require.NoError(t, err)
 	}()
 
@@ -148,7 +152,7 @@ func TestEnqueueObject(t *testing.T) {
 	obj.SetNamespace("default")
 	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Test"})
 
-	dc.enqueueObject(obj, "add")
+	dc.enqueueObject(schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}, obj, "add")
 
 	assert.Equal(t, 1, dc.queue.Len())
 }
@@ -0,0 +1,397 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// QueueConfig configures the sub-queue of a GVR.
+type QueueConfig struct {
+	// Weight is the share of the workers the GVR gets while other GVRs have
+	// items queued. A GVR with a weight of 2 gets twice as many items
+	// processed as a GVR with a weight of 1.
+	Weight int
+	// MaxConcurrentReconciles is the maximum number of items of the GVR
+	// processed at a time. 0 means no limit other than the number of workers.
+	MaxConcurrentReconciles int
+	// RateLimit is the maximum number of items of the GVR requeued with rate
+	// limiting per second.
+	RateLimit int
+	// BurstLimit is the maximum number of items of the GVR requeued with rate
+	// limiting in a burst.
+	BurstLimit int
+}
+
+// fairQueue is a work queue with a sub-queue per GVR. Workers dequeue from the
+// GVRs with items in smooth weighted round-robin, so that a GVR with many
+// instances, or with instances that keep failing, doesn't starve the others.
+//
+// Like the client-go work queues, an item is never processed by two workers
+// at a time, and an item added while it is processed is queued again once it
+// is done.
+type fairQueue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	defaults QueueConfig
+	queues   map[schema.GroupVersionResource]*gvrQueue
+	// failures computes the exponential backoff of the failing items.
+	failures workqueue.TypedRateLimiter[ObjectIdentifiers]
+	// waiting holds the items added with a delay, until they are queued.
+	waiting      map[ObjectIdentifiers]*waitingItem
+	length       int
+	shuttingDown bool
+}
+
+// waitingItem is an item added with a delay.
+type waitingItem struct {
+	at    time.Time
+	timer *time.Timer
+}
+
+// gvrQueue is the sub-queue of a GVR.
+type gvrQueue struct {
+	config  QueueConfig
+	limiter *rate.Limiter
+
+	items []ObjectIdentifiers
+	// queued holds the time the queued items were added at.
+	queued map[ObjectIdentifiers]time.Time
+	// processing holds the items handed out to workers.
+	processing map[ObjectIdentifiers]bool
+	// dirty holds the items added while they were processed.
+	dirty map[ObjectIdentifiers]bool
+	// current is the smooth weighted round-robin counter.
+	current int
+}
+
+// newFairQueue creates a new fairQueue, with the default configuration of
+// the GVR sub-queues.
+func newFairQueue(defaults QueueConfig, minRetryDelay, maxRetryDelay time.Duration) *fairQueue {
+	defaults.Weight = max(defaults.Weight, 1)
+	q := &fairQueue{
+		defaults: defaults,
+		queues:   make(map[schema.GroupVersionResource]*gvrQueue),
+		failures: workqueue.NewTypedItemExponentialFailureRateLimiter[ObjectIdentifiers](minRetryDelay, maxRetryDelay),
+		waiting:  make(map[ObjectIdentifiers]*waitingItem),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// SetConfig configures the sub-queue of a GVR. Unset fields use the defaults.
+func (q *fairQueue) SetConfig(gvr schema.GroupVersionResource, config QueueConfig) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	config = q.withDefaults(config)
+	sub := q.queue(gvr)
+	if sub.config != config {
+		sub.config = config
+		sub.limiter = newLimiter(config)
+	}
+	q.cond.Broadcast()
+}
+
+// Config returns the configuration of the sub-queue of a GVR.
+func (q *fairQueue) Config(gvr schema.GroupVersionResource) QueueConfig {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if sub, ok := q.queues[gvr]; ok {
+		return sub.config
+	}
+	return q.defaults
+}
+
+// Drop removes the sub-queue of a GVR, and the items queued or waiting in
+// it.
+func (q *fairQueue) Drop(gvr schema.GroupVersionResource) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// The backoff of the items is forgotten too, so that they start afresh
+	// if the GVR is served again.
+	if sub, ok := q.queues[gvr]; ok {
+		for _, item := range sub.items {
+			q.failures.Forget(item)
+		}
+		for item := range sub.processing {
+			q.failures.Forget(item)
+		}
+		q.length -= len(sub.items)
+		delete(q.queues, gvr)
+	}
+	for item, waiting := range q.waiting {
+		if item.GVR == gvr {
+			waiting.timer.Stop()
+			delete(q.waiting, item)
+			q.failures.Forget(item)
+		}
+	}
+	gvrKey := gvr.String()
+	queueDepth.DeleteLabelValues(gvrKey)
+	queueActiveReconciles.DeleteLabelValues(gvrKey)
+}
+
+// Add queues an item, unless it is already queued.
+func (q *fairQueue) Add(item ObjectIdentifiers) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.shuttingDown {
+		return
+	}
+	sub := q.queue(item.GVR)
+	if _, ok := sub.queued[item]; ok {
+		return
+	}
+	queueAddsTotal.WithLabelValues(item.GVR.String()).Inc()
+	if sub.processing[item] {
+		sub.dirty[item] = true
+		return
+	}
+	q.push(sub, item)
+	q.cond.Signal()
+}
+
+// AddAfter queues an item after a delay. An item waits for a single delay:
+// when it is added again before it is queued, it is queued at the earliest
+// of the two deadlines.
+func (q *fairQueue) AddAfter(item ObjectIdentifiers, delay time.Duration) {
+	if delay <= 0 {
+		q.Add(item)
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	at := time.Now().Add(delay)
+	if waiting, ok := q.waiting[item]; ok {
+		if !at.Before(waiting.at) {
+			return
+		}
+		waiting.timer.Stop()
+	}
+
+	waiting := &waitingItem{at: at}
+	waiting.timer = time.AfterFunc(delay, func() {
+		q.mu.Lock()
+		// The item may have been rescheduled earlier, or dropped, in the
+		// meantime.
+		current := q.waiting[item] == waiting
+		if current {
+			delete(q.waiting, item)
+		}
+		q.mu.Unlock()
+		if current {
+			q.Add(item)
+		}
+	})
+	q.waiting[item] = waiting
+}
+
+// AddRateLimited queues an item after the longest of its exponential backoff
+// and the delay of the rate limit of its GVR.
+func (q *fairQueue) AddRateLimited(item ObjectIdentifiers) {
+	delay := q.failures.When(item)
+
+	q.mu.Lock()
+	limiter := q.queue(item.GVR).limiter
+	q.mu.Unlock()
+	if limited := limiter.Reserve().Delay(); limited > delay {
+		delay = limited
+	}
+	q.AddAfter(item, delay)
+}
+
+// Forget resets the backoff of an item.
+func (q *fairQueue) Forget(item ObjectIdentifiers) {
+	q.failures.Forget(item)
+}
+
+// NumRequeues returns the number of times an item was requeued with rate
+// limiting since it was last forgotten.
+func (q *fairQueue) NumRequeues(item ObjectIdentifiers) int {
+	return q.failures.NumRequeues(item)
+}
+
+// Get blocks until an item can be processed, and returns it. It returns true
+// once the queue is shutting down.
+func (q *fairQueue) Get() (ObjectIdentifiers, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		if q.shuttingDown {
+			return ObjectIdentifiers{}, true
+		}
+		if sub := q.next(); sub != nil {
+			item := sub.items[0]
+			sub.items = sub.items[1:]
+			q.length--
+
+			gvrKey := item.GVR.String()
+			queueWaitDuration.WithLabelValues(gvrKey).Observe(time.Since(sub.queued[item]).Seconds())
+			delete(sub.queued, item)
+			sub.processing[item] = true
+			queueDepth.WithLabelValues(gvrKey).Set(float64(len(sub.items)))
+			queueActiveReconciles.WithLabelValues(gvrKey).Set(float64(len(sub.processing)))
+			return item, false
+		}
+		q.cond.Wait()
+	}
+}
+
+// Done marks an item as processed, and queues it again if it was added while
+// it was processed.
+func (q *fairQueue) Done(item ObjectIdentifiers) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	sub, ok := q.queues[item.GVR]
+	if !ok || !sub.processing[item] {
+		return
+	}
+	delete(sub.processing, item)
+	queueActiveReconciles.WithLabelValues(item.GVR.String()).Set(float64(len(sub.processing)))
+	if sub.dirty[item] {
+		delete(sub.dirty, item)
+		if !q.shuttingDown {
+			q.push(sub, item)
+		}
+	}
+	// The sub-queue may be below its concurrency limit again.
+	q.cond.Broadcast()
+}
+
+// Len returns the number of queued items.
+func (q *fairQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.length
+}
+
+// Oldest returns the time the longest queued item was added at, and false if
+// no item is queued.
+func (q *fairQueue) Oldest() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var oldest time.Time
+	for _, sub := range q.queues {
+		if len(sub.items) == 0 {
+			continue
+		}
+		// Items are appended, the first one is the oldest of the sub-queue.
+		if queued := sub.queued[sub.items[0]]; oldest.IsZero() || queued.Before(oldest) {
+			oldest = queued
+		}
+	}
+	return oldest, !oldest.IsZero()
+}
+
+// ShutDown stops handing out items, and makes the workers waiting for items
+// return.
+func (q *fairQueue) ShutDown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.shuttingDown = true
+	for item, waiting := range q.waiting {
+		waiting.timer.Stop()
+		delete(q.waiting, item)
+	}
+	q.cond.Broadcast()
+}
+
+// next returns the sub-queue the next item is taken from, or nil if no item
+// can be processed. It must be called with the lock held.
+func (q *fairQueue) next() *gvrQueue {
+	var best *gvrQueue
+	total := 0
+	for _, sub := range q.queues {
+		if len(sub.items) == 0 {
+			continue
+		}
+		if limit := sub.config.MaxConcurrentReconciles; limit > 0 && len(sub.processing) >= limit {
+			continue
+		}
+		sub.current += sub.config.Weight
+		total += sub.config.Weight
+		if best == nil || sub.current > best.current {
+			best = sub
+		}
+	}
+	if best != nil {
+		best.current -= total
+	}
+	return best
+}
+
+// queue returns the sub-queue of a GVR, creating it if needed. It must be
+// called with the lock held.
+func (q *fairQueue) queue(gvr schema.GroupVersionResource) *gvrQueue {
+	sub, ok := q.queues[gvr]
+	if !ok {
+		sub = &gvrQueue{
+			config:     q.defaults,
+			limiter:    newLimiter(q.defaults),
+			queued:     make(map[ObjectIdentifiers]time.Time),
+			processing: make(map[ObjectIdentifiers]bool),
+			dirty:      make(map[ObjectIdentifiers]bool),
+		}
+		q.queues[gvr] = sub
+	}
+	return sub
+}
+
+// push appends an item to a sub-queue. It must be called with the lock held.
+func (q *fairQueue) push(sub *gvrQueue, item ObjectIdentifiers) {
+	sub.items = append(sub.items, item)
+	sub.queued[item] = time.Now()
+	q.length++
+	queueDepth.WithLabelValues(item.GVR.String()).Set(float64(len(sub.items)))
+}
+
+func (q *fairQueue) withDefaults(config QueueConfig) QueueConfig {
+	if config.Weight <= 0 {
+		config.Weight = q.defaults.Weight
+	}
+	if config.MaxConcurrentReconciles <= 0 {
+		config.MaxConcurrentReconciles = q.defaults.MaxConcurrentReconciles
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = q.defaults.RateLimit
+	}
+	if config.BurstLimit <= 0 {
+		config.BurstLimit = q.defaults.BurstLimit
+	}
+	return config
+}
+
+func newLimiter(config QueueConfig) *rate.Limiter {
+	if config.RateLimit <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(config.RateLimit), max(config.BurstLimit, 1))
+}
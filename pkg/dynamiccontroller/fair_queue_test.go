@@ -0,0 +1,201 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	busyGVR  = schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "busies"}
+	quietGVR = schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "quiets"}
+)
+
+func newTestFairQueue() *fairQueue {
+	return newFairQueue(QueueConfig{Weight: 1}, 10*time.Millisecond, time.Second)
+}
+
+func item(gvr schema.GroupVersionResource, i int) ObjectIdentifiers {
+	return ObjectIdentifiers{NamespacedKey: fmt.Sprintf("default/instance-%d", i), GVR: gvr}
+}
+
+func TestFairQueueDoesNotStarveGVRs(t *testing.T) {
+	q := newTestFairQueue()
+	for i := 0; i < 100; i++ {
+		q.Add(item(busyGVR, i))
+	}
+	q.Add(item(quietGVR, 0))
+
+	// The quiet GVR is served within the first round.
+	for i := 0; i < 2; i++ {
+		got, shutdown := q.Get()
+		require.False(t, shutdown)
+		q.Done(got)
+		if got.GVR == quietGVR {
+			return
+		}
+	}
+	t.Fatal("quiet GVR was not served in the first round")
+}
+
+func TestFairQueueWeights(t *testing.T) {
+	q := newTestFairQueue()
+	q.SetConfig(busyGVR, QueueConfig{Weight: 3})
+	for i := 0; i < 100; i++ {
+		q.Add(item(busyGVR, i))
+		q.Add(item(quietGVR, i))
+	}
+
+	counts := map[schema.GroupVersionResource]int{}
+	for i := 0; i < 40; i++ {
+		got, _ := q.Get()
+		q.Done(got)
+		counts[got.GVR]++
+	}
+	assert.Equal(t, 30, counts[busyGVR])
+	assert.Equal(t, 10, counts[quietGVR])
+}
+
+func TestFairQueueConcurrencyLimit(t *testing.T) {
+	q := newTestFairQueue()
+	q.SetConfig(busyGVR, QueueConfig{MaxConcurrentReconciles: 1})
+	q.Add(item(busyGVR, 0))
+	q.Add(item(busyGVR, 1))
+	q.Add(item(quietGVR, 0))
+
+	first, _ := q.Get()
+	second, _ := q.Get()
+	assert.NotEqual(t, first.GVR, second.GVR, "only one busy item may be processed at a time")
+
+	// The second busy item is handed out once the first one is done.
+	busy := first
+	if busy.GVR != busyGVR {
+		busy = second
+	}
+	got := make(chan ObjectIdentifiers)
+	go func() {
+		next, _ := q.Get()
+		got <- next
+	}()
+	select {
+	case <-got:
+		t.Fatal("busy item handed out while the concurrency limit is reached")
+	case <-time.After(50 * time.Millisecond):
+	}
+	q.Done(busy)
+	select {
+	case next := <-got:
+		assert.Equal(t, busyGVR, next.GVR)
+	case <-time.After(time.Second):
+		t.Fatal("busy item not handed out after the first one was done")
+	}
+}
+
+func TestFairQueueRequeuesItemsAddedWhileProcessing(t *testing.T) {
+	q := newTestFairQueue()
+	q.Add(item(busyGVR, 0))
+	q.Add(item(busyGVR, 0))
+	assert.Equal(t, 1, q.Len())
+
+	got, _ := q.Get()
+	q.Add(got)
+	assert.Equal(t, 0, q.Len(), "items being processed are not queued twice")
+
+	q.Done(got)
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestFairQueueDropAndShutDown(t *testing.T) {
+	q := newTestFairQueue()
+	q.Add(item(busyGVR, 0))
+	q.Add(item(quietGVR, 0))
+	q.Drop(busyGVR)
+	assert.Equal(t, 1, q.Len())
+
+	q.ShutDown()
+	_, shutdown := q.Get()
+	assert.True(t, shutdown)
+	q.Add(item(busyGVR, 1))
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestFairQueueAddAfterKeepsEarliestDeadline(t *testing.T) {
+	q := newTestFairQueue()
+	waiting := item(busyGVR, 0)
+	q.AddAfter(waiting, time.Hour)
+	q.AddAfter(waiting, 2*time.Hour)
+	q.AddAfter(waiting, 20*time.Millisecond)
+	q.AddAfter(waiting, time.Hour)
+
+	// A single timer is pending, with the earliest deadline.
+	q.mu.Lock()
+	assert.Len(t, q.waiting, 1)
+	assert.WithinDuration(t, time.Now().Add(20*time.Millisecond), q.waiting[waiting].at, 20*time.Millisecond)
+	q.mu.Unlock()
+
+	require.Eventually(t, func() bool { return q.Len() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, q.Len())
+	q.mu.Lock()
+	assert.Empty(t, q.waiting)
+	q.mu.Unlock()
+}
+
+func TestFairQueueDropsWaitingItems(t *testing.T) {
+	q := newTestFairQueue()
+	q.AddAfter(item(busyGVR, 0), 20*time.Millisecond)
+	q.AddAfter(item(quietGVR, 0), 20*time.Millisecond)
+	q.Drop(busyGVR)
+
+	require.Eventually(t, func() bool { return q.Len() == 1 }, time.Second, 5*time.Millisecond)
+	got, _ := q.Get()
+	assert.Equal(t, quietGVR, got.GVR)
+
+	q.AddAfter(item(quietGVR, 1), 20*time.Millisecond)
+	q.ShutDown()
+	q.mu.Lock()
+	assert.Empty(t, q.waiting)
+	q.mu.Unlock()
+}
+
+func TestFairQueueDropForgetsBackoff(t *testing.T) {
+	q := newTestFairQueue()
+	queued, processing, waiting := item(busyGVR, 0), item(busyGVR, 1), item(busyGVR, 2)
+	quiet := item(quietGVR, 0)
+
+	q.Add(processing)
+	got, _ := q.Get()
+	require.Equal(t, processing, got)
+	q.AddRateLimited(processing)
+	q.Add(queued)
+	q.AddRateLimited(queued)
+	q.AddRateLimited(waiting)
+	q.AddRateLimited(waiting)
+	q.AddRateLimited(quiet)
+	for _, it := range []ObjectIdentifiers{queued, processing, waiting, quiet} {
+		require.NotZero(t, q.NumRequeues(it))
+	}
+
+	q.Drop(busyGVR)
+	for _, it := range []ObjectIdentifiers{queued, processing, waiting} {
+		assert.Zero(t, q.NumRequeues(it), it.NamespacedKey)
+	}
+	assert.Equal(t, 1, q.NumRequeues(quiet))
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultProgressDeadline is the default duration after which the workers are
+// considered wedged, if items are queued but none was taken or finished.
+const DefaultProgressDeadline = 10 * time.Minute
+
+// progressed records that a worker took or finished an item.
+func (dc *DynamicController) progressed() {
+	dc.lastProgress.Store(time.Now().UnixNano())
+}
+
+// ReadyCheck fails until the informers of all the served GVRs synced. It
+// implements healthz.Checker.
+func (dc *DynamicController) ReadyCheck(_ *http.Request) error {
+	if !dc.AllInformerHaveSynced() {
+		return fmt.Errorf("informers haven't synced")
+	}
+	return nil
+}
+
+// LivenessCheck fails when the workers are wedged, i.e items have been queued
+// for the ProgressDeadline while no worker took or finished an item, e.g
+// because all of them are stuck in handlers that never return. Idle workers,
+// and a controller that isn't running, e.g waiting to be elected, are
+// healthy. It implements healthz.Checker.
+func (dc *DynamicController) LivenessCheck(_ *http.Request) error {
+	if dc.config.ProgressDeadline <= 0 {
+		return nil
+	}
+	last := dc.lastProgress.Load()
+	if last == 0 {
+		return nil
+	}
+	oldest, ok := dc.queue.Oldest()
+	if !ok {
+		return nil
+	}
+	stuckSince := time.Unix(0, last)
+	if oldest.After(stuckSince) {
+		stuckSince = oldest
+	}
+	if since := time.Since(stuckSince); since > dc.config.ProgressDeadline {
+		return fmt.Errorf("workers made no progress for %s with %d items queued",
+			since.Round(time.Second), dc.queue.Len())
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+)
+
+func TestReadyCheck(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		Workers:         1,
+		ResyncPeriod:    time.Hour,
+		ShutdownTimeout: 5 * time.Second,
+		MinRetryDelay:   200 * time.Millisecond,
+		MaxRetryDelay:   1000 * time.Second,
+	}, setupFakeClient())
+
+	// Without any GVR served, there is nothing to sync.
+	assert.NoError(t, dc.ReadyCheck(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.Start(ctx)
+	}()
+
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	require.NoError(t, dc.StartServingGVK(ctx, gvr, func(ctx context.Context, req controllerruntime.Request) error {
+		return nil
+	}))
+	assert.True(t, dc.AllInformerHaveSynced())
+	assert.NoError(t, dc.ReadyCheck(nil))
+}
+
+func TestLivenessCheck(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		MinRetryDelay:    200 * time.Millisecond,
+		MaxRetryDelay:    1000 * time.Second,
+		ProgressDeadline: 50 * time.Millisecond,
+	}, setupFakeClient())
+
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	item := ObjectIdentifiers{NamespacedKey: "default/test-object", GVR: gvr}
+	dc.queue.Add(item)
+
+	// A controller that isn't running is healthy, even with queued items.
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, dc.LivenessCheck(nil))
+
+	// Idle workers are healthy.
+	dc.queue.Drop(gvr)
+	dc.progressed()
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, dc.LivenessCheck(nil))
+
+	// Items queued after the workers idled are given the deadline, then no
+	// progress means the workers are wedged.
+	dc.queue.Add(item)
+	assert.NoError(t, dc.LivenessCheck(nil))
+	time.Sleep(100 * time.Millisecond)
+	assert.ErrorContains(t, dc.LivenessCheck(nil), "with 1 items queued")
+
+	// Workers taking an item make progress again.
+	dc.handlers.Store(gvr, Handler(func(ctx context.Context, req controllerruntime.Request) error {
+		return nil
+	}))
+	dc.queue.Add(ObjectIdentifiers{NamespacedKey: "default/other-object", GVR: gvr})
+	require.True(t, dc.processNextWorkItem(context.Background()))
+	assert.NoError(t, dc.LivenessCheck(nil))
+}
@@ -30,6 +30,12 @@ func init() {
 		handlerErrorsTotal,
 		informerSyncDuration,
 		informerEventsTotal,
+		queueDepth,
+		queueActiveReconciles,
+		queueAddsTotal,
+		queueWaitDuration,
+		stalledItemsTotal,
+		stalledItemsGauge,
 		// activeWorkersTotal,
 	)
 }
@@ -93,6 +99,49 @@ var (
 		},
 		[]string{"gvr", "event_type"},
 	)
+	queueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dynamic_controller_queue_depth",
+			Help: "Current number of items queued per GVR",
+		},
+		[]string{"gvr"},
+	)
+	queueActiveReconciles = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dynamic_controller_queue_active_reconciles",
+			Help: "Current number of items processed per GVR",
+		},
+		[]string{"gvr"},
+	)
+	queueAddsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dynamic_controller_queue_adds_total",
+			Help: "Total number of items added to the queue per GVR",
+		},
+		[]string{"gvr"},
+	)
+	queueWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "dynamic_controller_queue_wait_duration_seconds",
+			Help:    "Duration items wait in the queue before being processed per GVR",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+		[]string{"gvr"},
+	)
+	stalledItemsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dynamic_controller_stalled_items_total",
+			Help: "Total number of items stalled after max retries per GVR",
+		},
+		[]string{"gvr"},
+	)
+	stalledItemsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dynamic_controller_stalled_items",
+			Help: "Current number of stalled items per GVR",
+		},
+		[]string{"gvr"},
+	)
 	/* activeWorkersTotal = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "dynamic_controller_active_workers_total",
@@ -0,0 +1,206 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// Scope restricts the objects of a GVR the controller watches and reconciles
+// to a set of namespaces.
+type Scope struct {
+	// Namespaces are the namespaces of the objects. Each namespace is watched
+	// separately, so that the controller only needs permissions in these
+	// namespaces. nil means all the namespaces, and an empty list none.
+	Namespaces []string
+	// NamespaceSelector selects the namespaces of the objects by label. The
+	// objects are watched in all the namespaces (or in Namespaces), and the
+	// objects of the namespaces that are not selected are ignored. nil means
+	// all the namespaces.
+	NamespaceSelector labels.Selector
+}
+
+// Equal returns true if the scopes select the same namespaces.
+func (s Scope) Equal(other Scope) bool {
+	if (s.Namespaces == nil) != (other.Namespaces == nil) || !slices.Equal(s.Namespaces, other.Namespaces) {
+		return false
+	}
+	if (s.NamespaceSelector == nil) != (other.NamespaceSelector == nil) {
+		return false
+	}
+	return s.NamespaceSelector == nil || s.NamespaceSelector.String() == other.NamespaceSelector.String()
+}
+
+// intersect returns the scope of the namespaces selected by both scopes.
+func (s Scope) intersect(other Scope) Scope {
+	var out Scope
+	switch {
+	case s.Namespaces == nil:
+		out.Namespaces = other.Namespaces
+	case other.Namespaces == nil:
+		out.Namespaces = s.Namespaces
+	default:
+		out.Namespaces = []string{}
+		for _, namespace := range s.Namespaces {
+			if slices.Contains(other.Namespaces, namespace) {
+				out.Namespaces = append(out.Namespaces, namespace)
+			}
+		}
+	}
+	if out.Namespaces != nil {
+		out.Namespaces = slices.Clone(out.Namespaces)
+		slices.Sort(out.Namespaces)
+		out.Namespaces = slices.Compact(out.Namespaces)
+	}
+
+	switch {
+	case s.NamespaceSelector == nil:
+		out.NamespaceSelector = other.NamespaceSelector
+	case other.NamespaceSelector == nil:
+		out.NamespaceSelector = s.NamespaceSelector
+	default:
+		requirements, _ := s.NamespaceSelector.Requirements()
+		otherRequirements, _ := other.NamespaceSelector.Requirements()
+		out.NamespaceSelector = labels.NewSelector().Add(requirements...).Add(otherRequirements...)
+	}
+	return out
+}
+
+// SetScope restricts the objects of a GVR the controller serves, in addition
+// to the scope of the controller. StartServingGVK restarts the informers of
+// the GVR when its scope changes.
+func (dc *DynamicController) SetScope(gvr schema.GroupVersionResource, scope Scope) {
+	dc.scopes.Store(gvr, scope)
+}
+
+// Scope returns the scope of a GVR, the intersection of the scope of the
+// controller and of the GVR.
+func (dc *DynamicController) Scope(gvr schema.GroupVersionResource) Scope {
+	scope := Scope{
+		Namespaces:        dc.config.Namespaces,
+		NamespaceSelector: dc.config.NamespaceSelector,
+	}.intersect(Scope{})
+	if value, ok := dc.scopes.Load(gvr); ok {
+		scope = scope.intersect(value.(Scope))
+	}
+	return scope
+}
+
+// InScope returns true if the object is in the scope of its GVR.
+func (dc *DynamicController) InScope(gvr schema.GroupVersionResource, obj metav1.Object) bool {
+	scope := dc.Scope(gvr)
+	if scope.Namespaces != nil && !slices.Contains(scope.Namespaces, obj.GetNamespace()) {
+		return false
+	}
+	return dc.namespaceSelected(scope.NamespaceSelector, obj.GetNamespace())
+}
+
+// namespaceSelected returns true if the labels of the namespace match the
+// selector.
+func (dc *DynamicController) namespaceSelected(selector labels.Selector, namespace string) bool {
+	if selector == nil {
+		return true
+	}
+
+	dc.namespacesMu.Lock()
+	informer := dc.namespaces
+	dc.namespacesMu.Unlock()
+	if informer == nil {
+		return false
+	}
+	obj, exists, err := informer.GetIndexer().GetByKey(namespace)
+	if err != nil || !exists {
+		return false
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	return ok && selector.Matches(labels.Set(u.GetLabels()))
+}
+
+// startNamespaceInformer starts the informer of the namespaces, used to select
+// the namespaces by label, if it isn't started yet.
+func (dc *DynamicController) startNamespaceInformer(ctx context.Context) error {
+	dc.namespacesMu.Lock()
+	defer dc.namespacesMu.Unlock()
+	if dc.namespaces != nil {
+		return nil
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dc.kubeClient, dc.config.ResyncPeriod)
+	informer := factory.ForResource(namespaceGVR).Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: dc.namespaceUpdated,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add event handler for namespaces: %w", err)
+	}
+
+	dc.log.V(1).Info("Starting namespace informer")
+	go informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to sync namespace informer cache")
+	}
+	dc.namespaces = informer
+	return nil
+}
+
+// namespaceUpdated enqueues the objects of a namespace when it starts being
+// selected by the scope of their GVR. The objects of the namespaces that stop
+// being selected are ignored from then on, unless they are being deleted.
+func (dc *DynamicController) namespaceUpdated(old, new interface{}) {
+	oldNamespace, ok := old.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	newNamespace, ok := new.(*unstructured.Unstructured)
+	if !ok || maps.Equal(oldNamespace.GetLabels(), newNamespace.GetLabels()) {
+		return
+	}
+
+	dc.informers.Range(func(key, value interface{}) bool {
+		gvr := key.(schema.GroupVersionResource)
+		wrapper := value.(*informerWrapper)
+		selector := wrapper.scope.NamespaceSelector
+		if selector == nil ||
+			selector.Matches(labels.Set(oldNamespace.GetLabels())) ||
+			!selector.Matches(labels.Set(newNamespace.GetLabels())) {
+			return true
+		}
+
+		informer := wrapper.informer(gvr, newNamespace.GetName())
+		if informer == nil {
+			return true
+		}
+		objs, err := informer.GetIndexer().ByIndex(cache.NamespaceIndex, newNamespace.GetName())
+		if err != nil {
+			dc.log.Error(err, "Failed to list objects of namespace", "gvr", gvr, "namespace", newNamespace.GetName())
+			return true
+		}
+		for _, obj := range objs {
+			dc.enqueueObject(gvr, obj, "namespace")
+		}
+		return true
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+)
+
+func TestScopeIntersect(t *testing.T) {
+	all := Scope{}
+	none := Scope{Namespaces: []string{}}
+	teamA := Scope{Namespaces: []string{"team-a", "shared"}}
+	teamB := Scope{Namespaces: []string{"shared", "team-b"}}
+
+	assert.True(t, all.intersect(all).Equal(all))
+	assert.True(t, all.intersect(teamA).Equal(Scope{Namespaces: []string{"shared", "team-a"}}))
+	assert.True(t, teamA.intersect(teamB).Equal(Scope{Namespaces: []string{"shared"}}))
+	assert.True(t, teamA.intersect(none).Equal(none))
+	assert.False(t, none.Equal(all), "no namespace is not all the namespaces")
+
+	tenant := labels.SelectorFromSet(labels.Set{"tenant": "a"})
+	env := labels.SelectorFromSet(labels.Set{"env": "prod"})
+	scope := Scope{NamespaceSelector: tenant}.intersect(Scope{NamespaceSelector: env})
+	assert.True(t, scope.NamespaceSelector.Matches(labels.Set{"tenant": "a", "env": "prod"}))
+	assert.False(t, scope.NamespaceSelector.Matches(labels.Set{"tenant": "a"}))
+	assert.False(t, scope.Equal(Scope{NamespaceSelector: tenant}))
+}
+
+func TestEnqueueObjectSkipsObjectsOutOfScope(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		MinRetryDelay: 200 * time.Millisecond,
+		MaxRetryDelay: 1000 * time.Second,
+		Namespaces:    []string{"default", "team-a"},
+	}, setupFakeClient())
+
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	dc.SetScope(gvr, Scope{Namespaces: []string{"team-a", "team-b"}})
+
+	for _, namespace := range []string{"default", "team-a", "team-b"} {
+		obj := &unstructured.Unstructured{}
+		obj.SetName("test-object")
+		obj.SetNamespace(namespace)
+		obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Test"})
+		dc.enqueueObject(gvr, obj, "add")
+	}
+
+	// Only team-a is in the scope of both the controller and the GVR.
+	require.Equal(t, 1, dc.queue.Len())
+	item, _ := dc.queue.Get()
+	assert.Equal(t, "team-a/test-object", item.NamespacedKey)
+}
+
+func TestEnqueueObjectKeepsDeletingObjectsOutOfScope(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		MinRetryDelay: 200 * time.Millisecond,
+		MaxRetryDelay: 1000 * time.Second,
+	}, setupFakeClient())
+
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	dc.SetScope(gvr, Scope{Namespaces: []string{"team-a"}})
+
+	for _, name := range []string{"live", "deleting"} {
+		obj := &unstructured.Unstructured{}
+		obj.SetName(name)
+		obj.SetNamespace("team-b")
+		obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Test"})
+		if name == "deleting" {
+			obj.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+			obj.SetFinalizers([]string{"kro.run/finalizer"})
+		}
+		dc.enqueueObject(gvr, obj, "update")
+	}
+
+	// The deleting object is reconciled to remove its finalizer.
+	require.Equal(t, 1, dc.queue.Len())
+	item, _ := dc.queue.Get()
+	assert.Equal(t, "team-b/deleting", item.NamespacedKey)
+}
+
+func TestStartServingGVKRestartsInformersOnScopeChange(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		Workers:         1,
+		ResyncPeriod:    time.Hour,
+		ShutdownTimeout: 5 * time.Second,
+		MinRetryDelay:   200 * time.Millisecond,
+		MaxRetryDelay:   1000 * time.Second,
+	}, setupFakeClient())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = dc.Start(ctx)
+	}()
+
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	handler := Handler(func(ctx context.Context, req controllerruntime.Request) error {
+		return nil
+	})
+
+	dc.SetScope(gvr, Scope{Namespaces: []string{"team-a"}})
+	require.NoError(t, dc.StartServingGVK(ctx, gvr, handler))
+	value, ok := dc.informers.Load(gvr)
+	require.True(t, ok)
+	wrapper := value.(*informerWrapper)
+	assert.Len(t, wrapper.informers, 1)
+	assert.NotNil(t, wrapper.informer(gvr, "team-a"))
+	assert.Nil(t, wrapper.informer(gvr, "team-b"))
+
+	// Serving the GVR again with the same scope keeps the informers.
+	require.NoError(t, dc.StartServingGVK(ctx, gvr, handler))
+	value, _ = dc.informers.Load(gvr)
+	assert.Same(t, wrapper, value.(*informerWrapper))
+
+	dc.SetScope(gvr, Scope{Namespaces: []string{"team-a", "team-b"}})
+	require.NoError(t, dc.StartServingGVK(ctx, gvr, handler))
+	value, _ = dc.informers.Load(gvr)
+	assert.NotSame(t, wrapper, value.(*informerWrapper))
+	assert.Len(t, value.(*informerWrapper).informers, 2)
+
+	require.NoError(t, dc.StopServiceGVK(ctx, gvr))
+	_, ok = dc.scopes.Load(gvr)
+	assert.False(t, ok)
+}
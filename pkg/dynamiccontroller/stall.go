@@ -0,0 +1,135 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// DefaultStalledRetryDelay is the default delay before a stalled item is
+	// re-admitted to the queue for the first time.
+	DefaultStalledRetryDelay = 10 * time.Minute
+	// DefaultMaxStalledRetryDelay is the default maximum delay before a
+	// stalled item is re-admitted to the queue.
+	DefaultMaxStalledRetryDelay = 6 * time.Hour
+)
+
+// StalledHandler is called when an object is stalled, i.e its reconciliation
+// failed more than QueueMaxRetries times in a row, with the last error. It is
+// meant to surface the stall on the object, e.g in a status condition. A
+// NotFound error means the object is gone, and it isn't retried.
+type StalledHandler func(ctx context.Context, req ctrl.Request, err error) error
+
+// stalledItems tracks the stalled items, and the number of times each of them
+// stalled in a row.
+type stalledItems struct {
+	mu    sync.Mutex
+	items map[schema.GroupVersionResource]map[ObjectIdentifiers]int
+}
+
+func newStalledItems() *stalledItems {
+	return &stalledItems{
+		items: make(map[schema.GroupVersionResource]map[ObjectIdentifiers]int),
+	}
+}
+
+// add records that the item stalled, and returns the number of times it
+// stalled in a row.
+func (s *stalledItems) add(item ObjectIdentifiers) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, ok := s.items[item.GVR]
+	if !ok {
+		items = make(map[ObjectIdentifiers]int)
+		s.items[item.GVR] = items
+	}
+	items[item]++
+	stalledItemsGauge.WithLabelValues(item.GVR.String()).Set(float64(len(items)))
+	return items[item]
+}
+
+// has returns true if the item is stalled.
+func (s *stalledItems) has(item ObjectIdentifiers) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.items[item.GVR][item]
+	return ok
+}
+
+// remove records that the item isn't stalled anymore.
+func (s *stalledItems) remove(item ObjectIdentifiers) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, ok := s.items[item.GVR]
+	if !ok {
+		return
+	}
+	if _, ok := items[item]; ok {
+		delete(items, item)
+		stalledItemsGauge.WithLabelValues(item.GVR.String()).Set(float64(len(items)))
+	}
+}
+
+// drop forgets the stalled items of a GVR.
+func (s *stalledItems) drop(gvr schema.GroupVersionResource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, gvr)
+	stalledItemsGauge.DeleteLabelValues(gvr.String())
+}
+
+// SetStalledHandler registers the handler called when the objects of a GVR
+// stall.
+func (dc *DynamicController) SetStalledHandler(gvr schema.GroupVersionResource, handler StalledHandler) {
+	dc.stalledHandlers.Store(gvr, handler)
+}
+
+// stall handles an item whose reconciliation failed more than QueueMaxRetries
+// times in a row, or failed again after it stalled. Instead of dropping it,
+// the item is re-admitted after a long delay, doubling each time it stalls
+// again, and the stall is surfaced with the StalledHandler of its GVR. Items
+// whose object is gone are dropped.
+func (dc *DynamicController) stall(ctx context.Context, item ObjectIdentifiers, err error) {
+	dc.queue.Forget(item)
+	if handler, ok := dc.stalledHandlers.Load(item.GVR); ok {
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: item.NamespacedKey}}
+		if handlerErr := handler.(StalledHandler)(ctx, req, err); apierrors.IsNotFound(handlerErr) {
+			dc.log.V(1).Info("Dropping stalled item, its object is gone", "item", item)
+			dc.stalled.remove(item)
+			return
+		} else if handlerErr != nil {
+			dc.log.Error(handlerErr, "Failed to handle stalled item", "item", item)
+		}
+	}
+
+	stalls := dc.stalled.add(item)
+	stalledItemsTotal.WithLabelValues(item.GVR.String()).Inc()
+	delay := dc.config.StalledRetryDelay
+	for i := 1; i < stalls && delay < dc.config.MaxStalledRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, dc.config.MaxStalledRetryDelay)
+	dc.log.Error(err, "Item stalled after max retries, re-admitting it later", "item", item, "stalls", stalls, "delay", delay)
+	dc.queue.AddAfter(item, delay)
+}
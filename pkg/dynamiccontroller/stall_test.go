@@ -0,0 +1,136 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+)
+
+func TestStalledItemsAreReadmitted(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		QueueMaxRetries:      2,
+		MinRetryDelay:        time.Millisecond,
+		MaxRetryDelay:        time.Millisecond,
+		StalledRetryDelay:    50 * time.Millisecond,
+		MaxStalledRetryDelay: 80 * time.Millisecond,
+	}, setupFakeClient())
+
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "tests"}
+	item := ObjectIdentifiers{NamespacedKey: "default/test-object", GVR: gvr}
+
+	failing := true
+	dc.handlers.Store(gvr, Handler(func(ctx context.Context, req controllerruntime.Request) error {
+		if failing {
+			return fmt.Errorf("boom")
+		}
+		return nil
+	}))
+	stalled := make(chan error, 10)
+	dc.SetStalledHandler(gvr, func(ctx context.Context, req controllerruntime.Request, err error) error {
+		assert.Equal(t, item.NamespacedKey, req.Name)
+		stalled <- err
+		return nil
+	})
+
+	ctx := context.Background()
+	dc.queue.Add(item)
+
+	// The item is retried QueueMaxRetries times before it stalls.
+	for i := 0; i < 3; i++ {
+		require.True(t, dc.processNextWorkItem(ctx))
+	}
+	select {
+	case err := <-stalled:
+		assert.EqualError(t, err, "boom")
+	default:
+		t.Fatal("the item did not stall after max retries")
+	}
+	assert.True(t, dc.stalled.has(item))
+
+	// The stalled item is re-admitted after the stalled retry delay, and
+	// stalls again as soon as it fails.
+	start := time.Now()
+	require.True(t, dc.processNextWorkItem(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.Len(t, stalled, 1)
+	<-stalled
+
+	// The delay doubles, up to the max stalled retry delay, and the item is
+	// not stalled anymore once it succeeds.
+	failing = false
+	start = time.Now()
+	require.True(t, dc.processNextWorkItem(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+	assert.Empty(t, stalled)
+	assert.False(t, dc.stalled.has(item))
+	assert.Equal(t, 0, dc.queue.Len())
+}
+
+func TestStalledItemsAreDroppedOnceGone(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		MinRetryDelay: time.Millisecond,
+		MaxRetryDelay: time.Millisecond,
+	}, setupFakeClient())
+
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "gones"}
+	item := ObjectIdentifiers{NamespacedKey: "default/test-object", GVR: gvr}
+	dc.handlers.Store(gvr, Handler(func(ctx context.Context, req controllerruntime.Request) error {
+		return fmt.Errorf("boom")
+	}))
+	dc.SetStalledHandler(gvr, func(ctx context.Context, req controllerruntime.Request, err error) error {
+		return apierrors.NewNotFound(schema.GroupResource{Group: gvr.Group, Resource: gvr.Resource}, req.Name)
+	})
+
+	// The item stalls on its first failure, and its object is gone.
+	dc.queue.Add(item)
+	dc.stalled.add(item)
+	require.True(t, dc.processNextWorkItem(context.Background()))
+	assert.False(t, dc.stalled.has(item))
+	assert.Zero(t, testutil.ToFloat64(stalledItemsTotal.WithLabelValues(gvr.String())))
+	dc.queue.mu.Lock()
+	assert.Empty(t, dc.queue.waiting)
+	dc.queue.mu.Unlock()
+	assert.Equal(t, 0, dc.queue.Len())
+}
+
+func TestItemsWithoutHandlerAreDropped(t *testing.T) {
+	dc := NewDynamicController(noopLogger(), Config{
+		MinRetryDelay: time.Millisecond,
+		MaxRetryDelay: time.Millisecond,
+	}, setupFakeClient())
+
+	// The GVR stopped being served while its item was queued.
+	gvr := schema.GroupVersionResource{Group: "test", Version: "v1", Resource: "unserved"}
+	item := ObjectIdentifiers{NamespacedKey: "default/test-object", GVR: gvr}
+	dc.queue.Add(item)
+	dc.stalled.add(item)
+	require.True(t, dc.processNextWorkItem(context.Background()))
+
+	assert.False(t, dc.stalled.has(item))
+	assert.Zero(t, dc.queue.NumRequeues(item))
+	dc.queue.mu.Lock()
+	assert.Empty(t, dc.queue.waiting)
+	dc.queue.mu.Unlock()
+	assert.Equal(t, 0, dc.queue.Len())
+}
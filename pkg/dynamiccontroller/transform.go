@@ -0,0 +1,72 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// DefaultMaxCachedAnnotationSize is the default size, in bytes, above which
+// annotation values are stripped from the objects cached by the informers.
+const DefaultMaxCachedAnnotationSize = 1024
+
+// informerFactory creates the informers of a namespace. It is implemented by
+// the dynamic informer factories, caching full objects, and by the metadata
+// informer factories, caching their metadata only.
+type informerFactory interface {
+	ForResource(gvr schema.GroupVersionResource) informers.GenericInformer
+	Shutdown()
+}
+
+// stripObject returns the transform applied to the objects before they are
+// cached by the informers. The handlers read the objects from the API server,
+// the informers only need what triggers reconciles, so it strips:
+//   - the managed fields, often larger than the object itself.
+//   - the last applied configuration of kubectl, a copy of the object.
+//   - the annotations larger than maxAnnotationSize, except kro's own
+//     annotations, which control how the objects are reconciled.
+func stripObject(maxAnnotationSize int) cache.TransformFunc {
+	return func(obj interface{}) (interface{}, error) {
+		// Tombstones of deleted objects are passed through.
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return obj, nil
+		}
+
+		accessor.SetManagedFields(nil)
+		annotations := accessor.GetAnnotations()
+		stripped := false
+		for key, value := range annotations {
+			if strings.HasPrefix(key, metadata.LabelKROPrefix) {
+				continue
+			}
+			if key == corev1.LastAppliedConfigAnnotation || len(value) > maxAnnotationSize {
+				delete(annotations, key)
+				stripped = true
+			}
+		}
+		if stripped {
+			accessor.SetAnnotations(annotations)
+		}
+		return obj, nil
+	}
+}
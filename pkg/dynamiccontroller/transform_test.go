@@ -0,0 +1,233 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dynamiccontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/tools/cache"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// newBenchmarkObject returns an instance as returned by the API server after
+// a kubectl apply and a few status updates.
+func newBenchmarkObject(i int) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kro.run/v1alpha1",
+		"kind":       "WebApp",
+		"metadata": map[string]interface{}{
+			"name":            fmt.Sprintf("webapp-%d", i),
+			"namespace":       fmt.Sprintf("team-%d", i%10),
+			"uid":             fmt.Sprintf("6f0f64a5-0000-0000-0000-%012d", i),
+			"resourceVersion": fmt.Sprintf("%d", 1000+i),
+			"generation":      int64(3),
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/name": "webapp",
+				"team":                   fmt.Sprintf("team-%d", i%10),
+			},
+		},
+		"spec": map[string]interface{}{
+			"image":    "nginx:1.27",
+			"replicas": int64(3),
+			"ingress":  map[string]interface{}{"enabled": true, "host": fmt.Sprintf("webapp-%d.example.com", i)},
+		},
+		"status": map[string]interface{}{
+			"state": "ACTIVE",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "InstanceSynced", "status": "True", "reason": "Synced", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+				map[string]interface{}{"type": "Ready", "status": "True", "reason": "Ready", "lastTransitionTime": "2025-01-01T00:00:00Z"},
+			},
+		},
+	}}
+
+	lastApplied, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": obj.GetAPIVersion(),
+		"kind":       obj.GetKind(),
+		"metadata":   map[string]interface{}{"name": obj.GetName(), "namespace": obj.GetNamespace()},
+		"spec":       obj.Object["spec"],
+	})
+	obj.SetAnnotations(map[string]string{
+		corev1.LastAppliedConfigAnnotation:                 string(lastApplied),
+		"example.com/notes":                                strings.Repeat("x", 2*DefaultMaxCachedAnnotationSize),
+		metadata.ResourceGraphDefinitionRevisionAnnotation: "3",
+	})
+
+	fields := func(paths ...string) *metav1.FieldsV1 {
+		raw := map[string]interface{}{}
+		for _, path := range paths {
+			raw["f:"+path] = map[string]interface{}{".": map[string]interface{}{}, "f:value": map[string]interface{}{}}
+		}
+		data, _ := json.Marshal(raw)
+		return &metav1.FieldsV1{Raw: data}
+	}
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kubectl-client-side-apply", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "kro.run/v1alpha1",
+			FieldsType: "FieldsV1", FieldsV1: fields("metadata", "spec", "image", "replicas", "ingress", "host", "enabled")},
+		{Manager: "kro", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "kro.run/v1alpha1", Subresource: "status",
+			FieldsType: "FieldsV1", FieldsV1: fields("status", "state", "conditions", "InstanceSynced", "Ready")},
+		{Manager: "kro", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "kro.run/v1alpha1",
+			FieldsType: "FieldsV1", FieldsV1: fields("metadata", "finalizers", "labels", "kro.run/owned")},
+	})
+	return obj
+}
+
+// toPartialObjectMetadata returns the metadata of an object, as cached by the
+// metadata informers.
+func toPartialObjectMetadata(obj *unstructured.Unstructured) *metav1.PartialObjectMetadata {
+	partial := &metav1.PartialObjectMetadata{}
+	_ = k8sruntime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, partial)
+	return partial
+}
+
+func TestStripObject(t *testing.T) {
+	obj := newBenchmarkObject(0)
+	transformed, err := stripObject(DefaultMaxCachedAnnotationSize)(obj)
+	require.NoError(t, err)
+
+	u := transformed.(*unstructured.Unstructured)
+	assert.Empty(t, u.GetManagedFields())
+	assert.Equal(t, map[string]string{metadata.ResourceGraphDefinitionRevisionAnnotation: "3"}, u.GetAnnotations(),
+		"the last applied configuration and large annotations are stripped, kro annotations are kept")
+	assert.Equal(t, int64(3), u.GetGeneration())
+	assert.NotNil(t, u.Object["spec"])
+
+	partial := toPartialObjectMetadata(newBenchmarkObject(0))
+	transformed, err = stripObject(DefaultMaxCachedAnnotationSize)(partial)
+	require.NoError(t, err)
+	assert.Empty(t, transformed.(*metav1.PartialObjectMetadata).ManagedFields)
+
+	// Tombstones are passed through.
+	tombstone := cache.DeletedFinalStateUnknown{Key: "team-0/webapp-0", Obj: obj}
+	transformed, err = stripObject(DefaultMaxCachedAnnotationSize)(tombstone)
+	require.NoError(t, err)
+	assert.Equal(t, tombstone, transformed)
+}
+
+func TestMetadataOnlyInformers(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "webapps"}
+	scheme := k8sruntime.NewScheme()
+	require.NoError(t, metav1.AddMetaToScheme(scheme))
+	partial := toPartialObjectMetadata(newBenchmarkObject(0))
+	partial.SetGroupVersionKind(schema.GroupVersionKind{Group: "kro.run", Version: "v1alpha1", Kind: "WebApp"})
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme, partial)
+
+	dc := NewDynamicController(noopLogger(), Config{
+		Workers:         1,
+		ResyncPeriod:    time.Hour,
+		ShutdownTimeout: 5 * time.Second,
+		MinRetryDelay:   200 * time.Millisecond,
+		MaxRetryDelay:   1000 * time.Second,
+		MetadataClient:  metadataClient,
+	}, setupFakeClient())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reconciled := make(chan string, 1)
+	go func() {
+		_ = dc.Start(ctx)
+	}()
+	require.NoError(t, dc.StartServingGVK(ctx, gvr, func(ctx context.Context, req controllerruntime.Request) error {
+		reconciled <- req.Name
+		return nil
+	}))
+
+	value, ok := dc.informers.Load(gvr)
+	require.True(t, ok)
+	obj, exists, err := value.(*informerWrapper).informer(gvr, "team-0").GetIndexer().GetByKey("team-0/webapp-0")
+	require.NoError(t, err)
+	require.True(t, exists)
+	cached, ok := obj.(*metav1.PartialObjectMetadata)
+	require.True(t, ok, "the informers cache the metadata only")
+	assert.Empty(t, cached.ManagedFields)
+
+	select {
+	case name := <-reconciled:
+		assert.Equal(t, "team-0/webapp-0", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the object was not reconciled")
+	}
+}
+
+// BenchmarkInformerMemory reports the heap retained per object by the
+// informer caches, for each caching mode.
+func BenchmarkInformerMemory(b *testing.B) {
+	const objects = 10000
+	strip := stripObject(DefaultMaxCachedAnnotationSize)
+
+	modes := []struct {
+		name  string
+		cache func(obj *unstructured.Unstructured) interface{}
+	}{
+		{
+			name:  "full",
+			cache: func(obj *unstructured.Unstructured) interface{} { return obj },
+		},
+		{
+			name: "stripped",
+			cache: func(obj *unstructured.Unstructured) interface{} {
+				transformed, _ := strip(obj)
+				return transformed
+			},
+		},
+		{
+			name: "metadata",
+			cache: func(obj *unstructured.Unstructured) interface{} {
+				transformed, _ := strip(toPartialObjectMetadata(obj))
+				return transformed
+			},
+		},
+	}
+
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+
+				store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+					cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+				})
+				for j := 0; j < objects; j++ {
+					if err := store.Add(mode.cache(newBenchmarkObject(j))); err != nil {
+						b.Fatal(err)
+					}
+				}
+
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/objects, "heap-bytes/object")
+				runtime.KeepAlive(store)
+			}
+		})
+	}
+}
@@ -14,13 +14,17 @@
 package graph
 
 import (
+	"context"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/common/types/ref"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/maps"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -31,6 +35,7 @@ import (
 	"github.com/kro-run/kro/api/v1alpha1"
 	krocel "github.com/kro-run/kro/pkg/cel"
 	"github.com/kro-run/kro/pkg/cel/ast"
+	"github.com/kro-run/kro/pkg/controller/instance/delta"
 	"github.com/kro-run/kro/pkg/graph/crd"
 	"github.com/kro-run/kro/pkg/graph/dag"
 	"github.com/kro-run/kro/pkg/graph/emulator"
@@ -38,7 +43,9 @@ import (
 	"github.com/kro-run/kro/pkg/graph/schema"
 	"github.com/kro-run/kro/pkg/graph/variable"
 	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/runtime"
 	"github.com/kro-run/kro/pkg/simpleschema"
+	"github.com/kro-run/kro/pkg/tracing"
 )
 
 // NewBuilder creates a new GraphBuilder instance.
@@ -100,11 +107,17 @@ type Builder struct {
 // CRD. The ResourceGraphDefinition object is a fully processed and validated representation
 // of the resource graph definition CRD, it's underlying resources, and the relationships between
 // the resources.
-func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphDefinition) (*Graph, error) {
+func (b *Builder) NewResourceGraphDefinition(ctx context.Context, originalCR *v1alpha1.ResourceGraphDefinition) (_ *Graph, err error) {
 	// Before anything else, let's copy the resource graph definition to avoid modifying the
 	// original object.
 	rgd := originalCR.DeepCopy()
 
+	ctx, span := tracing.Start(ctx, "graph.Builder.NewResourceGraphDefinition",
+		attribute.String("kro.rgd.name", rgd.Name),
+		attribute.Int("kro.rgd.resources", len(rgd.Spec.Resources)),
+	)
+	defer tracing.End(span, &err)
+
 	// There are a few steps to build a resource graph definition:
 	// 1. Validate the naming convention of the resource graph definition and its resources.
 	//    kro leverages CEL expressions to allow users to define new types and
@@ -112,7 +125,7 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 	//    that the names of the resources are valid to be used in CEL expressions.
 	//    for example name-something-something is not a valid name for a resource,
 	//    because in CEL - is a subtraction operator.
-	err := validateResourceGraphDefinitionNamingConventions(rgd)
+	err = validateResourceGraphDefinitionNamingConventions(rgd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate resourcegraphdefinition: %w", err)
 	}
@@ -129,7 +142,9 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 	// 4. Extract the CEL expressions from the resource + validate them.
 
 	namespacedResources := map[k8sschema.GroupKind]bool{}
+	_, discoverySpan := tracing.Start(ctx, "graph.Builder.discovery")
 	apiResourceList, err := b.discoveryClient.ServerPreferredNamespacedResources()
+	tracing.End(discoverySpan, &err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve Kubernetes namespaced resources: %w", err)
 	}
@@ -145,7 +160,9 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 	for i, rgResource := range rgd.Spec.Resources {
 		id := rgResource.ID
 		order := i
-		r, err := b.buildRGResource(rgResource, namespacedResources, order)
+		_, resourceSpan := tracing.Start(ctx, "graph.Builder.buildRGResource", attribute.String("kro.resource.id", id))
+		r, err := b.buildRGResource(rgResource, namespacedResources, &rgd.Spec, order)
+		tracing.End(resourceSpan, &err)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build resource %q: %w", id, err)
 		}
@@ -194,6 +211,7 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 	// 3. Validate them against the resources defined in the resource graph definition.
 	// 4. Infer the status schema based on the CEL expressions.
 
+	_, instanceSpan := tracing.Start(ctx, "graph.Builder.buildInstanceResource")
 	instance, err := b.buildInstanceResource(
 		rgd.Spec.Schema.Group,
 		rgd.Spec.Schema.APIVersion,
@@ -203,6 +221,7 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 		// the CEL expressions in the context of the resources.
 		resources,
 	)
+	tracing.End(instanceSpan, &err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build resourcegraphdefinition '%v': %w", rgd.Name, err)
 	}
@@ -211,7 +230,9 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 	// in the instance resource. In order to do that, we need to isolate each resource
 	// and evaluate the CEL expressions in the context of the resource graph definition. This is done
 	// by dry-running the CEL expressions against the emulated resources.
+	_, validationSpan := tracing.Start(ctx, "graph.Builder.validateResourceCELExpressions")
 	err = validateResourceCELExpressions(resources, instance)
+	tracing.End(validationSpan, &err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate resource CEL expressions: %w", err)
 	}
@@ -228,7 +249,9 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 	// The dependency graph is built by inspecting the CEL expressions in the
 	// resources and the instance resource, using a CEL AST (Abstract Syntax Tree)
 	// inspector.
+	_, dagSpan := tracing.Start(ctx, "graph.Builder.buildDependencyGraph")
 	dag, err := b.buildDependencyGraph(resources)
+	tracing.End(dagSpan, &err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
 	}
@@ -251,7 +274,12 @@ func (b *Builder) NewResourceGraphDefinition(originalCR *v1alpha1.ResourceGraphD
 // It provides a high-level understanding of the resource, by extracting the
 // OpenAPI schema, emulating the resource and extracting the cel expressions
 // from the schema.
-func (b *Builder) buildRGResource(rgResource *v1alpha1.Resource, namespacedResources map[k8sschema.GroupKind]bool, order int) (*Resource, error) {
+func (b *Builder) buildRGResource(
+	rgResource *v1alpha1.Resource,
+	namespacedResources map[k8sschema.GroupKind]bool,
+	rgdSpec *v1alpha1.ResourceGraphDefinitionSpec,
+	order int,
+) (*Resource, error) {
 	// 1. We need to unmarshal the resource into a map[string]interface{} to
 	//    make it easier to work with.
 	resourceObject := map[string]interface{}{}
@@ -328,6 +356,49 @@ func (b *Builder) buildRGResource(rgResource *v1alpha1.Resource, namespacedResou
 
 	_, isNamespaced := namespacedResources[gvk.GroupKind()]
 
+	adoptionPolicy := rgResource.AdoptionPolicy
+	if adoptionPolicy == "" {
+		adoptionPolicy = v1alpha1.AdoptionPolicyFail
+	}
+	updatePolicy := rgResource.UpdatePolicy
+	if updatePolicy == "" {
+		updatePolicy = v1alpha1.UpdatePolicyUpdate
+	}
+
+	// 8. Validate the ignored field paths
+	for _, path := range rgResource.IgnoreDifferences {
+		if _, err := delta.ParsePath(path); err != nil {
+			return nil, fmt.Errorf("failed to parse ignoreDifferences of resource %s: %w", rgResource.ID, err)
+		}
+	}
+
+	// 9. Pick the ResourceGraphDefinition health check of the resource kind,
+	//    used when there are no readyWhen expressions.
+	healthCheckPolicy := rgResource.HealthCheck
+	if healthCheckPolicy == "" {
+		healthCheckPolicy = v1alpha1.HealthCheckPolicyDefault
+	}
+	var healthCheckExpressions []string
+	if len(readyWhen) == 0 && healthCheckPolicy == v1alpha1.HealthCheckPolicyDefault {
+		for _, healthCheck := range rgdSpec.HealthChecks {
+			if healthCheck.Group == gvk.Group && healthCheck.Kind == gvk.Kind {
+				healthCheckExpressions, err = parser.ParseConditionExpressions(healthCheck.ReadyWhen)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse %s health check expressions: %v", gvk.Kind, err)
+				}
+				break
+			}
+		}
+	}
+
+	// 10. Resolve the readiness and deletion timeouts, the resource ones take
+	//     precedence over the ResourceGraphDefinition defaults.
+	readyTimeout := durationOrDefault(rgResource.ReadyTimeout, rgdSpec.ReadyTimeout)
+	deleteTimeout := durationOrDefault(rgResource.DeleteTimeout, rgdSpec.DeleteTimeout)
+	if readyTimeout < 0 || deleteTimeout < 0 {
+		return nil, fmt.Errorf("resource %s timeouts must not be negative", rgResource.ID)
+	}
+
 	// Note that at this point we don't inject the dependencies into the resource.
 	return &Resource{
 		id:                     rgResource.ID,
@@ -340,9 +411,27 @@ func (b *Builder) buildRGResource(rgResource *v1alpha1.Resource, namespacedResou
 		includeWhenExpressions: includeWhen,
 		namespaced:             isNamespaced,
 		order:                  order,
+		adoptionPolicy:         adoptionPolicy,
+		ignoreDifferences:      slices.Clone(rgResource.IgnoreDifferences),
+		updatePolicy:           updatePolicy,
+		explicitDependencies:   slices.Clone(rgResource.DependsOn),
+		healthCheckPolicy:      healthCheckPolicy,
+		healthCheckExpressions: healthCheckExpressions,
+		readyTimeout:           readyTimeout,
+		deleteTimeout:          deleteTimeout,
 	}, nil
 }
 
+// durationOrDefault returns the first set duration, or zero if none is.
+func durationOrDefault(durations ...*metav1.Duration) time.Duration {
+	for _, d := range durations {
+		if d != nil {
+			return d.Duration
+		}
+	}
+	return 0
+}
+
 // buildDependencyGraph builds the dependency graph between the resources in the
 // resource graph definition. The dependency graph is an directed acyclic graph that represents
 // the relationships between the resources in the resource graph definition. The graph is used
@@ -410,6 +499,20 @@ func (b *Builder) buildDependencyGraph(
 		}
 	}
 
+	// Merge the dependencies declared with dependsOn. They are added after the
+	// inferred ones, so that cycles are reported on the explicit dependencies.
+	for _, resource := range resources {
+		for _, dependency := range resource.explicitDependencies {
+			if _, ok := resources[dependency]; !ok {
+				return nil, fmt.Errorf("resource %s depends on unknown resource %s", resource.id, dependency)
+			}
+		}
+		resource.addDependencies(resource.explicitDependencies...)
+		if err := directedAcyclicGraph.AddDependencies(resource.id, resource.explicitDependencies); err != nil {
+			return nil, fmt.Errorf("invalid dependsOn of resource %s: %w", resource.id, err)
+		}
+	}
+
 	return directedAcyclicGraph, nil
 }
 
@@ -739,6 +842,11 @@ func validateResourceCELExpressions(resources map[string]*Resource, instance *Re
 			return fmt.Errorf("failed to ensure resource %s readyWhen expressions: %w", resource.id, err)
 		}
 
+		err = ensureHealthCheckExpressions(resource)
+		if err != nil {
+			return fmt.Errorf("failed to ensure resource %s health check expressions: %w", resource.id, err)
+		}
+
 		err = ensureIncludeWhenExpressions(env, includeWhenContext, resource)
 		if err != nil {
 			return fmt.Errorf("failed to ensure resource %s includeWhen expressions: %w", resource.id, err)
@@ -769,8 +877,20 @@ func ensureResourceExpressions(env *cel.Env, context map[string]*Resource, resou
 // ensureReadyWhenExpressions validates the readyWhen expressions in the resource
 // against the resources defined in the resource graph definition.
 func ensureReadyWhenExpressions(resource *Resource) error {
-	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{resource.id}))
-	for _, expression := range resource.readyWhenExpressions {
+	return ensureReadinessExpressions(resource, resource.id, resource.readyWhenExpressions, "readyWhen")
+}
+
+// ensureHealthCheckExpressions validates the health check expressions picked
+// for the resource, where the resource is referred to as "self".
+func ensureHealthCheckExpressions(resource *Resource) error {
+	return ensureReadinessExpressions(resource, runtime.HealthCheckSelf, resource.healthCheckExpressions, "health check")
+}
+
+// ensureReadinessExpressions validates readiness expressions referring to the
+// resource with the given name.
+func ensureReadinessExpressions(resource *Resource, name string, expressions []string, kind string) error {
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{name}))
+	for _, expression := range expressions {
 		if err != nil {
 			return fmt.Errorf("failed to create CEL environment: %w", err)
 		}
@@ -782,16 +902,16 @@ func ensureReadyWhenExpressions(resource *Resource) error {
 			delete(resourceEmulatedCopy.Object, "kind")
 		}
 		context := map[string]*Resource{}
-		context[resource.id] = &Resource{
+		context[name] = &Resource{
 			emulatedObject: resourceEmulatedCopy,
 		}
 
-		output, err := ensureExpression(env, expression, []string{resource.id}, context)
+		output, err := ensureExpression(env, expression, []string{name}, context)
 		if err != nil {
 			return fmt.Errorf("failed to dry-run expression %s: %w", expression, err)
 		}
 		if !krocel.IsBoolType(output) {
-			return fmt.Errorf("output of readyWhen expression %s can only be of type bool", expression)
+			return fmt.Errorf("output of %s expression %s can only be of type bool", kind, expression)
 		}
 	}
 	return nil
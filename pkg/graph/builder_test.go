@@ -14,12 +14,16 @@
 package graph
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 
+	"github.com/kro-run/kro/api/v1alpha1"
 	"github.com/kro-run/kro/pkg/graph/emulator"
 	"github.com/kro-run/kro/pkg/graph/variable"
 	"github.com/kro-run/kro/pkg/testutil/generator"
@@ -424,7 +428,7 @@ func TestGraphBuilder_Validation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rgd := generator.NewResourceGraphDefinition("test-group", tt.resourceGraphDefinitionOpts...)
-			_, err := builder.NewResourceGraphDefinition(rgd)
+			_, err := builder.NewResourceGraphDefinition(context.Background(), rgd)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -975,12 +979,117 @@ func TestGraphBuilder_DependencyValidation(t *testing.T) {
 				}, g.TopologicalOrder)
 			},
 		},
+		{
+			name: "explicit dependencies",
+			resourceGraphDefinitionOpts: []generator.ResourceGraphDefinitionOption{
+				generator.WithSchema(
+					"Test", "v1alpha1",
+					map[string]interface{}{
+						"name": "string",
+					},
+					nil,
+				),
+				generator.WithResource("pod", map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Pod",
+					"metadata": map[string]interface{}{
+						"name": "pod",
+					},
+				}, nil, nil),
+				generator.WithResource("policy", map[string]interface{}{
+					"apiVersion": "iam.services.k8s.aws/v1alpha1",
+					"kind":       "Policy",
+					"metadata": map[string]interface{}{
+						"name": "policy",
+					},
+				}, nil, nil),
+				generator.WithResource("vpc", map[string]interface{}{
+					"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+					"kind":       "VPC",
+					"metadata": map[string]interface{}{
+						"name": "vpc",
+					},
+				}, nil, nil),
+				generator.WithResource("subnet", map[string]interface{}{
+					"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+					"kind":       "Subnet",
+					"metadata": map[string]interface{}{
+						"name": "subnet",
+					},
+					"spec": map[string]interface{}{
+						"vpcID": "${vpc.status.vpcID}",
+					},
+				}, nil, nil),
+				generator.WithDependsOn("pod", "subnet", "policy"),
+				generator.WithDependsOn("subnet", "vpc"),
+			},
+			validateDeps: func(t *testing.T, g *Graph) {
+				assert.ElementsMatch(t, []string{"subnet", "policy"}, g.Resources["pod"].GetDependencies())
+				assert.Equal(t, []string{"subnet", "policy"}, g.Resources["pod"].GetExplicitDependencies())
+				assert.Equal(t, []string{"vpc"}, g.Resources["subnet"].GetDependencies())
+				assert.Equal(t, []string{"policy", "vpc", "subnet", "pod"}, g.TopologicalOrder)
+			},
+		},
+		{
+			name: "explicit dependency on unknown resource",
+			resourceGraphDefinitionOpts: []generator.ResourceGraphDefinitionOption{
+				generator.WithSchema(
+					"Test", "v1alpha1",
+					map[string]interface{}{
+						"name": "string",
+					},
+					nil,
+				),
+				generator.WithResource("pod", map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Pod",
+					"metadata": map[string]interface{}{
+						"name": "pod",
+					},
+				}, nil, nil),
+				generator.WithDependsOn("pod", "namespace"),
+			},
+			wantErr: true,
+			errMsg:  "resource pod depends on unknown resource namespace",
+		},
+		{
+			name: "explicit dependency cycle",
+			resourceGraphDefinitionOpts: []generator.ResourceGraphDefinitionOption{
+				generator.WithSchema(
+					"Test", "v1alpha1",
+					map[string]interface{}{
+						"name": "string",
+					},
+					nil,
+				),
+				generator.WithResource("vpc", map[string]interface{}{
+					"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+					"kind":       "VPC",
+					"metadata": map[string]interface{}{
+						"name": "vpc",
+					},
+				}, nil, nil),
+				generator.WithResource("subnet", map[string]interface{}{
+					"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+					"kind":       "Subnet",
+					"metadata": map[string]interface{}{
+						"name": "subnet",
+					},
+					"spec": map[string]interface{}{
+						"vpcID": "${vpc.status.vpcID}",
+					},
+				}, nil, nil),
+				generator.WithDependsOn("vpc", "subnet"),
+			},
+			wantErr: true,
+			errMsg:  "invalid dependsOn of resource vpc: graph contains a cycle",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rgd := generator.NewResourceGraphDefinition("testrgd", tt.resourceGraphDefinitionOpts...)
-			g, err := builder.NewResourceGraphDefinition(rgd)
+			g, err := builder.NewResourceGraphDefinition(context.Background(), rgd)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -1313,7 +1422,7 @@ func TestGraphBuilder_ExpressionParsing(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rgd := generator.NewResourceGraphDefinition("testrgd", tt.resourceGraphDefinitionOpts...)
-			g, err := builder.NewResourceGraphDefinition(rgd)
+			g, err := builder.NewResourceGraphDefinition(context.Background(), rgd)
 			require.NoError(t, err)
 			if tt.validateVars != nil {
 				tt.validateVars(t, g)
@@ -1346,6 +1455,61 @@ func validateVariables(t *testing.T, actual []*variable.ResourceField, expected
 	assert.ElementsMatch(t, expected, actualVars)
 }
 
+func TestGraphBuilder_Timeouts(t *testing.T) {
+	fakeResolver, fakeDiscovery := k8s.NewFakeResolver()
+	builder := &Builder{
+		schemaResolver:   fakeResolver,
+		discoveryClient:  fakeDiscovery,
+		resourceEmulator: emulator.NewEmulator(),
+	}
+
+	newRGD := func() *v1alpha1.ResourceGraphDefinition {
+		return generator.NewResourceGraphDefinition("test-group",
+			generator.WithSchema("Test", "v1alpha1", map[string]interface{}{"name": "string"}, nil),
+			generator.WithResource("vpc", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "VPC",
+				"metadata":   map[string]interface{}{"name": "test-vpc"},
+			}, nil, nil),
+			generator.WithResource("subnet", map[string]interface{}{
+				"apiVersion": "ec2.services.k8s.aws/v1alpha1",
+				"kind":       "Subnet",
+				"metadata":   map[string]interface{}{"name": "test-subnet"},
+				"spec":       map[string]interface{}{"vpcID": "${vpc.status.vpcID}"},
+			}, nil, nil),
+		)
+	}
+
+	t.Run("resource timeouts take precedence over the defaults", func(t *testing.T) {
+		rgd := newRGD()
+		rgd.Spec.ReadyTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+		rgd.Spec.DeleteTimeout = &metav1.Duration{Duration: 5 * time.Minute}
+		rgd.Spec.Resources[1].ReadyTimeout = &metav1.Duration{Duration: time.Minute}
+
+		g, err := builder.NewResourceGraphDefinition(context.Background(), rgd)
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Minute, g.Resources["vpc"].GetReadyTimeout())
+		assert.Equal(t, 5*time.Minute, g.Resources["vpc"].GetDeleteTimeout())
+		assert.Equal(t, time.Minute, g.Resources["subnet"].GetReadyTimeout())
+		assert.Equal(t, 5*time.Minute, g.Resources["subnet"].GetDeleteTimeout())
+	})
+
+	t.Run("timeouts are unset by default", func(t *testing.T) {
+		g, err := builder.NewResourceGraphDefinition(context.Background(), newRGD())
+		require.NoError(t, err)
+		assert.Zero(t, g.Resources["vpc"].GetReadyTimeout())
+		assert.Zero(t, g.Resources["vpc"].GetDeleteTimeout())
+	})
+
+	t.Run("negative timeouts are rejected", func(t *testing.T) {
+		rgd := newRGD()
+		rgd.Spec.Resources[0].DeleteTimeout = &metav1.Duration{Duration: -time.Second}
+
+		_, err := builder.NewResourceGraphDefinition(context.Background(), rgd)
+		assert.ErrorContains(t, err, "resource vpc timeouts must not be negative")
+	})
+}
+
 func TestNewBuilder(t *testing.T) {
 	builder, err := NewBuilder(&rest.Config{})
 	assert.Nil(t, err)
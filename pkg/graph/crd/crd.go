@@ -81,6 +81,12 @@ func newCRDSchema(spec, status extv1.JSONSchemaProps, statusFieldsOverride bool)
 		if _, ok := status.Properties["conditions"]; !ok {
 			status.Properties["conditions"] = defaultConditionsType
 		}
+		if _, ok := status.Properties["plan"]; !ok {
+			status.Properties["plan"] = defaultPlanType
+		}
+		if _, ok := status.Properties["revision"]; !ok {
+			status.Properties["revision"] = defaultRevisionType
+		}
 	}
 
 	return &extv1.JSONSchemaProps{
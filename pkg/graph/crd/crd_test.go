@@ -222,6 +222,8 @@ func TestNewCRDSchema(t *testing.T) {
 			if tt.expectedStateField {
 				assert.Contains(t, statusProps.Properties, "state")
 				assert.Equal(t, defaultConditionsType, statusProps.Properties["conditions"])
+				assert.Equal(t, defaultPlanType, statusProps.Properties["plan"])
+				assert.Equal(t, defaultRevisionType, statusProps.Properties["revision"])
 			}
 
 			if tt.status.Properties != nil {
@@ -18,6 +18,8 @@ import (
 )
 
 var (
+	preserveUnknownFields = true
+
 	defaultStateType = extv1.JSONSchemaProps{
 		Type: "string",
 	}
@@ -49,6 +51,18 @@ var (
 			},
 		},
 	}
+	// defaultPlanType holds the changes planned for instances with the
+	// kro.run/reconcile: plan annotation.
+	defaultPlanType = extv1.JSONSchemaProps{
+		Type:                   "object",
+		XPreserveUnknownFields: &preserveUnknownFields,
+	}
+	// defaultRevisionType holds the ResourceGraphDefinition revision the
+	// instance was last reconciled with.
+	defaultRevisionType = extv1.JSONSchemaProps{
+		Type:   "integer",
+		Format: "int64",
+	}
 	// additionalPrinterColumns specifies additional columns returned in Table output.
 	// See https://kubernetes.io/docs/reference/using-api/api-concepts/#receiving-resources-as-tables for details.
 	// Sample output for `kubectl get clusters`
@@ -15,12 +15,14 @@ package graph
 
 import (
 	"slices"
+	"time"
 
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 
+	"github.com/kro-run/kro/api/v1alpha1"
 	"github.com/kro-run/kro/pkg/graph/variable"
 )
 
@@ -72,6 +74,30 @@ type Resource struct {
 	// order reflects the original order in which the resources were specified,
 	// and lets us keep the client-specified ordering where the dependencies allow.
 	order int
+	// adoptionPolicy defines how existing objects that aren't owned by the
+	// instance are handled.
+	adoptionPolicy v1alpha1.AdoptionPolicy
+	// ignoreDifferences is a list of field paths ignored when comparing the
+	// desired and observed objects.
+	ignoreDifferences []string
+	// updatePolicy defines how changes are applied to existing objects.
+	updatePolicy v1alpha1.UpdatePolicy
+	// explicitDependencies are the dependencies declared in the resource
+	// dependsOn list. They are also part of dependencies.
+	explicitDependencies []string
+	// healthCheckPolicy defines how readiness is assessed when there are no
+	// readyWhen expressions.
+	healthCheckPolicy v1alpha1.HealthCheckPolicy
+	// healthCheckExpressions are the expressions of the ResourceGraphDefinition
+	// health check matching the resource kind, referring to the resource as
+	// runtime.HealthCheckSelf.
+	healthCheckExpressions []string
+	// readyTimeout is how long the resource can wait for readiness, zero
+	// means forever.
+	readyTimeout time.Duration
+	// deleteTimeout is how long the deletion of the resource can take, zero
+	// means the controller default.
+	deleteTimeout time.Duration
 }
 
 // GetDependencies returns the dependencies of the resource.
@@ -79,6 +105,36 @@ func (r *Resource) GetDependencies() []string {
 	return r.dependencies
 }
 
+// GetExplicitDependencies returns the dependencies declared in the resource
+// dependsOn list.
+func (r *Resource) GetExplicitDependencies() []string {
+	return r.explicitDependencies
+}
+
+// GetHealthCheckPolicy returns how readiness is assessed when the resource
+// has no readyWhen expressions.
+func (r *Resource) GetHealthCheckPolicy() v1alpha1.HealthCheckPolicy {
+	return r.healthCheckPolicy
+}
+
+// GetHealthCheckExpressions returns the health check expressions of the
+// resource kind, if any.
+func (r *Resource) GetHealthCheckExpressions() []string {
+	return r.healthCheckExpressions
+}
+
+// GetReadyTimeout returns how long the resource can wait for readiness, or
+// zero if it can wait forever.
+func (r *Resource) GetReadyTimeout() time.Duration {
+	return r.readyTimeout
+}
+
+// GetDeleteTimeout returns how long the deletion of the resource can take, or
+// zero if it isn't set.
+func (r *Resource) GetDeleteTimeout() time.Duration {
+	return r.deleteTimeout
+}
+
 // HasDependency checks if the resource has a dependency on another resource.
 func (r *Resource) HasDependency(dep string) bool {
 	for _, d := range r.dependencies {
@@ -158,6 +214,22 @@ func (r *Resource) IsNamespaced() bool {
 	return r.namespaced
 }
 
+// GetAdoptionPolicy returns the adoption policy of the resource.
+func (r *Resource) GetAdoptionPolicy() v1alpha1.AdoptionPolicy {
+	return r.adoptionPolicy
+}
+
+// GetIgnoreDifferences returns the field paths ignored when comparing the
+// desired and observed objects.
+func (r *Resource) GetIgnoreDifferences() []string {
+	return r.ignoreDifferences
+}
+
+// GetUpdatePolicy returns the update policy of the resource.
+func (r *Resource) GetUpdatePolicy() v1alpha1.UpdatePolicy {
+	return r.updatePolicy
+}
+
 // DeepCopy returns a deep copy of the resource.
 func (r *Resource) DeepCopy() *Resource {
 	return &Resource{
@@ -171,5 +243,13 @@ func (r *Resource) DeepCopy() *Resource {
 		readyWhenExpressions:   slices.Clone(r.readyWhenExpressions),
 		includeWhenExpressions: slices.Clone(r.includeWhenExpressions),
 		namespaced:             r.namespaced,
+		adoptionPolicy:         r.adoptionPolicy,
+		ignoreDifferences:      slices.Clone(r.ignoreDifferences),
+		updatePolicy:           r.updatePolicy,
+		explicitDependencies:   slices.Clone(r.explicitDependencies),
+		healthCheckPolicy:      r.healthCheckPolicy,
+		healthCheckExpressions: slices.Clone(r.healthCheckExpressions),
+		readyTimeout:           r.readyTimeout,
+		deleteTimeout:          r.deleteTimeout,
 	}
 }
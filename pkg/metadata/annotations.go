@@ -0,0 +1,49 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metadata
+
+const (
+	// ServiceAccountAnnotation is the instance annotation used to request the
+	// service account, in the instance namespace, that kro impersonates when
+	// reconciling the instance sub-resources.
+	ServiceAccountAnnotation = LabelKROPrefix + "service-account"
+	// ServiceAccountRequesterAnnotation is the instance annotation holding the
+	// user that requested the service account. The user must be allowed to
+	// impersonate the requested service account.
+	ServiceAccountRequesterAnnotation = LabelKROPrefix + "service-account-requester"
+	// AbandonOnDeleteTimeoutAnnotation is the instance annotation that, when
+	// set to "true", lets kro finish deleting the instance when some of its
+	// sub-resources exceeded their delete timeout. Those sub-resources are left
+	// behind in the cluster.
+	AbandonOnDeleteTimeoutAnnotation = LabelKROPrefix + "abandon-on-delete-timeout"
+	// ReconcileAnnotation is the instance annotation controlling whether kro
+	// reconciles the instance. Setting it to ReconcilePaused stops kro from
+	// creating, updating or deleting the instance sub-resources, until the
+	// instance itself is deleted.
+	ReconcileAnnotation = LabelKROPrefix + "reconcile"
+	// ReconcilePaused is the ReconcileAnnotation value pausing reconciliation.
+	ReconcilePaused = "paused"
+	// ReconcilePlan is the ReconcileAnnotation value pausing reconciliation
+	// and writing the changes kro would make in the instance status plan.
+	ReconcilePlan = "plan"
+	// ResourceGraphDefinitionRevisionAnnotation is the instance annotation
+	// holding the revision of the ResourceGraphDefinition, the generation that
+	// changed its graph, the instance is reconciled with. Staged rollouts move
+	// it forward, see v1alpha1.RolloutPolicy.
+	ResourceGraphDefinitionRevisionAnnotation = LabelKROPrefix + "resource-graph-definition-revision"
+	// PinnedRevisionAnnotation is the instance annotation pinning the instance
+	// to a revision of its ResourceGraphDefinition, e.g to roll back a change.
+	// Pinned instances are left out of staged rollouts.
+	PinnedRevisionAnnotation = LabelKROPrefix + "pinned-revision"
+)
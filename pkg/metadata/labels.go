@@ -46,6 +46,15 @@ const (
 	ResourceGraphDefinitionVersionLabel   = LabelKROPrefix + "resource-graph-definition-version"
 )
 
+const (
+	// ShardLabel is the bucket of an instance, when the instances are sharded
+	// across the controller replicas.
+	ShardLabel = LabelKROPrefix + "shard"
+	// ShardMemberLabel marks the Leases of the controller replicas sharing
+	// the instances.
+	ShardMemberLabel = LabelKROPrefix + "shard-member"
+)
+
 // IsKROOwned returns true if the resource is owned by KRO.
 func IsKROOwned(meta metav1.ObjectMeta) bool {
 	v, ok := meta.Labels[OwnedLabel]
@@ -0,0 +1,181 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package health
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// deploymentReady checks that the rollout of a Deployment is complete, the
+// same way "kubectl rollout status" does.
+func deploymentReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if ready, reason := observedLatestGeneration(obj); !ready {
+		return false, reason, nil
+	}
+	progressing, found, err := getCondition(obj, "Progressing")
+	if err != nil {
+		return false, "", err
+	}
+	if found && progressing.reason == "ProgressDeadlineExceeded" {
+		return false, fmt.Sprintf("rollout exceeded its progress deadline: %s", progressing.message), nil
+	}
+
+	replicas, err := getInt64(obj, 1, "spec", "replicas")
+	if err != nil {
+		return false, "", err
+	}
+	statusReplicas, err := getInt64(obj, 0, "status", "replicas")
+	if err != nil {
+		return false, "", err
+	}
+	updated, err := getInt64(obj, 0, "status", "updatedReplicas")
+	if err != nil {
+		return false, "", err
+	}
+	available, err := getInt64(obj, 0, "status", "availableReplicas")
+	if err != nil {
+		return false, "", err
+	}
+
+	switch {
+	case updated < replicas:
+		return false, fmt.Sprintf("%d out of %d new replicas have been updated", updated, replicas), nil
+	case statusReplicas > updated:
+		return false, fmt.Sprintf("%d old replicas are pending termination", statusReplicas-updated), nil
+	case available < updated:
+		return false, fmt.Sprintf("%d of %d updated replicas are available", available, updated), nil
+	}
+	return true, "", nil
+}
+
+// statefulSetReady checks that every replica of a StatefulSet is ready and
+// runs the latest revision.
+func statefulSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if ready, reason := observedLatestGeneration(obj); !ready {
+		return false, reason, nil
+	}
+	replicas, err := getInt64(obj, 1, "spec", "replicas")
+	if err != nil {
+		return false, "", err
+	}
+	readyReplicas, err := getInt64(obj, 0, "status", "readyReplicas")
+	if err != nil {
+		return false, "", err
+	}
+	if readyReplicas < replicas {
+		return false, fmt.Sprintf("%d of %d replicas are ready", readyReplicas, replicas), nil
+	}
+
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+	if strategy == "OnDelete" {
+		return true, "", nil
+	}
+	currentRevision, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+	updateRevision, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+	if updateRevision != "" && currentRevision != updateRevision {
+		updated, err := getInt64(obj, 0, "status", "updatedReplicas")
+		if err != nil {
+			return false, "", err
+		}
+		partition, err := getInt64(obj, 0, "spec", "updateStrategy", "rollingUpdate", "partition")
+		if err != nil {
+			return false, "", err
+		}
+		if updated < replicas-partition {
+			return false, fmt.Sprintf("%d out of %d new replicas have been updated", updated, replicas-partition), nil
+		}
+	}
+	return true, "", nil
+}
+
+// daemonSetReady checks that the DaemonSet pods are updated and available on
+// every node they are scheduled on.
+func daemonSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if ready, reason := observedLatestGeneration(obj); !ready {
+		return false, reason, nil
+	}
+	desired, err := getInt64(obj, 0, "status", "desiredNumberScheduled")
+	if err != nil {
+		return false, "", err
+	}
+	updated, err := getInt64(obj, 0, "status", "updatedNumberScheduled")
+	if err != nil {
+		return false, "", err
+	}
+	available, err := getInt64(obj, 0, "status", "numberAvailable")
+	if err != nil {
+		return false, "", err
+	}
+	switch {
+	case updated < desired:
+		return false, fmt.Sprintf("%d out of %d new pods have been updated", updated, desired), nil
+	case available < desired:
+		return false, fmt.Sprintf("%d of %d updated pods are available", available, desired), nil
+	}
+	return true, "", nil
+}
+
+// jobReady checks that a Job succeeded.
+func jobReady(obj *unstructured.Unstructured) (bool, string, error) {
+	failed, found, err := getCondition(obj, "Failed")
+	if err != nil {
+		return false, "", err
+	}
+	if found && failed.status == "True" {
+		return false, fmt.Sprintf("job failed: %s", failed.message), nil
+	}
+	complete, found, err := getCondition(obj, "Complete")
+	if err != nil {
+		return false, "", err
+	}
+	if !found || complete.status != "True" {
+		return false, "job has not completed", nil
+	}
+	return true, "", nil
+}
+
+// serviceReady checks that LoadBalancer Services have been assigned an
+// address. Other Services are ready as soon as they exist.
+func serviceReady(obj *unstructured.Unstructured) (bool, string, error) {
+	serviceType, _, err := unstructured.NestedString(obj.Object, "spec", "type")
+	if err != nil {
+		return false, "", err
+	}
+	if serviceType != "LoadBalancer" {
+		return true, "", nil
+	}
+	ingress, _, err := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+	if err != nil {
+		return false, "", err
+	}
+	if len(ingress) == 0 {
+		return false, "load balancer address is not assigned", nil
+	}
+	return true, "", nil
+}
+
+// customResourceDefinitionReady checks that a CustomResourceDefinition is
+// established, i.e its custom resources can be created.
+func customResourceDefinitionReady(obj *unstructured.Unstructured) (bool, string, error) {
+	established, found, err := getCondition(obj, "Established")
+	if err != nil {
+		return false, "", err
+	}
+	if !found || established.status != "True" {
+		return false, "custom resource definition is not established", nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package health assesses the readiness of Kubernetes objects that don't
+// define readyWhen expressions.
+//
+// Well-known kinds (Deployments, StatefulSets, DaemonSets, Jobs, Services and
+// CustomResourceDefinitions) have dedicated checks. Every other kind is
+// assessed with a generic check in the style of kstatus: the object must have
+// observed its latest generation, and its Ready condition, if any, must be
+// True.
+//
+// PersistentVolumeClaims have no dedicated check: claims of a
+// WaitForFirstConsumer storage class stay Pending until a pod uses them, so
+// waiting for them to be bound would deadlock the pods depending on them.
+package health
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Check assesses the readiness of an object. It returns whether the object is
+// ready, and a human readable reason when it isn't.
+type Check func(obj *unstructured.Unstructured) (ready bool, reason string, err error)
+
+var (
+	mu     sync.RWMutex
+	checks = map[schema.GroupKind]Check{
+		{Group: "apps", Kind: "Deployment"}:                               deploymentReady,
+		{Group: "apps", Kind: "StatefulSet"}:                              statefulSetReady,
+		{Group: "apps", Kind: "DaemonSet"}:                                daemonSetReady,
+		{Group: "batch", Kind: "Job"}:                                     jobReady,
+		{Group: "", Kind: "Service"}:                                      serviceReady,
+		{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: customResourceDefinitionReady,
+	}
+)
+
+// Register registers the check used for the given kind, replacing the
+// built-in one if any.
+func Register(gk schema.GroupKind, check Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[gk] = check
+}
+
+// Assess assesses the readiness of an object with the check registered for its
+// kind, or the generic check.
+func Assess(obj *unstructured.Unstructured) (bool, string, error) {
+	mu.RLock()
+	check, ok := checks[obj.GroupVersionKind().GroupKind()]
+	mu.RUnlock()
+	if !ok {
+		check = genericReady
+	}
+	return check(obj)
+}
+
+// genericReady checks that the object observed its latest generation, and
+// that its Ready condition is True when it has one.
+func genericReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if ready, reason := observedLatestGeneration(obj); !ready {
+		return false, reason, nil
+	}
+	condition, found, err := getCondition(obj, "Ready")
+	if err != nil || !found {
+		return err == nil, "", err
+	}
+	if condition.status != "True" {
+		return false, fmt.Sprintf("Ready condition is %s: %s", condition.status, condition.message), nil
+	}
+	return true, "", nil
+}
+
+// observedLatestGeneration returns false if the object reports an observed
+// generation older than its generation, i.e its controller didn't process the
+// latest spec yet. Objects that don't report an observed generation pass.
+func observedLatestGeneration(obj *unstructured.Unstructured) (bool, string) {
+	observedGeneration, found, err := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		return true, ""
+	}
+	if observedGeneration < obj.GetGeneration() {
+		return false, fmt.Sprintf("waiting for generation %d to be observed, observed generation is %d",
+			obj.GetGeneration(), observedGeneration)
+	}
+	return true, ""
+}
+
+type condition struct {
+	status  string
+	reason  string
+	message string
+}
+
+// getCondition returns the status.conditions entry of the given type.
+func getCondition(obj *unstructured.Unstructured, conditionType string) (condition, bool, error) {
+	conditions, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || !found {
+		return condition{}, false, err
+	}
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != conditionType {
+			continue
+		}
+		status, _ := m["status"].(string)
+		reason, _ := m["reason"].(string)
+		message, _ := m["message"].(string)
+		return condition{status: status, reason: reason, message: message}, true, nil
+	}
+	return condition{}, false, nil
+}
+
+// getInt64 returns the int64 at the given path, or def if it is not set.
+func getInt64(obj *unstructured.Unstructured, def int64, fields ...string) (int64, error) {
+	value, found, err := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+	if err != nil || !found || value == nil {
+		return def, err
+	}
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	default:
+		return def, fmt.Errorf("%v accessor error: %v is of the type %T, expected int64", fields, value, value)
+	}
+}
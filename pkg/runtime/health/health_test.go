@@ -0,0 +1,223 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newObject(apiVersion, kind string, generation int64, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	if obj.Object == nil {
+		obj.Object = map[string]interface{}{}
+	}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetGeneration(generation)
+	return obj
+}
+
+func TestAssess(t *testing.T) {
+	tests := []struct {
+		name       string
+		obj        *unstructured.Unstructured
+		want       bool
+		wantReason string
+	}{
+		{
+			name: "deployment rolled out",
+			obj: newObject("apps/v1", "Deployment", 2, map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"replicas":           int64(3),
+					"updatedReplicas":    int64(3),
+					"availableReplicas":  int64(3),
+				},
+			}),
+			want: true,
+		},
+		{
+			name: "deployment generation not observed",
+			obj: newObject("apps/v1", "Deployment", 2, map[string]interface{}{
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+				},
+			}),
+			wantReason: "waiting for generation 2 to be observed, observed generation is 1",
+		},
+		{
+			name: "deployment rollout in progress",
+			obj: newObject("apps/v1", "Deployment", 1, map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"replicas":        int64(4),
+					"updatedReplicas": int64(3),
+				},
+			}),
+			wantReason: "1 old replicas are pending termination",
+		},
+		{
+			name: "deployment not available",
+			obj: newObject("apps/v1", "Deployment", 1, map[string]interface{}{
+				"status": map[string]interface{}{
+					"replicas":          int64(1),
+					"updatedReplicas":   int64(1),
+					"availableReplicas": int64(0),
+				},
+			}),
+			wantReason: "0 of 1 updated replicas are available",
+		},
+		{
+			name: "deployment progress deadline exceeded",
+			obj: newObject("apps/v1", "Deployment", 1, map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{
+							"type":    "Progressing",
+							"status":  "False",
+							"reason":  "ProgressDeadlineExceeded",
+							"message": "timed out",
+						},
+					},
+				},
+			}),
+			wantReason: "rollout exceeded its progress deadline: timed out",
+		},
+		{
+			name: "statefulset partially updated",
+			obj: newObject("apps/v1", "StatefulSet", 1, map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{
+					"readyReplicas":   int64(2),
+					"updatedReplicas": int64(1),
+					"currentRevision": "a",
+					"updateRevision":  "b",
+				},
+			}),
+			wantReason: "1 out of 2 new replicas have been updated",
+		},
+		{
+			name: "statefulset ready",
+			obj: newObject("apps/v1", "StatefulSet", 1, map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{
+					"readyReplicas":   int64(2),
+					"currentRevision": "b",
+					"updateRevision":  "b",
+				},
+			}),
+			want: true,
+		},
+		{
+			name: "daemonset not available",
+			obj: newObject("apps/v1", "DaemonSet", 1, map[string]interface{}{
+				"status": map[string]interface{}{
+					"desiredNumberScheduled": int64(3),
+					"updatedNumberScheduled": int64(3),
+					"numberAvailable":        int64(2),
+				},
+			}),
+			wantReason: "2 of 3 updated pods are available",
+		},
+		{
+			name: "job succeeded",
+			obj: newObject("batch/v1", "Job", 1, map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Complete", "status": "True"},
+					},
+				},
+			}),
+			want: true,
+		},
+		{
+			name: "job failed",
+			obj: newObject("batch/v1", "Job", 1, map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Failed", "status": "True", "message": "backoff limit exceeded"},
+					},
+				},
+			}),
+			wantReason: "job failed: backoff limit exceeded",
+		},
+		{
+			name: "pvc waiting for first consumer",
+			obj: newObject("v1", "PersistentVolumeClaim", 0, map[string]interface{}{
+				"status": map[string]interface{}{"phase": "Pending"},
+			}),
+			want: true,
+		},
+		{
+			name: "cluster ip service",
+			obj:  newObject("v1", "Service", 0, nil),
+			want: true,
+		},
+		{
+			name: "load balancer service without address",
+			obj: newObject("v1", "Service", 0, map[string]interface{}{
+				"spec": map[string]interface{}{"type": "LoadBalancer"},
+			}),
+			wantReason: "load balancer address is not assigned",
+		},
+		{
+			name: "custom resource without status",
+			obj:  newObject("example.com/v1", "Database", 1, nil),
+			want: true,
+		},
+		{
+			name: "custom resource not ready",
+			obj: newObject("example.com/v1", "Database", 1, map[string]interface{}{
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False", "message": "provisioning"},
+					},
+				},
+			}),
+			wantReason: "Ready condition is False: provisioning",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, reason, err := Assess(tt.obj)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, ready)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	gk := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+	Register(gk, func(*unstructured.Unstructured) (bool, string, error) {
+		return false, "never ready", nil
+	})
+	defer func() {
+		mu.Lock()
+		delete(checks, gk)
+		mu.Unlock()
+	}()
+
+	ready, reason, err := Assess(newObject("example.com/v1", "Widget", 0, nil))
+	assert.NoError(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "never ready", reason)
+}
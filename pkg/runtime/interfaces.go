@@ -14,9 +14,13 @@
 package runtime
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 
+	"github.com/kro-run/kro/api/v1alpha1"
 	"github.com/kro-run/kro/pkg/graph/variable"
 )
 
@@ -112,6 +116,38 @@ type ResourceDescriptor interface {
 	// IsNamespaced returns true if the resource is namespaced, and false if it's
 	// cluster-scoped.
 	IsNamespaced() bool
+
+	// GetAdoptionPolicy returns how existing objects that aren't owned by the
+	// instance are handled.
+	GetAdoptionPolicy() v1alpha1.AdoptionPolicy
+
+	// GetIgnoreDifferences returns the field paths ignored when comparing the
+	// desired and observed objects.
+	GetIgnoreDifferences() []string
+
+	// GetUpdatePolicy returns how changes are applied to existing objects.
+	GetUpdatePolicy() v1alpha1.UpdatePolicy
+
+	// GetHealthCheckPolicy returns how readiness is assessed when there are no
+	// readyWhen expressions.
+	GetHealthCheckPolicy() v1alpha1.HealthCheckPolicy
+
+	// GetHealthCheckExpressions returns the expressions assessing readiness
+	// when there are no readyWhen expressions, referring to the resource as
+	// HealthCheckSelf. When empty, the built-in health checks are used.
+	GetHealthCheckExpressions() []string
+
+	// GetReadyTimeout returns how long the resource can wait for readiness, or
+	// zero if it can wait forever.
+	GetReadyTimeout() time.Duration
+
+	// GetDeleteTimeout returns how long the deletion of the resource can take,
+	// or zero if the controller default applies.
+	GetDeleteTimeout() time.Duration
+
+	// GetSchema returns the OpenAPI schema of the resource, or nil if it is
+	// unknown.
+	GetSchema() *spec.Schema
 }
 
 // Resource extends `ResourceDescriptor` to include the actual resource data.
@@ -22,8 +22,10 @@ import (
 	"golang.org/x/exp/maps"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
+	"github.com/kro-run/kro/api/v1alpha1"
 	krocel "github.com/kro-run/kro/pkg/cel"
 	"github.com/kro-run/kro/pkg/graph/variable"
+	"github.com/kro-run/kro/pkg/runtime/health"
 	"github.com/kro-run/kro/pkg/runtime/resolver"
 )
 
@@ -472,17 +474,40 @@ func (rt *ResourceGraphDefinitionRuntime) IsResourceReady(resourceID string) (bo
 
 	expressions := rt.resources[resourceID].GetReadyWhenExpressions()
 	if len(expressions) == 0 {
+		return rt.assessHealth(resourceID, observed)
+	}
+	return evaluateReadinessExpressions(resourceID, observed, expressions)
+}
+
+// HealthCheckSelf is the name health check expressions use to refer to the
+// resource they assess.
+const HealthCheckSelf = "self"
+
+// assessHealth assesses the readiness of a resource without readyWhen
+// expressions, using the health check expressions of its kind if any, or the
+// built-in health checks.
+func (rt *ResourceGraphDefinitionRuntime) assessHealth(resourceID string, observed *unstructured.Unstructured) (bool, string, error) {
+	resource := rt.resources[resourceID]
+	if resource.GetHealthCheckPolicy() == v1alpha1.HealthCheckPolicyNone {
 		return true, "", nil
 	}
+	if expressions := resource.GetHealthCheckExpressions(); len(expressions) > 0 {
+		return evaluateReadinessExpressions(HealthCheckSelf, observed, expressions)
+	}
+	return health.Assess(observed)
+}
 
+// evaluateReadinessExpressions evaluates readiness expressions referring to
+// the observed object with the given name.
+func evaluateReadinessExpressions(name string, observed *unstructured.Unstructured, expressions []string) (bool, string, error) {
 	// we should not expect errors here since we already compiled it
 	// in the dryRun
-	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{resourceID}))
+	env, err := krocel.DefaultEnvironment(krocel.WithResourceIDs([]string{name}))
 	if err != nil {
 		return false, "", fmt.Errorf("failed creating new Environment: %w", err)
 	}
 	context := map[string]interface{}{
-		resourceID: observed.Object,
+		name: observed.Object,
 	}
 
 	for _, expression := range expressions {
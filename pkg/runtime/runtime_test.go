@@ -18,11 +18,14 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/cel-go/cel"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 
+	"github.com/kro-run/kro/api/v1alpha1"
 	krocel "github.com/kro-run/kro/pkg/cel"
 	"github.com/kro-run/kro/pkg/graph/variable"
 )
@@ -2253,6 +2256,57 @@ func Test_IsResourceReady(t *testing.T) {
 			want:       false,
 			wantReason: "expression test.status.healthy evaluated to false",
 		},
+		{
+			name: "built-in health check",
+			resource: newTestResource(
+				withReadyExpressions(nil),
+			),
+			resolvedObject: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+			},
+			want:       false,
+			wantReason: "job has not completed",
+		},
+		{
+			name: "health check disabled",
+			resource: newTestResource(
+				withReadyExpressions(nil),
+				withHealthCheck(v1alpha1.HealthCheckPolicyNone, nil),
+			),
+			resolvedObject: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+			},
+			want: true,
+		},
+		{
+			name: "custom health check",
+			resource: newTestResource(
+				withReadyExpressions(nil),
+				withHealthCheck(v1alpha1.HealthCheckPolicyDefault, []string{"self.status.phase == 'Ready'"}),
+			),
+			resolvedObject: map[string]interface{}{
+				"status": map[string]interface{}{
+					"phase": "Pending",
+				},
+			},
+			want:       false,
+			wantReason: "expression self.status.phase == 'Ready' evaluated to false",
+		},
+		{
+			name: "readyWhen takes precedence over health checks",
+			resource: newTestResource(
+				withReadyExpressions([]string{"test.status.ready"}),
+				withHealthCheck(v1alpha1.HealthCheckPolicyDefault, []string{"false"}),
+			),
+			resolvedObject: map[string]interface{}{
+				"status": map[string]interface{}{
+					"ready": true,
+				},
+			},
+			want: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -2606,14 +2660,17 @@ func Test_containsAllElements(t *testing.T) {
 }
 
 type mockResource struct {
-	gvr              schema.GroupVersionResource
-	variables        []*variable.ResourceField
-	dependencies     []string
-	readyExpressions []string
-	conditions       []string
-	topLevelFields   []string
-	namespaced       bool
-	obj              *unstructured.Unstructured
+	gvr                    schema.GroupVersionResource
+	variables              []*variable.ResourceField
+	dependencies           []string
+	readyExpressions       []string
+	conditions             []string
+	topLevelFields         []string
+	namespaced             bool
+	adoptionPolicy         v1alpha1.AdoptionPolicy
+	healthCheckPolicy      v1alpha1.HealthCheckPolicy
+	healthCheckExpressions []string
+	obj                    *unstructured.Unstructured
 }
 
 func newMockResource() *mockResource {
@@ -2648,6 +2705,38 @@ func (m *mockResource) IsNamespaced() bool {
 	return m.namespaced
 }
 
+func (m *mockResource) GetAdoptionPolicy() v1alpha1.AdoptionPolicy {
+	return m.adoptionPolicy
+}
+
+func (m *mockResource) GetIgnoreDifferences() []string {
+	return nil
+}
+
+func (m *mockResource) GetUpdatePolicy() v1alpha1.UpdatePolicy {
+	return v1alpha1.UpdatePolicyUpdate
+}
+
+func (m *mockResource) GetHealthCheckPolicy() v1alpha1.HealthCheckPolicy {
+	return m.healthCheckPolicy
+}
+
+func (m *mockResource) GetHealthCheckExpressions() []string {
+	return m.healthCheckExpressions
+}
+
+func (m *mockResource) GetReadyTimeout() time.Duration {
+	return 0
+}
+
+func (m *mockResource) GetDeleteTimeout() time.Duration {
+	return 0
+}
+
+func (m *mockResource) GetSchema() *spec.Schema {
+	return nil
+}
+
 func (m *mockResource) Unstructured() *unstructured.Unstructured {
 	return m.obj
 }
@@ -2682,6 +2771,13 @@ func withReadyExpressions(exprs []string) mockResourceOption {
 	}
 }
 
+func withHealthCheck(policy v1alpha1.HealthCheckPolicy, exprs []string) mockResourceOption {
+	return func(m *mockResource) {
+		m.healthCheckPolicy = policy
+		m.healthCheckExpressions = exprs
+	}
+}
+
 func withConditions(conditions []string) mockResourceOption {
 	return func(m *mockResource) {
 		m.conditions = conditions
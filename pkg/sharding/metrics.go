@@ -0,0 +1,43 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sharding
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	// Register metrics with the global prometheus registry
+	metrics.Registry.MustRegister(
+		shardMembers,
+		shardOwnedBuckets,
+	)
+}
+
+var (
+	shardMembers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "shard_members",
+			Help: "Number of live controller replicas sharing the instances",
+		},
+	)
+	shardOwnedBuckets = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "shard_owned_buckets",
+			Help: "Number of instance buckets owned by the replica",
+		},
+	)
+)
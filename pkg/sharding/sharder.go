@@ -0,0 +1,465 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package sharding splits the instances reconciled by kro across the replicas
+// of the controller.
+//
+// Instances are hashed, by namespace/name or UID, into a fixed number of
+// buckets recorded in the metadata.ShardLabel. Each replica announces itself
+// with a Lease, and the buckets are assigned to the live replicas with
+// rendezvous (highest random weight) hashing, so that only the buckets of the
+// replicas that join or leave move. Replicas only watch the instances of the
+// buckets they own, and the instances that aren't labeled yet.
+//
+// Buckets are released before they are acquired by another replica: a replica
+// drops the buckets it loses as soon as it sees the new membership, and waits
+// one lease duration before acquiring the buckets of replicas that are still
+// live, by which time they have seen it too. Replicas that can't renew their
+// Lease or refresh the membership drop all their buckets before the other
+// replicas consider them gone.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+// Key is what instances are hashed by.
+type Key string
+
+const (
+	// KeyName hashes instances by namespace/name.
+	KeyName Key = "name"
+	// KeyUID hashes instances by UID.
+	KeyUID Key = "uid"
+)
+
+const (
+	// DefaultBuckets is the default number of buckets instances are hashed
+	// into.
+	DefaultBuckets = 64
+	// DefaultLeaseDuration is the default duration after which replicas that
+	// didn't renew their Lease are considered gone.
+	DefaultLeaseDuration = 15 * time.Second
+	// DefaultRenewInterval is the default interval at which replicas renew
+	// their Lease and refresh the membership.
+	DefaultRenewInterval = 5 * time.Second
+
+	leasePrefix = "kro-shard-"
+)
+
+// Config holds the configuration of a Sharder.
+type Config struct {
+	// ID identifies the replica, e.g its pod name.
+	ID string
+	// Namespace is the namespace of the Leases of the replicas.
+	Namespace string
+	// Key is what instances are hashed by.
+	Key Key
+	// Buckets is the number of buckets instances are hashed into. Buckets are
+	// the unit of assignment to replicas, all the replicas must use the same
+	// number.
+	Buckets int
+	// LeaseDuration is the duration after which replicas that didn't renew
+	// their Lease are considered gone.
+	LeaseDuration time.Duration
+	// RenewInterval is the interval at which replicas renew their Lease and
+	// refresh the membership.
+	RenewInterval time.Duration
+}
+
+// Sharder assigns the buckets of instances to the live replicas of the
+// controller. It implements the dynamiccontroller.Sharder interface.
+type Sharder struct {
+	config Config
+	client kubernetes.Interface
+	log    logr.Logger
+	clock  clock.PassiveClock
+
+	mu sync.RWMutex
+	// members are the IDs of the live replicas, sorted.
+	members []string
+	// owners are the IDs of the replicas each bucket is assigned to.
+	owners []string
+	// acquireAt holds the time at which the replica acquires the buckets
+	// assigned to it that other live replicas may still own.
+	acquireAt map[int]time.Time
+	// renewed and refreshed are the times of the last successful renewal of
+	// the Lease of the replica and refresh of the membership.
+	renewed   time.Time
+	refreshed time.Time
+
+	ready      chan struct{}
+	readyOnce  sync.Once
+	rebalanced chan struct{}
+}
+
+var _ manager.LeaderElectionRunnable = &Sharder{}
+
+// NewSharder creates a new Sharder.
+func NewSharder(log logr.Logger, config Config, client kubernetes.Interface) (*Sharder, error) {
+	if config.ID == "" {
+		return nil, fmt.Errorf("shard ID is required")
+	}
+	if config.Namespace == "" {
+		return nil, fmt.Errorf("shard lease namespace is required")
+	}
+	switch config.Key {
+	case KeyName, KeyUID:
+	case "":
+		config.Key = KeyName
+	default:
+		return nil, fmt.Errorf("invalid shard key %q, must be one of: %s, %s", config.Key, KeyName, KeyUID)
+	}
+	if config.Buckets <= 0 {
+		config.Buckets = DefaultBuckets
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = DefaultLeaseDuration
+	}
+	if config.RenewInterval <= 0 {
+		config.RenewInterval = DefaultRenewInterval
+	}
+	if config.RenewInterval >= config.LeaseDuration {
+		return nil, fmt.Errorf("shard renew interval %s must be shorter than the lease duration %s",
+			config.RenewInterval, config.LeaseDuration)
+	}
+
+	return &Sharder{
+		config:     config,
+		client:     client,
+		log:        log.WithName("sharder").WithValues("shard", config.ID),
+		clock:      clock.RealClock{},
+		owners:     make([]string, config.Buckets),
+		acquireAt:  make(map[int]time.Time),
+		ready:      make(chan struct{}),
+		rebalanced: make(chan struct{}, 1),
+	}, nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every replica
+// runs its Sharder.
+func (s *Sharder) NeedLeaderElection() bool {
+	return false
+}
+
+// Start renews the Lease of the replica and refreshes the membership until
+// the context is canceled, and then releases the Lease so that the other
+// replicas take over its buckets without waiting for it to expire.
+func (s *Sharder) Start(ctx context.Context) error {
+	s.log.Info("Starting sharder", "buckets", s.config.Buckets, "key", s.config.Key)
+	defer s.release()
+
+	ticker := time.NewTicker(s.config.RenewInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.renew(ctx); err != nil {
+			s.log.Error(err, "Failed to renew shard lease")
+		}
+		if err := s.refresh(ctx); err != nil {
+			s.log.Error(err, "Failed to refresh shard members")
+			s.fence()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ready returns a channel that is closed once the membership is known.
+func (s *Sharder) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Rebalanced returns a channel receiving a value when the buckets owned by
+// the replica change.
+func (s *Sharder) Rebalanced() <-chan struct{} {
+	return s.rebalanced
+}
+
+// Selector returns the label selector of the instances the replica watches:
+// the instances of the buckets it owns, and the instances that aren't
+// labeled yet.
+func (s *Sharder) Selector() labels.Selector {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var others []string
+	for bucket := range s.owners {
+		if !s.owns(bucket) {
+			others = append(others, strconv.Itoa(bucket))
+		}
+	}
+	if len(others) == 0 {
+		return labels.Everything()
+	}
+	requirement, err := labels.NewRequirement(metadata.ShardLabel, selection.NotIn, others)
+	if err != nil {
+		// Bucket numbers are always valid label values.
+		panic(err)
+	}
+	return labels.NewSelector().Add(*requirement)
+}
+
+// Assign returns the bucket of an instance, as the value of its
+// metadata.ShardLabel, and whether the replica owns it.
+func (s *Sharder) Assign(obj metav1.Object) (string, bool) {
+	bucket := s.bucket(obj)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return strconv.Itoa(bucket), s.owns(bucket)
+}
+
+// owns returns true if the replica owns the bucket, i.e it is assigned to it
+// and acquired. It must be called with the lock held.
+func (s *Sharder) owns(bucket int) bool {
+	_, acquiring := s.acquireAt[bucket]
+	return s.owners[bucket] == s.config.ID && !acquiring
+}
+
+// bucket returns the bucket an instance is hashed into.
+func (s *Sharder) bucket(obj metav1.Object) int {
+	key := obj.GetNamespace() + "/" + obj.GetName()
+	if s.config.Key == KeyUID {
+		key = string(obj.GetUID())
+	}
+	return int(hash(key) % uint64(s.config.Buckets))
+}
+
+// renew creates or renews the Lease of the replica.
+func (s *Sharder) renew(ctx context.Context) error {
+	leases := s.client.CoordinationV1().Leases(s.config.Namespace)
+	now := metav1.NewMicroTime(s.clock.Now())
+
+	lease, err := leases.Get(ctx, leasePrefix+s.config.ID, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   leasePrefix + s.config.ID,
+				Labels: map[string]string{metadata.ShardMemberLabel: "true"},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(s.config.ID),
+				LeaseDurationSeconds: ptr.To(int32(s.config.LeaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}, metav1.CreateOptions{})
+	} else if err == nil {
+		lease.Spec.HolderIdentity = ptr.To(s.config.ID)
+		lease.Spec.LeaseDurationSeconds = ptr.To(int32(s.config.LeaseDuration.Seconds()))
+		lease.Spec.RenewTime = &now
+		_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.renewed = now.Time
+	return nil
+}
+
+// refresh lists the Leases of the replicas, and rebalances the buckets when
+// the live replicas change. The replica is only a member while its own Lease
+// is renewed.
+func (s *Sharder) refresh(ctx context.Context) error {
+	leases, err := s.client.CoordinationV1().Leases(s.config.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metadata.ShardMemberLabel + "=true",
+	})
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	s.mu.Lock()
+	s.refreshed = now
+	renewed := s.renewed
+	s.mu.Unlock()
+
+	var members []string
+	if !s.stale(renewed, now) {
+		members = append(members, s.config.ID)
+	}
+	for _, lease := range leases.Items {
+		if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == s.config.ID {
+			continue
+		}
+		if expired(&lease, now) {
+			continue
+		}
+		members = append(members, *lease.Spec.HolderIdentity)
+	}
+
+	s.rebalance(members, now)
+	s.readyOnce.Do(func() { close(s.ready) })
+	return nil
+}
+
+// fence drops the buckets of the replica when the membership couldn't be
+// refreshed in time, before the other replicas consider the replica gone.
+func (s *Sharder) fence() {
+	now := s.clock.Now()
+	s.mu.RLock()
+	refreshed := s.refreshed
+	s.mu.RUnlock()
+	if !refreshed.IsZero() && s.stale(refreshed, now) {
+		s.rebalance(nil, now)
+	}
+}
+
+// stale returns true when the replica must stop relying on a renewal or a
+// refresh made at the given time: the other replicas may consider it gone
+// before its next attempt.
+func (s *Sharder) stale(at, now time.Time) bool {
+	return !now.Before(at.Add(s.config.LeaseDuration - s.config.RenewInterval))
+}
+
+// rebalance sets the live replicas, and notifies the rebalancing when the
+// buckets owned by the replica change.
+func (s *Sharder) rebalance(members []string, now time.Time) {
+	if s.setMembers(members, now) {
+		s.log.Info("Rebalanced shards", "members", members)
+		select {
+		case s.rebalanced <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// release drops the buckets of the replica and deletes its Lease.
+func (s *Sharder) release() {
+	s.setMembers(nil, s.clock.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.RenewInterval)
+	defer cancel()
+
+	err := s.client.CoordinationV1().Leases(s.config.Namespace).Delete(ctx, leasePrefix+s.config.ID, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		s.log.Error(err, "Failed to release shard lease")
+	}
+}
+
+// setMembers sets the live replicas and assigns the buckets to them. The
+// buckets the replica loses are released right away, and the buckets it
+// gets from replicas that are still live are acquired one lease duration
+// later. It returns true if the buckets owned by the replica changed.
+func (s *Sharder) setMembers(members []string, now time.Time) bool {
+	slices.Sort(members)
+	members = slices.Compact(members)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owned := s.ownedBuckets()
+
+	if !slices.Equal(s.members, members) {
+		owners := assign(members, s.config.Buckets)
+		for bucket, owner := range owners {
+			previous := s.owners[bucket]
+			switch {
+			case owner != s.config.ID:
+				delete(s.acquireAt, bucket)
+			case previous == s.config.ID:
+			case slices.Contains(members, previous) || (previous == "" && len(members) > 1):
+				// The previous owner, if any, may still reconcile the
+				// instances of the bucket until it sees the new
+				// membership.
+				s.acquireAt[bucket] = now.Add(s.config.LeaseDuration)
+			}
+		}
+		s.members, s.owners = members, owners
+	}
+	for bucket, at := range s.acquireAt {
+		if !now.Before(at) {
+			delete(s.acquireAt, bucket)
+		}
+	}
+
+	changed := !slices.Equal(owned, s.ownedBuckets())
+	shardMembers.Set(float64(len(members)))
+	shardOwnedBuckets.Set(float64(len(s.ownedBuckets())))
+	return changed
+}
+
+// ownedBuckets returns the buckets owned by the replica. It must be called
+// with the lock held.
+func (s *Sharder) ownedBuckets() []int {
+	var owned []int
+	for bucket := range s.owners {
+		if s.owns(bucket) {
+			owned = append(owned, bucket)
+		}
+	}
+	return owned
+}
+
+// assign returns the owner of each bucket, the member with the highest hash
+// of the member and the bucket.
+func assign(members []string, buckets int) []string {
+	owners := make([]string, buckets)
+	for bucket := range owners {
+		var best uint64
+		for _, member := range members {
+			if h := hash(member + "/" + strconv.Itoa(bucket)); owners[bucket] == "" || h > best {
+				owners[bucket], best = member, h
+			}
+		}
+	}
+	return owners
+}
+
+// expired returns true if the Lease wasn't renewed within its duration.
+func expired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	return lease.Spec.RenewTime.Add(duration).Before(now)
+}
+
+// hash returns the FNV-1a hash of the string, mixed with the splitmix64
+// finalizer since FNV alone spreads similar strings poorly.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
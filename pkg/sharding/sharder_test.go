@@ -0,0 +1,244 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+
+	"github.com/kro-run/kro/pkg/metadata"
+)
+
+func newTestSharder(t *testing.T, id string, client *fake.Clientset, clock *clocktesting.FakeClock) *Sharder {
+	t.Helper()
+	s, err := NewSharder(logr.Discard(), Config{ID: id, Namespace: "kro-system", Buckets: 32}, client)
+	require.NoError(t, err)
+	s.clock = clock
+	return s
+}
+
+// join renews the Lease of the replicas, and refreshes their membership.
+func join(t *testing.T, sharders ...*Sharder) {
+	t.Helper()
+	for _, s := range sharders {
+		require.NoError(t, s.renew(context.Background()))
+	}
+	for _, s := range sharders {
+		require.NoError(t, s.refresh(context.Background()))
+	}
+}
+
+// rebalanced returns true if the replica was notified of a rebalancing.
+func rebalanced(s *Sharder) bool {
+	select {
+	case <-s.Rebalanced():
+		return true
+	default:
+		return false
+	}
+}
+
+// owned returns the number of buckets owned by the replica.
+func owned(s *Sharder) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.ownedBuckets())
+}
+
+func TestAssignMovesOnlyBucketsOfNewMembers(t *testing.T) {
+	before := assign([]string{"a", "b", "c"}, 256)
+	after := assign([]string{"a", "b", "c", "d"}, 256)
+
+	moved := 0
+	for bucket := range before {
+		if before[bucket] != after[bucket] {
+			assert.Equal(t, "d", after[bucket], "bucket %d moved between existing members", bucket)
+			moved++
+		}
+	}
+	assert.Greater(t, moved, 0)
+	assert.Less(t, moved, 256/2)
+}
+
+func TestAssignIsBalanced(t *testing.T) {
+	owners := assign([]string{"a", "b", "c", "d"}, 1024)
+
+	counts := map[string]int{}
+	for _, owner := range owners {
+		counts[owner]++
+	}
+	for member, count := range counts {
+		assert.InDelta(t, 256, count, 100, "member %s", member)
+	}
+}
+
+func TestSelectorAndAssign(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	clock := clocktesting.NewFakeClock(time.Now())
+	a := newTestSharder(t, "a", client, clock)
+	b := newTestSharder(t, "b", client, clock)
+
+	// Alone, a replica owns all the buckets.
+	join(t, a)
+	assert.True(t, a.Selector().Empty())
+	assert.True(t, rebalanced(a))
+
+	// The buckets of b are released by a first, and acquired by b one lease
+	// duration later.
+	join(t, a, b)
+	assert.True(t, rebalanced(a))
+	assert.Zero(t, owned(b))
+	clock.Step(DefaultLeaseDuration)
+	join(t, a, b)
+	assert.True(t, rebalanced(b))
+
+	for i := 0; i < 100; i++ {
+		obj := &metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("instance-%d", i)}
+		shardA, ownedA := a.Assign(obj)
+		shardB, ownedB := b.Assign(obj)
+		assert.Equal(t, shardA, shardB)
+		assert.NotEqual(t, ownedA, ownedB, "instance %s must be owned by exactly one replica", obj.Name)
+
+		// Each replica watches the labeled instances it owns only.
+		set := labels.Set{metadata.ShardLabel: shardA}
+		assert.Equal(t, ownedA, a.Selector().Matches(set))
+		assert.Equal(t, ownedB, b.Selector().Matches(set))
+	}
+
+	// Both replicas watch the instances that aren't labeled yet.
+	assert.True(t, a.Selector().Matches(labels.Set{}))
+	assert.True(t, b.Selector().Matches(labels.Set{}))
+}
+
+func TestRefreshIgnoresExpiredLeases(t *testing.T) {
+	stale := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+	client := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      leasePrefix + "b",
+			Namespace: "kro-system",
+			Labels:    map[string]string{metadata.ShardMemberLabel: "true"},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To("b"),
+			LeaseDurationSeconds: ptr.To(int32(15)),
+			RenewTime:            &stale,
+		},
+	})
+	a := newTestSharder(t, "a", client, clocktesting.NewFakeClock(time.Now()))
+
+	join(t, a)
+	assert.Equal(t, []string{"a"}, a.members)
+	assert.True(t, a.Selector().Empty())
+
+	select {
+	case <-a.Ready():
+	default:
+		t.Fatal("expected the sharder to be ready after a refresh")
+	}
+}
+
+func TestHandoff(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	clock := clocktesting.NewFakeClock(time.Now())
+	a := newTestSharder(t, "a", client, clock)
+	b := newTestSharder(t, "b", client, clock)
+	join(t, a)
+	require.Equal(t, 32, owned(a))
+	rebalanced(a)
+
+	// b joins, a only owns its own buckets as soon as it sees b, and b
+	// waits for a to release its buckets.
+	join(t, b, a)
+	ownedByA := owned(a)
+	assert.Less(t, ownedByA, 32)
+	assert.True(t, rebalanced(a))
+	assert.Zero(t, owned(b))
+	assert.False(t, rebalanced(b))
+
+	clock.Step(DefaultLeaseDuration - time.Second)
+	join(t, a, b)
+	assert.Zero(t, owned(b))
+
+	clock.Step(time.Second)
+	join(t, a, b)
+	assert.Equal(t, 32-ownedByA, owned(b))
+	assert.True(t, rebalanced(b))
+
+	// The buckets of replicas that left are acquired right away.
+	b.release()
+	assert.Zero(t, owned(b))
+	join(t, a)
+	assert.Equal(t, 32, owned(a))
+	assert.True(t, rebalanced(a))
+}
+
+func TestFencing(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	clock := clocktesting.NewFakeClock(time.Now())
+	a := newTestSharder(t, "a", client, clock)
+	join(t, a)
+	require.Equal(t, 32, owned(a))
+	rebalanced(a)
+
+	// The replica drops its buckets when it can't refresh the membership
+	// before the other replicas consider it gone.
+	clock.Step(DefaultLeaseDuration - DefaultRenewInterval - time.Second)
+	a.fence()
+	assert.Equal(t, 32, owned(a))
+	clock.Step(time.Second)
+	a.fence()
+	assert.Zero(t, owned(a))
+	assert.True(t, rebalanced(a))
+
+	// Or when it can't renew its Lease.
+	join(t, a)
+	require.Equal(t, 32, owned(a))
+	client.PrependReactor("update", "leases", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		return true, nil, errors.New("unavailable")
+	})
+	clock.Step(DefaultLeaseDuration - DefaultRenewInterval)
+	assert.Error(t, a.renew(context.Background()))
+	require.NoError(t, a.refresh(context.Background()))
+	assert.Zero(t, owned(a))
+}
+
+func TestNewSharderValidatesKey(t *testing.T) {
+	_, err := NewSharder(logr.Discard(), Config{ID: "a", Namespace: "kro-system", Key: "labels"}, fake.NewSimpleClientset())
+	assert.Error(t, err)
+}
+
+func TestNewSharderValidatesRenewInterval(t *testing.T) {
+	_, err := NewSharder(logr.Discard(), Config{
+		ID:            "a",
+		Namespace:     "kro-system",
+		LeaseDuration: 10 * time.Second,
+		RenewInterval: 10 * time.Second,
+	}, fake.NewSimpleClientset())
+	assert.ErrorContains(t, err, "must be shorter than the lease duration")
+}
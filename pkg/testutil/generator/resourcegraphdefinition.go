@@ -15,6 +15,7 @@ package generator
 
 import (
 	"encoding/json"
+	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -91,3 +92,17 @@ func WithResource(
 		})
 	}
 }
+
+// WithDependsOn sets the explicit dependencies of a resource previously added
+// with WithResource.
+func WithDependsOn(id string, dependsOn ...string) ResourceGraphDefinitionOption {
+	return func(rgd *krov1alpha1.ResourceGraphDefinition) {
+		for _, resource := range rgd.Spec.Resources {
+			if resource.ID == id {
+				resource.DependsOn = dependsOn
+				return
+			}
+		}
+		panic(fmt.Sprintf("resource %s not found", id))
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// Compile time proof that resourceInterface implements dynamic.ResourceInterface.
+var _ dynamic.ResourceInterface = &resourceInterface{}
+
+// WrapResourceInterface returns a dynamic.ResourceInterface that records a
+// span for every call made to the API server. Calls that are not explicitly
+// traced are passed through to the wrapped client.
+func WrapResourceInterface(ri dynamic.ResourceInterface, gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	return &resourceInterface{
+		ResourceInterface: ri,
+		attrs: []attribute.KeyValue{
+			attribute.String("k8s.resource.group", gvr.Group),
+			attribute.String("k8s.resource.version", gvr.Version),
+			attribute.String("k8s.resource.resource", gvr.Resource),
+			attribute.String("k8s.namespace.name", namespace),
+		},
+	}
+}
+
+type resourceInterface struct {
+	dynamic.ResourceInterface
+	attrs []attribute.KeyValue
+}
+
+// start starts an API call span for the given verb and object name.
+func (r *resourceInterface) start(ctx context.Context, verb, name string) (context.Context, trace.Span) {
+	attrs := append([]attribute.KeyValue{
+		attribute.String("k8s.verb", verb),
+		attribute.String("k8s.object.name", name),
+	}, r.attrs...)
+	return Tracer().Start(ctx, "kubernetes."+verb,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (r *resourceInterface) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (_ *unstructured.Unstructured, err error) {
+	ctx, span := r.start(ctx, "create", obj.GetName())
+	defer End(span, &err)
+	return r.ResourceInterface.Create(ctx, obj, options, subresources...)
+}
+
+func (r *resourceInterface) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (_ *unstructured.Unstructured, err error) {
+	ctx, span := r.start(ctx, "update", obj.GetName())
+	defer End(span, &err)
+	return r.ResourceInterface.Update(ctx, obj, options, subresources...)
+}
+
+func (r *resourceInterface) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (_ *unstructured.Unstructured, err error) {
+	ctx, span := r.start(ctx, "update_status", obj.GetName())
+	defer End(span, &err)
+	return r.ResourceInterface.UpdateStatus(ctx, obj, options)
+}
+
+func (r *resourceInterface) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) (err error) {
+	ctx, span := r.start(ctx, "delete", name)
+	defer End(span, &err)
+	return r.ResourceInterface.Delete(ctx, name, options, subresources...)
+}
+
+func (r *resourceInterface) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (_ *unstructured.Unstructured, err error) {
+	ctx, span := r.start(ctx, "get", name)
+	defer End(span, &err)
+	return r.ResourceInterface.Get(ctx, name, options, subresources...)
+}
+
+func (r *resourceInterface) List(ctx context.Context, opts metav1.ListOptions) (_ *unstructured.UnstructuredList, err error) {
+	ctx, span := r.start(ctx, "list", "")
+	defer End(span, &err)
+	return r.ResourceInterface.List(ctx, opts)
+}
+
+func (r *resourceInterface) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (_ *unstructured.Unstructured, err error) {
+	ctx, span := r.start(ctx, "patch", name)
+	defer End(span, &err)
+	return r.ResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+}
+
+func (r *resourceInterface) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, options metav1.ApplyOptions, subresources ...string) (_ *unstructured.Unstructured, err error) {
+	ctx, span := r.start(ctx, "apply", name)
+	defer End(span, &err)
+	return r.ResourceInterface.Apply(ctx, name, obj, options, subresources...)
+}
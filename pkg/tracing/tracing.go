@@ -0,0 +1,167 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tracing wires OpenTelemetry tracing into kro. Tracing is disabled
+// by default: until Setup is called with an exporter, the global no-op tracer
+// provider is used and spans cost close to nothing.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/release-utils/version"
+
+	"github.com/kro-run/kro/pkg/requeue"
+)
+
+const (
+	// TracerName is the name of the tracer used by all kro components.
+	TracerName = "github.com/kro-run/kro"
+	// ServiceName is the service name reported with every span.
+	ServiceName = "kro"
+)
+
+// Exporter is the name of a supported span exporter.
+type Exporter string
+
+const (
+	// ExporterNone disables tracing.
+	ExporterNone Exporter = "none"
+	// ExporterOTLPGRPC exports spans using OTLP over gRPC.
+	ExporterOTLPGRPC Exporter = "otlp-grpc"
+	// ExporterOTLPHTTP exports spans using OTLP over HTTP.
+	ExporterOTLPHTTP Exporter = "otlp-http"
+)
+
+// Config holds the configuration of the tracing pipeline.
+type Config struct {
+	// Exporter selects the span exporter. An empty value is equivalent
+	// to ExporterNone.
+	Exporter Exporter
+	// Endpoint is the collector endpoint (host:port). When empty, the
+	// exporter falls back to the standard OTEL_EXPORTER_OTLP_* variables.
+	Endpoint string
+	// Insecure disables TLS when talking to the collector.
+	Insecure bool
+	// SampleRatio is the ratio of root spans that are sampled, between 0 and 1.
+	SampleRatio float64
+}
+
+// ShutdownFunc flushes and stops the tracing pipeline.
+type ShutdownFunc func(context.Context) error
+
+// Setup configures the global tracer provider according to the given config.
+// The returned ShutdownFunc must be called before the process exits to flush
+// any pending spans.
+func Setup(ctx context.Context, cfg Config) (ShutdownFunc, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLPGRPC:
+		opts := []otlptracegrpc.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	tp := NewTracerProvider(exporter, cfg.SampleRatio)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return tp.Shutdown, nil
+}
+
+// NewTracerProvider returns a tracer provider that batches spans to the
+// given exporter. It is exposed so that tests can plug an in-memory exporter.
+func NewTracerProvider(exporter sdktrace.SpanExporter, sampleRatio float64) *sdktrace.TracerProvider {
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(version.GetVersionInfo().GitVersion),
+	)
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+}
+
+// Tracer returns the kro tracer from the global tracer provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Start starts a new span with the given name and attributes.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if any, and ends it. It is meant to be
+// deferred with a pointer to a named error return value.
+//
+// Requeue errors are part of the normal reconciliation flow (e.g waiting for
+// a resource to become ready), they are recorded as span events rather than
+// marking the span as failed.
+func End(span trace.Span, err *error) {
+	defer span.End()
+	if err == nil || *err == nil {
+		return
+	}
+	if isRequeue(*err) {
+		span.AddEvent("requeue", trace.WithAttributes(attribute.String("reason", (*err).Error())))
+		return
+	}
+	span.RecordError(*err)
+	span.SetStatus(codes.Error, (*err).Error())
+}
+
+func isRequeue(err error) bool {
+	var needed *requeue.RequeueNeeded
+	var neededAfter *requeue.RequeueNeededAfter
+	return errors.As(err, &needed) || errors.As(err, &neededAfter)
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/kro-run/kro/pkg/requeue"
+)
+
+// setupInMemoryTracing installs a tracer provider backed by an in-memory
+// exporter and restores the previous provider when the test ends.
+func setupInMemoryTracing(t *testing.T) (*tracetest.InMemoryExporter, func() tracetest.SpanStubs) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := NewTracerProvider(exporter, 1)
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = tp.Shutdown(context.Background())
+	})
+
+	return exporter, func() tracetest.SpanStubs {
+		require.NoError(t, tp.ForceFlush(context.Background()))
+		return exporter.GetSpans()
+	}
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func attributeValue(attrs []attribute.KeyValue, key string) (string, bool) {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return attr.Value.Emit(), true
+		}
+	}
+	return "", false
+}
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "empty exporter disables tracing", config: Config{}},
+		{name: "none exporter disables tracing", config: Config{Exporter: ExporterNone}},
+		{name: "unknown exporter", config: Config{Exporter: "zipkin"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestEnd(t *testing.T) {
+	_, flush := setupInMemoryTracing(t)
+
+	ctx := context.Background()
+
+	_, span := Start(ctx, "success")
+	var noErr error
+	End(span, &noErr)
+
+	_, span = Start(ctx, "failure")
+	failure := errors.New("boom")
+	End(span, &failure)
+
+	_, span = Start(ctx, "requeue")
+	var requeueErr error = requeue.NeededAfter(errors.New("waiting for readiness"), time.Second)
+	End(span, &requeueErr)
+
+	spans := flush()
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, codes.Unset, findSpan(spans, "success").Status.Code)
+
+	failed := findSpan(spans, "failure")
+	assert.Equal(t, codes.Error, failed.Status.Code)
+	assert.Equal(t, "boom", failed.Status.Description)
+
+	requeued := findSpan(spans, "requeue")
+	assert.Equal(t, codes.Unset, requeued.Status.Code)
+	require.Len(t, requeued.Events, 1)
+	assert.Equal(t, "requeue", requeued.Events[0].Name)
+}
+
+func TestWrapResourceInterface(t *testing.T) {
+	_, flush := setupInMemoryTracing(t)
+
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gvr: "DeploymentList",
+	})
+	rc := WrapResourceInterface(client.Resource(gvr).Namespace("default"), gvr, "default")
+
+	ctx, parent := Start(context.Background(), "parent")
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetName("web")
+	obj.SetNamespace("default")
+
+	_, err := rc.Create(ctx, obj, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = rc.Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, err = rc.Get(ctx, "missing", metav1.GetOptions{})
+	require.Error(t, err)
+	require.NoError(t, rc.Delete(ctx, "web", metav1.DeleteOptions{}))
+	parent.End()
+
+	spans := flush()
+	require.Len(t, spans, 5)
+
+	parentSpan := findSpan(spans, "parent")
+	require.NotNil(t, parentSpan)
+
+	for _, name := range []string{"kubernetes.create", "kubernetes.delete"} {
+		span := findSpan(spans, name)
+		require.NotNil(t, span, name)
+		assert.Equal(t, parentSpan.SpanContext.SpanID(), span.Parent.SpanID())
+
+		resource, _ := attributeValue(span.Attributes, "k8s.resource.resource")
+		assert.Equal(t, "deployments", resource)
+		objectName, _ := attributeValue(span.Attributes, "k8s.object.name")
+		assert.Equal(t, "web", objectName)
+	}
+
+	var failedGets int
+	for _, span := range spans {
+		if span.Name == "kubernetes.get" && span.Status.Code == codes.Error {
+			failedGets++
+		}
+	}
+	assert.Equal(t, 1, failedGets)
+}
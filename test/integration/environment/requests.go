@@ -0,0 +1,89 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package environment
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RequestCounter counts the reads of single objects made by the controllers
+// to the API server, by resource.
+type RequestCounter struct {
+	mu   sync.Mutex
+	gets map[string]int
+}
+
+func newRequestCounter() *RequestCounter {
+	return &RequestCounter{gets: make(map[string]int)}
+}
+
+// Gets returns the number of objects of the resource read since the last
+// Reset.
+func (c *RequestCounter) Gets(resource string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gets[resource]
+}
+
+// Reset resets the counts.
+func (c *RequestCounter) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gets = make(map[string]int)
+}
+
+// wrap wraps the transport of the controllers clients.
+func (c *RequestCounter) wrap(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if resource, ok := objectResource(req); ok {
+			c.mu.Lock()
+			c.gets[resource]++
+			c.mu.Unlock()
+		}
+		return rt.RoundTrip(req)
+	})
+}
+
+// objectResource returns the resource of a GET request for a single object,
+// e.g /api/v1/namespaces/default/configmaps/name or
+// /apis/apps/v1/deployments/name.
+func objectResource(req *http.Request) (string, bool) {
+	if req.Method != http.MethodGet || req.URL.Query().Get("watch") == "true" {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(parts) > 0 && parts[0] == "api":
+		parts = parts[min(2, len(parts)):]
+	case len(parts) > 0 && parts[0] == "apis":
+		parts = parts[min(3, len(parts)):]
+	default:
+		return "", false
+	}
+	if len(parts) >= 2 && parts[0] == "namespaces" && len(parts) != 2 {
+		parts = parts[2:]
+	}
+	if len(parts) != 2 {
+		return "", false
+	}
+	return parts[0], true
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -47,6 +48,9 @@ type Environment struct {
 	ClientSet        *kroclient.Set
 	CRDManager       kroclient.CRDClient
 	GraphBuilder     *graph.Builder
+	// Requests counts the requests made by the controllers, the requests of
+	// Client aren't counted.
+	Requests *RequestCounter
 }
 
 type ControllerConfig struct {
@@ -84,8 +88,11 @@ func New(controllerConfig ControllerConfig) (*Environment, error) {
 		return nil, fmt.Errorf("starting test environment: %w", err)
 	}
 
+	env.Requests = newRequestCounter()
+	countedConfig := rest.CopyConfig(cfg)
+	countedConfig.Wrap(env.Requests.wrap)
 	clientSet, err := kroclient.NewSet(kroclient.Config{
-		RestConfig: cfg,
+		RestConfig: countedConfig,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("creating client set: %w", err)
@@ -98,7 +105,7 @@ func New(controllerConfig ControllerConfig) (*Environment, error) {
 	}
 
 	// Initialize clients
-	if err := env.initializeClients(); err != nil {
+	if err := env.initializeClients(cfg); err != nil {
 		return nil, fmt.Errorf("initializing clients: %w", err)
 	}
 
@@ -111,10 +118,10 @@ func New(controllerConfig ControllerConfig) (*Environment, error) {
 	return env, nil
 }
 
-func (e *Environment) initializeClients() error {
+func (e *Environment) initializeClients(cfg *rest.Config) error {
 	var err error
 
-	e.Client, err = client.New(e.ClientSet.RESTConfig(), client.Options{Scheme: scheme.Scheme})
+	e.Client, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	if err != nil {
 		return fmt.Errorf("creating client: %w", err)
 	}
@@ -145,18 +152,16 @@ func (e *Environment) setupController() error {
 		},
 		e.ClientSet.Dynamic())
 
-	go func() {
-		err := dc.Run(e.context)
-		if err != nil {
-			panic(fmt.Sprintf("failed to run dynamic controller: %v", err))
-		}
-	}()
+	resourceCache := kroclient.NewResourceCache(noopLogger(), e.ClientSet.Dynamic(), nil)
 
 	rgReconciler := ctrlresourcegraphdefinition.NewResourceGraphDefinitionReconciler(
 		e.ClientSet,
 		e.ControllerConfig.AllowCRDDeletion,
 		dc,
 		e.GraphBuilder,
+		kroclient.NewImpersonationCache(e.ClientSet, kroclient.DefaultImpersonationCacheSize),
+		kroclient.NewServiceAccountAuthorizer(e.ClientSet.Kubernetes(), ""),
+		resourceCache,
 		1,
 	)
 
@@ -175,6 +180,12 @@ func (e *Environment) setupController() error {
 	if err = rgReconciler.SetupWithManager(e.CtrlManager); err != nil {
 		return fmt.Errorf("setting up reconciler: %w", err)
 	}
+	if err = e.CtrlManager.Add(dc); err != nil {
+		return fmt.Errorf("adding dynamic controller: %w", err)
+	}
+	if err = e.CtrlManager.Add(resourceCache); err != nil {
+		return fmt.Errorf("adding resource cache: %w", err)
+	}
 
 	go func() {
 		if err := e.CtrlManager.Start(e.context); err != nil {
@@ -261,19 +261,6 @@ var _ = Describe("Conditions", func() {
 		}
 		Expect(env.Client.Create(ctx, instance)).To(Succeed())
 
-		// Check if instance is created
-		Eventually(func(g Gomega) {
-			err := env.Client.Get(ctx, types.NamespacedName{
-				Name:      name,
-				Namespace: namespace,
-			}, instance)
-			g.Expect(err).ToNot(HaveOccurred())
-			val, b, err := unstructured.NestedString(instance.Object, "status", "state")
-			g.Expect(err).ToNot(HaveOccurred())
-			g.Expect(b).To(BeTrue())
-			g.Expect(val).To(Equal("ACTIVE"))
-		}, 20*time.Second, time.Second).Should(Succeed())
-
 		// Verify DeploymentA is not created
 		Eventually(func(g Gomega) bool {
 			err := env.Client.Get(ctx, types.NamespacedName{
@@ -307,6 +294,26 @@ var _ = Describe("Conditions", func() {
 			g.Expect(deploymentB.Spec.Template.Spec.ServiceAccountName).To(Equal(name + "-a" + name))
 		}, 20*time.Second, time.Second).Should(Succeed())
 
+		// Mark DeploymentB as rolled out
+		deploymentB.Status.Replicas = 1
+		deploymentB.Status.UpdatedReplicas = 1
+		deploymentB.Status.ReadyReplicas = 1
+		deploymentB.Status.AvailableReplicas = 1
+		Expect(env.Client.Status().Update(ctx, deploymentB)).To(Succeed())
+
+		// Check if instance becomes active
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{
+				Name:      name,
+				Namespace: namespace,
+			}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			val, b, err := unstructured.NestedString(instance.Object, "status", "state")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(b).To(BeTrue())
+			g.Expect(val).To(Equal("ACTIVE"))
+		}, 20*time.Second, time.Second).Should(Succeed())
+
 		// Verify ServiceA is not created
 		Eventually(func(g Gomega) bool {
 			err := env.Client.Get(ctx, types.NamespacedName{
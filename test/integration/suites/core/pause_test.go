@@ -0,0 +1,179 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+)
+
+var _ = Describe("Pause", func() {
+	var (
+		ctx       context.Context
+		namespace string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = fmt.Sprintf("test-%s", rand.String(5))
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		}
+		Expect(env.Client.Create(ctx, ns)).To(Succeed())
+	})
+
+	It("should not touch the resources of paused instances", func() {
+		rgd := generator.NewResourceGraphDefinition("test-pause",
+			generator.WithSchema(
+				"TestPause", "v1alpha1",
+				map[string]interface{}{
+					"value": "string",
+				},
+				nil,
+			),
+			generator.WithResource("config", map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "${schema.metadata.name}",
+				},
+				"data": map[string]interface{}{
+					"value": "${schema.spec.value}",
+				},
+			}, nil, nil),
+		)
+		Expect(env.Client.Create(ctx, rgd)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.State).To(Equal(krov1alpha1.ResourceGraphDefinitionStateActive))
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		// Create a paused instance
+		name := "test-pause"
+		instance := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"),
+				"kind":       "TestPause",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": namespace,
+					"annotations": map[string]interface{}{
+						metadata.ReconcileAnnotation: metadata.ReconcilePaused,
+					},
+				},
+				"spec": map[string]interface{}{
+					"value": "initial",
+				},
+			},
+		}
+		Expect(env.Client.Create(ctx, instance)).To(Succeed())
+
+		// Verify the instance reports the paused state and nothing is created
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			state, _, _ := unstructured.NestedString(instance.Object, "status", "state")
+			g.Expect(state).To(Equal("PAUSED"))
+			conditions, _, _ := unstructured.NestedSlice(instance.Object, "status", "conditions")
+			g.Expect(conditions).To(ContainElement(HaveKeyWithValue("type", string(krov1alpha1.InstanceConditionTypePaused))))
+		}, 20*time.Second, time.Second).Should(Succeed())
+
+		Consistently(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &corev1.ConfigMap{})
+			return errors.IsNotFound(err)
+		}, 5*time.Second, time.Second).Should(BeTrue())
+
+		// Resume the instance
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			instance.SetAnnotations(nil)
+			g.Expect(env.Client.Update(ctx, instance)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(configMap.Data).To(HaveKeyWithValue("value", "initial"))
+		}, 20*time.Second, time.Second).Should(Succeed())
+
+		// Pause the instance again, and change the ConfigMap manually
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			instance.SetAnnotations(map[string]string{metadata.ReconcileAnnotation: metadata.ReconcilePaused})
+			g.Expect(env.Client.Update(ctx, instance)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			state, _, _ := unstructured.NestedString(instance.Object, "status", "state")
+			g.Expect(state).To(Equal("PAUSED"))
+		}, 20*time.Second, time.Second).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap)
+			g.Expect(err).ToNot(HaveOccurred())
+			configMap.Data["value"] = "manual"
+			g.Expect(env.Client.Update(ctx, configMap)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		// Verify the manual change is kept
+		Consistently(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(configMap.Data).To(HaveKeyWithValue("value", "manual"))
+		}, 5*time.Second, time.Second).Should(Succeed())
+
+		// Resume, delete the instance, and verify it is cleaned up
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			instance.SetAnnotations(nil)
+			g.Expect(env.Client.Update(ctx, instance)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		Expect(env.Client.Delete(ctx, instance)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+
+		Expect(env.Client.Delete(ctx, rgd)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, &krov1alpha1.ResourceGraphDefinition{})
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+	})
+})
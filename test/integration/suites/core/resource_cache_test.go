@@ -0,0 +1,173 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+)
+
+// resourceCacheHits returns the number of configmaps read from the resource
+// cache.
+func resourceCacheHits() float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != "client_resource_cache_reads_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["gvr"] == "/v1, Resource=configmaps" && labels["result"] == "hit" {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+var _ = Describe("Resource Cache", func() {
+	var (
+		ctx       context.Context
+		namespace string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = fmt.Sprintf("test-%s", rand.String(5))
+		Expect(env.Client.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		})).To(Succeed())
+	})
+
+	It("should read the resources of the instances from the cache", func() {
+		configMap := func(suffix string) map[string]interface{} {
+			return map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "${schema.metadata.name}-" + suffix,
+				},
+				"data": map[string]interface{}{
+					"value": "${schema.spec.value}",
+				},
+			}
+		}
+		rgd := generator.NewResourceGraphDefinition("test-resource-cache",
+			generator.WithSchema(
+				"TestResourceCache", "v1alpha1",
+				map[string]interface{}{
+					"value": "string",
+				},
+				nil,
+			),
+			generator.WithResource("configA", configMap("a"), nil, nil),
+			generator.WithResource("configB", configMap("b"), nil, nil),
+		)
+		Expect(env.Client.Create(ctx, rgd)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.State).To(Equal(krov1alpha1.ResourceGraphDefinitionStateActive))
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		name := "test-resource-cache"
+		instance := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"),
+				"kind":       "TestResourceCache",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": namespace,
+				},
+				"spec": map[string]interface{}{
+					"value": "v0",
+				},
+			},
+		}
+		Expect(env.Client.Create(ctx, instance)).To(Succeed())
+
+		expectSynced := func(value string) {
+			Eventually(func(g Gomega) {
+				for _, suffix := range []string{"a", "b"} {
+					cm := &corev1.ConfigMap{}
+					err := env.Client.Get(ctx, types.NamespacedName{Name: name + "-" + suffix, Namespace: namespace}, cm)
+					g.Expect(err).ToNot(HaveOccurred())
+					g.Expect(cm.Data).To(HaveKeyWithValue("value", value))
+				}
+				err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+				g.Expect(err).ToNot(HaveOccurred())
+				state, _, _ := unstructured.NestedString(instance.Object, "status", "state")
+				g.Expect(state).To(Equal("ACTIVE"))
+			}, 30*time.Second, time.Second).Should(Succeed())
+		}
+		expectSynced("v0")
+
+		// Update the instance a few times, each update reconciles the instance
+		// and reads both of its configmaps at least once.
+		const updates = 5
+		env.Requests.Reset()
+		hits := resourceCacheHits()
+		for i := 1; i <= updates; i++ {
+			value := fmt.Sprintf("v%d", i)
+			Eventually(func(g Gomega) {
+				err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(unstructured.SetNestedField(instance.Object, value, "spec", "value")).To(Succeed())
+				g.Expect(env.Client.Update(ctx, instance)).To(Succeed())
+			}, 10*time.Second, time.Second).Should(Succeed())
+			expectSynced(value)
+		}
+
+		cached := resourceCacheHits() - hits
+		live := env.Requests.Gets("configmaps")
+		GinkgoWriter.Printf("configmaps reads over %d updates: %.0f from the cache, %d from the API server\n",
+			updates, cached, live)
+		Expect(cached).To(BeNumerically(">=", 2*updates))
+		Expect(float64(live)).To(BeNumerically("<", cached/2),
+			"most reads are served from the cache")
+
+		Expect(env.Client.Delete(ctx, instance)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+
+		Expect(env.Client.Delete(ctx, rgd)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, &krov1alpha1.ResourceGraphDefinition{})
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+	})
+})
@@ -0,0 +1,391 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/utils/ptr"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+)
+
+var _ = Describe("Revisions", func() {
+	var (
+		ctx       context.Context
+		namespace string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = fmt.Sprintf("test-%s", rand.String(5))
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		}
+		Expect(env.Client.Create(ctx, ns)).To(Succeed())
+	})
+
+	It("should record revisions and reconcile pinned instances with their revision", func() {
+		configMap := func(suffix string) map[string]interface{} {
+			return map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "${schema.metadata.name}",
+				},
+				"data": map[string]interface{}{
+					"value": "${schema.spec.value}" + suffix,
+				},
+			}
+		}
+		rgd := generator.NewResourceGraphDefinition("test-revision",
+			generator.WithSchema(
+				"TestRevision", "v1alpha1",
+				map[string]interface{}{
+					"value": "string",
+				},
+				nil,
+			),
+			generator.WithResource("config", configMap(""), nil, nil),
+		)
+		Expect(env.Client.Create(ctx, rgd)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.State).To(Equal(krov1alpha1.ResourceGraphDefinitionStateActive))
+		}, 10*time.Second, time.Second).Should(Succeed())
+		firstRevision := rgd.Generation
+
+		// Verify the revision is recorded
+		revision := &krov1alpha1.ResourceGraphDefinitionRevision{}
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-%d", rgd.Name, firstRevision)}, revision)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(revision.Spec.Revision).To(Equal(firstRevision))
+			g.Expect(revision.Spec.GraphHash).ToNot(BeEmpty())
+			g.Expect(revision.Spec.TopologicalOrder).To(Equal([]string{"config"}))
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		name := "test-revision"
+		instance := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"),
+				"kind":       "TestRevision",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": namespace,
+				},
+				"spec": map[string]interface{}{
+					"value": "initial",
+				},
+			},
+		}
+		Expect(env.Client.Create(ctx, instance)).To(Succeed())
+
+		expectConfigMap := func(value string, revision int64) {
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, cm)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(cm.Data).To(HaveKeyWithValue("value", value))
+				g.Expect(cm.Labels).To(HaveKeyWithValue(
+					metadata.ResourceGraphDefinitionVersionLabel, fmt.Sprintf("%d", revision)))
+			}, 20*time.Second, time.Second).Should(Succeed())
+		}
+		expectConfigMap("initial", firstRevision)
+
+		// Change the template
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			rgd.Spec.Resources[0] = generator.NewResourceGraphDefinition("test-revision",
+				generator.WithResource("config", configMap("-v2"), nil, nil),
+			).Spec.Resources[0]
+			g.Expect(env.Client.Update(ctx, rgd)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+		expectConfigMap("initial-v2", rgd.Generation)
+
+		// Pin the instance to the first revision to roll back the change
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			annotations := instance.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[metadata.PinnedRevisionAnnotation] = fmt.Sprintf("%d", firstRevision)
+			instance.SetAnnotations(annotations)
+			g.Expect(env.Client.Update(ctx, instance)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+		expectConfigMap("initial", firstRevision)
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			revision, _, _ := unstructured.NestedInt64(instance.Object, "status", "revision")
+			g.Expect(revision).To(Equal(firstRevision))
+		}, 20*time.Second, time.Second).Should(Succeed())
+
+		// Delete the instance and the ResourceGraphDefinition
+		Expect(env.Client.Delete(ctx, instance)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+
+		Expect(env.Client.Delete(ctx, rgd)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, &krov1alpha1.ResourceGraphDefinition{})
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+	})
+
+	It("should only record the generations that change the graph, and prune old revisions", func() {
+		configMap := func(value string) map[string]interface{} {
+			return map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "${schema.metadata.name}",
+				},
+				"data": map[string]interface{}{
+					"value": value,
+				},
+			}
+		}
+		rgd := generator.NewResourceGraphDefinition("test-revision-history",
+			generator.WithSchema(
+				"TestRevisionHistory", "v1alpha1",
+				map[string]interface{}{
+					"value": "string",
+				},
+				nil,
+			),
+			generator.WithResource("config", configMap("v1"), nil, nil),
+		)
+		rgd.Spec.RevisionHistoryLimit = ptr.To[int32](2)
+		Expect(env.Client.Create(ctx, rgd)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.State).To(Equal(krov1alpha1.ResourceGraphDefinitionStateActive))
+		}, 10*time.Second, time.Second).Should(Succeed())
+		firstRevision := rgd.Generation
+
+		// Pin an instance to the first revision, so that it is never pruned
+		instance := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"),
+				"kind":       "TestRevisionHistory",
+				"metadata": map[string]interface{}{
+					"name":      "test-revision-history",
+					"namespace": namespace,
+					"annotations": map[string]interface{}{
+						metadata.PinnedRevisionAnnotation: fmt.Sprintf("%d", firstRevision),
+					},
+				},
+				"spec": map[string]interface{}{
+					"value": "initial",
+				},
+			},
+		}
+		Expect(env.Client.Create(ctx, instance)).To(Succeed())
+
+		update := func(mutate func(rgd *krov1alpha1.ResourceGraphDefinition)) int64 {
+			Eventually(func(g Gomega) {
+				err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+				g.Expect(err).ToNot(HaveOccurred())
+				mutate(rgd)
+				g.Expect(env.Client.Update(ctx, rgd)).To(Succeed())
+			}, 10*time.Second, time.Second).Should(Succeed())
+			return rgd.Generation
+		}
+		revisionExists := func(g Gomega, revision int64) bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-%d", rgd.Name, revision)},
+				&krov1alpha1.ResourceGraphDefinitionRevision{})
+			if errors.IsNotFound(err) {
+				return false
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			return true
+		}
+		setTemplate := func(value string) func(rgd *krov1alpha1.ResourceGraphDefinition) {
+			return func(rgd *krov1alpha1.ResourceGraphDefinition) {
+				rgd.Spec.Resources[0] = generator.NewResourceGraphDefinition(rgd.Name,
+					generator.WithResource("config", configMap(value), nil, nil),
+				).Spec.Resources[0]
+			}
+		}
+
+		// Operational settings don't change the graph, no revision is recorded
+		pausedGeneration := update(func(rgd *krov1alpha1.ResourceGraphDefinition) { rgd.Spec.Paused = true })
+		secondRevision := update(setTemplate("v2"))
+		Eventually(func(g Gomega) {
+			g.Expect(revisionExists(g, secondRevision)).To(BeTrue())
+			g.Expect(revisionExists(g, pausedGeneration)).To(BeFalse())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		// Revisions beyond the limit are pruned, except the pinned one
+		thirdRevision := update(setTemplate("v3"))
+		fourthRevision := update(setTemplate("v4"))
+		Eventually(func(g Gomega) {
+			g.Expect(revisionExists(g, fourthRevision)).To(BeTrue())
+			g.Expect(revisionExists(g, thirdRevision)).To(BeTrue())
+			g.Expect(revisionExists(g, secondRevision)).To(BeFalse())
+			g.Expect(revisionExists(g, firstRevision)).To(BeTrue())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		Expect(env.Client.Delete(ctx, instance)).To(Succeed())
+		Expect(env.Client.Delete(ctx, rgd)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, &krov1alpha1.ResourceGraphDefinition{})
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+	})
+
+	It("should keep serving the last valid graph when an update fails to build", func() {
+		configMap := func(value string) map[string]interface{} {
+			return map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "${schema.metadata.name}",
+				},
+				"data": map[string]interface{}{
+					"value": value,
+				},
+			}
+		}
+		rgd := generator.NewResourceGraphDefinition("test-last-valid-graph",
+			generator.WithSchema(
+				"TestLastValidGraph", "v1alpha1",
+				map[string]interface{}{
+					"value": "string",
+				},
+				nil,
+			),
+			generator.WithResource("config", configMap("${schema.spec.value}"), nil, nil),
+		)
+		Expect(env.Client.Create(ctx, rgd)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.State).To(Equal(krov1alpha1.ResourceGraphDefinitionStateActive))
+			g.Expect(rgd.Status.ActiveGeneration).To(Equal(rgd.Generation))
+		}, 10*time.Second, time.Second).Should(Succeed())
+		validGeneration := rgd.Generation
+
+		name := "test-last-valid-graph"
+		instance := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"),
+				"kind":       "TestLastValidGraph",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": namespace,
+				},
+				"spec": map[string]interface{}{
+					"value": "initial",
+				},
+			},
+		}
+		Expect(env.Client.Create(ctx, instance)).To(Succeed())
+
+		expectConfigMap := func(value string) {
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, cm)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(cm.Data).To(HaveKeyWithValue("value", value))
+			}, 20*time.Second, time.Second).Should(Succeed())
+		}
+		expectConfigMap("initial")
+
+		// Break the graph with a typo in the expression
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			rgd.Spec.Resources[0] = generator.NewResourceGraphDefinition("test-last-valid-graph",
+				generator.WithResource("config", configMap("${schema.spec.valeu}"), nil, nil),
+			).Spec.Resources[0]
+			g.Expect(env.Client.Update(ctx, rgd)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.State).To(Equal(krov1alpha1.ResourceGraphDefinitionStateActive))
+			g.Expect(rgd.Status.ActiveGeneration).To(Equal(validGeneration))
+			g.Expect(rgd.Status.FailedGeneration).To(Equal(rgd.Generation))
+			for _, condition := range rgd.Status.Conditions {
+				if condition.Type == krov1alpha1.ResourceGraphDefinitionConditionTypeGraphVerified {
+					g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				}
+			}
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		// Serving the last valid graph is only reported once, while the
+		// reconciliation keeps failing
+		Consistently(func(g Gomega) {
+			events := &corev1.EventList{}
+			g.Expect(env.Client.List(ctx, events)).To(Succeed())
+			var count int32
+			for _, event := range events.Items {
+				if event.InvolvedObject.Name == rgd.Name && event.Reason == "ServingPreviousGraph" {
+					count += max(event.Count, 1)
+				}
+			}
+			g.Expect(count).To(BeNumerically("<=", 1))
+		}, 5*time.Second, time.Second).Should(Succeed())
+
+		// The instance is still reconciled with the last valid graph
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			instance.Object["spec"] = map[string]interface{}{"value": "updated"}
+			g.Expect(env.Client.Update(ctx, instance)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+		expectConfigMap("updated")
+
+		// Delete the instance and the ResourceGraphDefinition
+		Expect(env.Client.Delete(ctx, instance)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+
+		Expect(env.Client.Delete(ctx, rgd)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, &krov1alpha1.ResourceGraphDefinition{})
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+	})
+})
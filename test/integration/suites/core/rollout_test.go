@@ -0,0 +1,187 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/metadata"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+)
+
+var _ = Describe("Rollout", func() {
+	var (
+		ctx       context.Context
+		namespace string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = fmt.Sprintf("test-%s", rand.String(5))
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		}
+		Expect(env.Client.Create(ctx, ns)).To(Succeed())
+	})
+
+	It("should roll out changes to instances wave by wave", func() {
+		configMap := func(suffix string) map[string]interface{} {
+			return map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "${schema.metadata.name}",
+				},
+				"data": map[string]interface{}{
+					"value": "${schema.spec.value}" + suffix,
+				},
+			}
+		}
+		rgd := generator.NewResourceGraphDefinition("test-rollout",
+			generator.WithSchema(
+				"TestRollout", "v1alpha1",
+				map[string]interface{}{
+					"value": "string",
+				},
+				nil,
+			),
+			generator.WithResource("config", configMap(""), nil, nil),
+		)
+		maxUnavailable := intstr.FromInt32(1)
+		rgd.Spec.Rollout = &krov1alpha1.RolloutPolicy{
+			MaxUnavailable: &maxUnavailable,
+			Waves: []krov1alpha1.RolloutWave{{
+				Name:     "canary",
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"canary": "true"}},
+			}},
+		}
+		Expect(env.Client.Create(ctx, rgd)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.State).To(Equal(krov1alpha1.ResourceGraphDefinitionStateActive))
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		// Create a canary and a regular instance
+		names := []string{"test-rollout-canary", "test-rollout-regular"}
+		for _, name := range names {
+			instance := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"),
+					"kind":       "TestRollout",
+					"metadata": map[string]interface{}{
+						"name":      name,
+						"namespace": namespace,
+						"labels": map[string]interface{}{
+							"canary": fmt.Sprintf("%t", name == names[0]),
+						},
+					},
+					"spec": map[string]interface{}{
+						"value": "initial",
+					},
+				},
+			}
+			Expect(env.Client.Create(ctx, instance)).To(Succeed())
+		}
+
+		for _, name := range names {
+			Eventually(func(g Gomega) {
+				configMap := &corev1.ConfigMap{}
+				err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(configMap.Data).To(HaveKeyWithValue("value", "initial"))
+			}, 20*time.Second, time.Second).Should(Succeed())
+		}
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.Rollout).ToNot(BeNil())
+			g.Expect(rgd.Status.Rollout.State).To(Equal(krov1alpha1.RolloutStateComplete))
+		}, 20*time.Second, time.Second).Should(Succeed())
+
+		// Change the template, and verify every instance is moved to the new
+		// revision
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			rgd.Spec.Resources[0] = generator.NewResourceGraphDefinition("test-rollout",
+				generator.WithResource("config", configMap("-v2"), nil, nil),
+			).Spec.Resources[0]
+			g.Expect(env.Client.Update(ctx, rgd)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.Rollout).ToNot(BeNil())
+			g.Expect(rgd.Status.Rollout.Revision).To(Equal(rgd.Generation))
+			g.Expect(rgd.Status.Rollout.State).To(Equal(krov1alpha1.RolloutStateComplete))
+			g.Expect(rgd.Status.Rollout.UpdatedInstances).To(Equal(int32(2)))
+		}, 60*time.Second, time.Second).Should(Succeed())
+
+		for _, name := range names {
+			Eventually(func(g Gomega) {
+				configMap := &corev1.ConfigMap{}
+				err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(configMap.Data).To(HaveKeyWithValue("value", "initial-v2"))
+
+				instance := &unstructured.Unstructured{}
+				instance.SetAPIVersion(fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"))
+				instance.SetKind("TestRollout")
+				err = env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(instance.GetAnnotations()).To(HaveKeyWithValue(
+					metadata.ResourceGraphDefinitionRevisionAnnotation, fmt.Sprintf("%d", rgd.Generation)))
+			}, 20*time.Second, time.Second).Should(Succeed())
+		}
+
+		// Delete the instances and the ResourceGraphDefinition
+		for _, name := range names {
+			instance := &unstructured.Unstructured{}
+			instance.SetAPIVersion(fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"))
+			instance.SetKind("TestRollout")
+			instance.SetName(name)
+			instance.SetNamespace(namespace)
+			Expect(env.Client.Delete(ctx, instance)).To(Succeed())
+			Eventually(func() bool {
+				err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+				return errors.IsNotFound(err)
+			}, 20*time.Second, time.Second).Should(BeTrue())
+		}
+
+		Expect(env.Client.Delete(ctx, rgd)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, &krov1alpha1.ResourceGraphDefinition{})
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+	})
+})
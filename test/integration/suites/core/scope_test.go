@@ -0,0 +1,162 @@
+// Copyright 2025 The Kube Resource Orchestrator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	krov1alpha1 "github.com/kro-run/kro/api/v1alpha1"
+	"github.com/kro-run/kro/pkg/testutil/generator"
+)
+
+var _ = Describe("Scope", func() {
+	var (
+		ctx       context.Context
+		tenant    string
+		selected  string
+		unlabeled string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		tenant = rand.String(5)
+		selected = fmt.Sprintf("test-%s", rand.String(5))
+		unlabeled = fmt.Sprintf("test-%s", rand.String(5))
+		Expect(env.Client.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   selected,
+				Labels: map[string]string{"tenant": tenant},
+			},
+		})).To(Succeed())
+		Expect(env.Client.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: unlabeled,
+			},
+		})).To(Succeed())
+	})
+
+	It("should only reconcile the instances of the selected namespaces", func() {
+		rgd := generator.NewResourceGraphDefinition("test-scope",
+			generator.WithSchema(
+				"TestScope", "v1alpha1",
+				map[string]interface{}{
+					"value": "string",
+				},
+				nil,
+			),
+			generator.WithResource("config", map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name": "${schema.metadata.name}",
+				},
+				"data": map[string]interface{}{
+					"value": "${schema.spec.value}",
+				},
+			}, nil, nil),
+		)
+		rgd.Spec.Scope = &krov1alpha1.InstanceScope{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tenant": tenant},
+			},
+		}
+		Expect(env.Client.Create(ctx, rgd)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, rgd)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rgd.Status.State).To(Equal(krov1alpha1.ResourceGraphDefinitionStateActive))
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		// Create an instance in each namespace
+		name := "test-scope"
+		instances := map[string]*unstructured.Unstructured{}
+		for _, namespace := range []string{selected, unlabeled} {
+			instance := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": fmt.Sprintf("%s/%s", krov1alpha1.KRODomainName, "v1alpha1"),
+					"kind":       "TestScope",
+					"metadata": map[string]interface{}{
+						"name":      name,
+						"namespace": namespace,
+					},
+					"spec": map[string]interface{}{
+						"value": namespace,
+					},
+				},
+			}
+			Expect(env.Client.Create(ctx, instance)).To(Succeed())
+			instances[namespace] = instance
+		}
+
+		// Only the instance of the selected namespace is reconciled
+		Eventually(func(g Gomega) {
+			configMap := &corev1.ConfigMap{}
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: selected}, configMap)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(configMap.Data).To(HaveKeyWithValue("value", selected))
+		}, 20*time.Second, time.Second).Should(Succeed())
+
+		Consistently(func(g Gomega) {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: unlabeled}, &corev1.ConfigMap{})
+			g.Expect(errors.IsNotFound(err)).To(BeTrue())
+			instance := &unstructured.Unstructured{}
+			instance.SetGroupVersionKind(instances[unlabeled].GroupVersionKind())
+			err = env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: unlabeled}, instance)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(instance.Object).ToNot(HaveKey("status"))
+		}, 5*time.Second, time.Second).Should(Succeed())
+
+		// Select the other namespace, and verify its instance is reconciled
+		Eventually(func(g Gomega) {
+			ns := &corev1.Namespace{}
+			g.Expect(env.Client.Get(ctx, types.NamespacedName{Name: unlabeled}, ns)).To(Succeed())
+			ns.Labels = map[string]string{"tenant": tenant}
+			g.Expect(env.Client.Update(ctx, ns)).To(Succeed())
+		}, 10*time.Second, time.Second).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			configMap := &corev1.ConfigMap{}
+			err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: unlabeled}, configMap)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(configMap.Data).To(HaveKeyWithValue("value", unlabeled))
+		}, 20*time.Second, time.Second).Should(Succeed())
+
+		// Delete the instances, and verify they are cleaned up
+		for namespace, instance := range instances {
+			Expect(env.Client.Delete(ctx, instance)).To(Succeed())
+			Eventually(func() bool {
+				err := env.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, instance)
+				return errors.IsNotFound(err)
+			}, 20*time.Second, time.Second).Should(BeTrue())
+		}
+
+		Expect(env.Client.Delete(ctx, rgd)).To(Succeed())
+		Eventually(func() bool {
+			err := env.Client.Get(ctx, types.NamespacedName{Name: rgd.Name}, &krov1alpha1.ResourceGraphDefinition{})
+			return errors.IsNotFound(err)
+		}, 20*time.Second, time.Second).Should(BeTrue())
+	})
+})
@@ -138,6 +138,7 @@ var _ = Describe("DeploymentService", func() {
 
 		// Patch the deployment to have available replicas in status
 		deployment.Status.Replicas = 1
+		deployment.Status.UpdatedReplicas = 1
 		deployment.Status.ReadyReplicas = 1
 		deployment.Status.AvailableReplicas = 1
 		deployment.Status.Conditions = []appsv1.DeploymentCondition{